@@ -2,31 +2,107 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"lucasbonna/pulse/internal/alerting"
 	"lucasbonna/pulse/internal/api"
 	"lucasbonna/pulse/internal/config"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/janitor"
+	"lucasbonna/pulse/internal/logging"
+	"lucasbonna/pulse/internal/metrics"
 	"lucasbonna/pulse/internal/scheduler"
 	"lucasbonna/pulse/internal/storage"
+	"lucasbonna/pulse/internal/telemetry"
+	"lucasbonna/pulse/internal/webhooks"
 )
 
+// shutdownTimeout bounds how long in-flight API requests may take to finish
+// after SIGINT or SIGTERM.
+const shutdownTimeout = 10 * time.Second
+
+// eventBufferSize is how many recent events GET /api/events keeps for
+// clients resuming with Last-Event-ID.
+const eventBufferSize = 1000
+
 func main() {
 	config := config.InitEnvs()
 
-	dbInstance, err := storage.NewSQLiteDB()
+	if err := logging.Setup(config.LogLevel, config.LogFormat); err != nil {
+		slog.Error("error setting up logging", "error", err)
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := telemetry.Setup(context.Background(), config.TracesExporter)
 	if err != nil {
-		log.Fatal("error creating db")
+		slog.Error("error setting up tracing", "error", err)
+		os.Exit(1)
 	}
+	defer shutdownTracing(context.Background())
 
-	jobScheduler := scheduler.NewScheduler(dbInstance)
+	dbInstance, dbConn, err := storage.NewSQLiteDB()
+	if err != nil {
+		slog.Error("error creating db", "error", err)
+		os.Exit(1)
+	}
+	defer dbConn.Close()
+
+	bus := events.NewBus()
+	eventStream := events.NewStream(bus, eventBufferSize)
+	dispatcher := webhooks.NewDispatcher(dbInstance, bus)
+	dispatcher.Start(context.Background())
+	defer dispatcher.Stop()
+
+	jobScheduler := scheduler.NewScheduler(dbInstance, bus, config.HostLimits)
+	alerter := alerting.NewAlerter(dbInstance)
+	jobScheduler.AddRunObserver(alerter)
 	jobScheduler.Start(context.Background())
 	defer jobScheduler.Stop()
 
-	httpServer := api.NewServer(dbInstance, config)
-
-	err = httpServer.Start()
-	if err != nil {
-		log.Fatal("error starting http server at port 3333", err)
+	if config.MetricsPort != "0" {
+		metrics.RegisterRunningJobs(jobScheduler.RunningCount)
+		go func() {
+			if err := metrics.ListenAndServe(config.MetricsPort); err != nil {
+				slog.Error("error starting metrics server", "error", err)
+				os.Exit(1)
+			}
+		}()
 	}
 
+	runJanitor := janitor.NewJanitor(dbInstance, dbConn, config.Retention)
+	runJanitor.Start(context.Background())
+	defer runJanitor.Stop()
+
+	alerter.Start(context.Background())
+	defer alerter.Stop()
+
+	httpServer := api.NewServer(dbInstance, dbConn, config, jobScheduler, runJanitor, alerter, bus, eventStream)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- httpServer.Start()
+	}()
+
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			slog.Error("error starting http server", "port", config.Port, "error", err)
+			os.Exit(1)
+		}
+	case <-signalCtx.Done():
+		slog.Info("received shutdown signal")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("error shutting down http server", "error", err)
+		}
+	}
 }
@@ -6,25 +6,210 @@ package db
 
 import (
 	"database/sql"
+	"time"
 )
 
+type DeadLetter struct {
+	ID           int64
+	JobID        int64
+	JobRunID     int64
+	Method       string
+	Url          string
+	Headers      string
+	Body         sql.NullString
+	Attempts     int64
+	ResponseCode sql.NullInt64
+	Error        string
+	CreatedAt    time.Time
+	ReplayRunID  sql.NullInt64
+	ReplayedAt   sql.NullTime
+}
+
+type Incident struct {
+	ID                  int64
+	JobID               int64
+	State               string
+	Reason              string
+	LastRunID           sql.NullInt64
+	LastError           sql.NullString
+	ConsecutiveFailures int64
+	OpenedAt            time.Time
+	AcknowledgedAt      sql.NullTime
+	AcknowledgedBy      sql.NullString
+	EscalatedAt         sql.NullTime
+	ResolvedAt          sql.NullTime
+	LastNotifiedAt      time.Time
+	Notifications       int64
+}
+
 type Job struct {
-	ID              int64
-	Name            string
-	Url             string
-	Method          interface{}
-	Headers         sql.NullString
-	IntervalSeconds int64
-	NextRunAt       sql.NullTime
-	Active          sql.NullBool
+	ID                        int64
+	Name                      string
+	Url                       string
+	Method                    interface{}
+	Headers                   sql.NullString
+	IntervalSeconds           int64
+	NextRunAt                 sql.NullTime
+	Active                    sql.NullBool
+	TlsClientCertFile         sql.NullString
+	TlsClientKeyFile          sql.NullString
+	TlsCaFile                 sql.NullString
+	TlsServerName             sql.NullString
+	TlsMinVersion             sql.NullString
+	TlsInsecureSkipVerify     sql.NullBool
+	ProxyUrl                  sql.NullString
+	FollowRedirects           sql.NullBool
+	MaxRedirects              sql.NullInt64
+	Resolve                   sql.NullString
+	CertExpiryDays            sql.NullInt64
+	CertExpiryAction          string
+	Type                      string
+	TimeoutSeconds            int64
+	DnsRecordType             sql.NullString
+	DnsExpected               sql.NullString
+	DnsServer                 sql.NullString
+	GrpcService               sql.NullString
+	GrpcTls                   sql.NullBool
+	FailOnErrorStatus         sql.NullBool
+	Steps                     sql.NullString
+	SendMessage               sql.NullString
+	ExpectMessage             sql.NullString
+	TrackChanges              sql.NullBool
+	TrackJsonPath             sql.NullString
+	MaxBodyBytes              int64
+	RedactHeaders             sql.NullString
+	RetentionKeepRuns         sql.NullInt64
+	RetentionKeepDays         sql.NullInt64
+	RetentionKeepFailuresDays sql.NullInt64
+	AlertChannels             sql.NullString
+	AlertConsecutiveFailures  sql.NullInt64
+	AlertFailureRate          sql.NullInt64
+	AlertFailureWindow        int64
+	AlertRenotifyMinutes      sql.NullInt64
+	AlertEscalationChannels   sql.NullString
+	AlertEscalationMinutes    sql.NullInt64
+	Tags                      sql.NullString
+	HeartbeatToken            sql.NullString
+	HeartbeatGraceSeconds     sql.NullInt64
+	HeartbeatStartedAt        sql.NullTime
+	TriggerToken              sql.NullString
+	TriggerSecret             sql.NullString
+	TriggerRateLimit          sql.NullInt64
+	BreakerFailureThreshold   sql.NullInt64
+	BreakerProbeSeconds       sql.NullInt64
+	BreakerState              string
+	BreakerFailures           int64
+	BreakerOpenedAt           sql.NullTime
+	Body                      sql.NullString
+	RetryAttempts             sql.NullInt64
+	RetryDelaySeconds         sql.NullInt64
+	RetryAfterHostWide        sql.NullBool
+}
+
+type JobAlertState struct {
+	JobID               int64
+	State               string
+	ConsecutiveFailures int64
+	ChangedAt           time.Time
+}
+
+type JobLatencyRollup struct {
+	JobID       int64
+	BucketStart int64
+	LeMs        int64
+	Runs        int64
+}
+
+type JobRollup struct {
+	JobID        int64
+	BucketStart  int64
+	Status       string
+	Runs         int64
+	LatencySumMs int64
+	LatencyCount int64
 }
 
 type JobRun struct {
+	ID                int64
+	JobID             int64
+	Status            sql.NullString
+	ResponseCode      sql.NullInt64
+	ResponseBody      []byte
+	StartedAt         sql.NullTime
+	FinishedAt        sql.NullTime
+	DnsMs             sql.NullInt64
+	ConnectMs         sql.NullInt64
+	TlsMs             sql.NullInt64
+	TtfbMs            sql.NullInt64
+	TotalMs           sql.NullInt64
+	Error             sql.NullString
+	BodyHash          sql.NullString
+	Changed           sql.NullBool
+	BodyDiff          sql.NullString
+	ResponseHeaders   sql.NullString
+	ResponseEncoding  sql.NullString
+	ResponseSize      sql.NullInt64
+	ResponseTruncated sql.NullBool
+	ReplayOfRunID     sql.NullInt64
+}
+
+type JobRunStep struct {
 	ID           int64
-	JobID        int64
-	Status       sql.NullString
+	JobRunID     int64
+	Position     int64
+	Name         string
+	Status       string
 	ResponseCode sql.NullInt64
-	ResponseBody sql.NullString
-	StartedAt    sql.NullTime
-	FinishedAt   sql.NullTime
+	DurationMs   sql.NullInt64
+	Error        sql.NullString
+}
+
+type JobTrackedContent struct {
+	JobID     int64
+	BodyHash  string
+	Content   string
+	UpdatedAt time.Time
+}
+
+type NotificationChannel struct {
+	ID        int64
+	Name      string
+	Type      string
+	Config    string
+	CreatedAt time.Time
+}
+
+type RunCertificate struct {
+	ID       int64
+	JobRunID int64
+	JobID    int64
+	Host     string
+	Position int64
+	Subject  string
+	Issuer   string
+	NotAfter time.Time
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventID        int64
+	EventType      string
+	Payload        string
+	Status         string
+	Attempts       int64
+	NextAttemptAt  sql.NullTime
+	LastAttemptAt  sql.NullTime
+	ResponseCode   sql.NullInt64
+	Error          sql.NullString
+	CreatedAt      time.Time
+}
+
+type WebhookSubscription struct {
+	ID        int64
+	Url       string
+	Secret    string
+	Events    string
+	Active    bool
+	CreatedAt time.Time
 }
@@ -8,25 +8,281 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 )
 
+const acknowledgeIncident = `-- name: AcknowledgeIncident :one
+UPDATE incidents
+SET state = 'acknowledged', acknowledged_at = ?, acknowledged_by = ?
+WHERE id = ? AND state = 'open'
+RETURNING id, job_id, state, reason, last_run_id, last_error, consecutive_failures, opened_at, acknowledged_at, acknowledged_by, escalated_at, resolved_at, last_notified_at, notifications
+`
+
+type AcknowledgeIncidentParams struct {
+	AcknowledgedAt sql.NullTime
+	AcknowledgedBy sql.NullString
+	ID             int64
+}
+
+func (q *Queries) AcknowledgeIncident(ctx context.Context, arg AcknowledgeIncidentParams) (Incident, error) {
+	row := q.db.QueryRowContext(ctx, acknowledgeIncident, arg.AcknowledgedAt, arg.AcknowledgedBy, arg.ID)
+	var i Incident
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.State,
+		&i.Reason,
+		&i.LastRunID,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.OpenedAt,
+		&i.AcknowledgedAt,
+		&i.AcknowledgedBy,
+		&i.EscalatedAt,
+		&i.ResolvedAt,
+		&i.LastNotifiedAt,
+		&i.Notifications,
+	)
+	return i, err
+}
+
+const countRecentJobRunFailures = `-- name: CountRecentJobRunFailures :one
+SELECT CAST(COUNT(*) AS INTEGER) AS runs,
+       CAST(COALESCE(SUM(status = 'failed'), 0) AS INTEGER) AS failures
+FROM (
+  SELECT status FROM job_runs
+  WHERE job_id = ? AND status != 'running'
+  ORDER BY id DESC
+  LIMIT ?
+)
+`
+
+type CountRecentJobRunFailuresParams struct {
+	JobID int64
+	Limit int64
+}
+
+type CountRecentJobRunFailuresRow struct {
+	Runs     int64
+	Failures int64
+}
+
+// Looks at the job's last finished runs, newest first.
+func (q *Queries) CountRecentJobRunFailures(ctx context.Context, arg CountRecentJobRunFailuresParams) (CountRecentJobRunFailuresRow, error) {
+	row := q.db.QueryRowContext(ctx, countRecentJobRunFailures, arg.JobID, arg.Limit)
+	var i CountRecentJobRunFailuresRow
+	err := row.Scan(&i.Runs, &i.Failures)
+	return i, err
+}
+
+const createDeadLetter = `-- name: CreateDeadLetter :one
+INSERT INTO dead_letters (
+  job_id, job_run_id, method, url, headers, body, attempts, response_code, error, created_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING id, job_id, job_run_id, method, url, headers, body, attempts, response_code, error, created_at, replay_run_id, replayed_at
+`
+
+type CreateDeadLetterParams struct {
+	JobID        int64
+	JobRunID     int64
+	Method       string
+	Url          string
+	Headers      string
+	Body         sql.NullString
+	Attempts     int64
+	ResponseCode sql.NullInt64
+	Error        string
+	CreatedAt    time.Time
+}
+
+func (q *Queries) CreateDeadLetter(ctx context.Context, arg CreateDeadLetterParams) (DeadLetter, error) {
+	row := q.db.QueryRowContext(ctx, createDeadLetter,
+		arg.JobID,
+		arg.JobRunID,
+		arg.Method,
+		arg.Url,
+		arg.Headers,
+		arg.Body,
+		arg.Attempts,
+		arg.ResponseCode,
+		arg.Error,
+		arg.CreatedAt,
+	)
+	var i DeadLetter
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.JobRunID,
+		&i.Method,
+		&i.Url,
+		&i.Headers,
+		&i.Body,
+		&i.Attempts,
+		&i.ResponseCode,
+		&i.Error,
+		&i.CreatedAt,
+		&i.ReplayRunID,
+		&i.ReplayedAt,
+	)
+	return i, err
+}
+
+const createIncident = `-- name: CreateIncident :one
+INSERT INTO incidents (
+  job_id, state, reason, last_run_id, last_error, consecutive_failures,
+  opened_at, last_notified_at
+) VALUES (
+  ?, 'open', ?, ?, ?, ?,
+  ?, ?
+)
+RETURNING id, job_id, state, reason, last_run_id, last_error, consecutive_failures, opened_at, acknowledged_at, acknowledged_by, escalated_at, resolved_at, last_notified_at, notifications
+`
+
+type CreateIncidentParams struct {
+	JobID               int64
+	Reason              string
+	LastRunID           sql.NullInt64
+	LastError           sql.NullString
+	ConsecutiveFailures int64
+	OpenedAt            time.Time
+	LastNotifiedAt      time.Time
+}
+
+func (q *Queries) CreateIncident(ctx context.Context, arg CreateIncidentParams) (Incident, error) {
+	row := q.db.QueryRowContext(ctx, createIncident,
+		arg.JobID,
+		arg.Reason,
+		arg.LastRunID,
+		arg.LastError,
+		arg.ConsecutiveFailures,
+		arg.OpenedAt,
+		arg.LastNotifiedAt,
+	)
+	var i Incident
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.State,
+		&i.Reason,
+		&i.LastRunID,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.OpenedAt,
+		&i.AcknowledgedAt,
+		&i.AcknowledgedBy,
+		&i.EscalatedAt,
+		&i.ResolvedAt,
+		&i.LastNotifiedAt,
+		&i.Notifications,
+	)
+	return i, err
+}
+
 const createJob = `-- name: CreateJob :one
 INSERT INTO jobs (
-  name, url, method, headers, interval_seconds, next_run_at, active
+  name, url, method, headers, interval_seconds, next_run_at, active,
+  tls_client_cert_file, tls_client_key_file, tls_ca_file,
+  tls_server_name, tls_min_version, tls_insecure_skip_verify,
+  proxy_url, follow_redirects, max_redirects, resolve,
+  cert_expiry_days, cert_expiry_action,
+  type, timeout_seconds,
+  dns_record_type, dns_expected, dns_server,
+  grpc_service, grpc_tls, fail_on_error_status, steps,
+  send_message, expect_message,
+  track_changes, track_json_path,
+  max_body_bytes, redact_headers,
+  retention_keep_runs, retention_keep_days, retention_keep_failures_days,
+  alert_channels, alert_consecutive_failures, alert_failure_rate, alert_failure_window,
+  alert_renotify_minutes, alert_escalation_channels, alert_escalation_minutes,
+  tags, heartbeat_token, heartbeat_grace_seconds,
+  trigger_token, trigger_secret, trigger_rate_limit,
+  breaker_failure_threshold, breaker_probe_seconds,
+  body, retry_attempts, retry_delay_seconds,
+  retry_after_host_wide
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?,
+  ?, ?, ?,
+  ?, ?, ?,
+  ?, ?, ?, ?,
+  ?, ?,
+  ?, ?,
+  ?, ?, ?,
+  ?, ?, ?, ?,
+  ?, ?,
+  ?, ?,
+  ?, ?,
+  ?, ?, ?,
+  ?, ?, ?, ?,
+  ?, ?, ?,
+  ?, ?, ?,
+  ?, ?, ?,
+  ?, ?,
+  ?, ?, ?,
+  ?
 )
-RETURNING id, name, url, method, headers, interval_seconds, next_run_at, active
+RETURNING id, name, url, method, headers, interval_seconds, next_run_at, active, tls_client_cert_file, tls_client_key_file, tls_ca_file, tls_server_name, tls_min_version, tls_insecure_skip_verify, proxy_url, follow_redirects, max_redirects, resolve, cert_expiry_days, cert_expiry_action, type, timeout_seconds, dns_record_type, dns_expected, dns_server, grpc_service, grpc_tls, fail_on_error_status, steps, send_message, expect_message, track_changes, track_json_path, max_body_bytes, redact_headers, retention_keep_runs, retention_keep_days, retention_keep_failures_days, alert_channels, alert_consecutive_failures, alert_failure_rate, alert_failure_window, alert_renotify_minutes, alert_escalation_channels, alert_escalation_minutes, tags, heartbeat_token, heartbeat_grace_seconds, heartbeat_started_at, trigger_token, trigger_secret, trigger_rate_limit, breaker_failure_threshold, breaker_probe_seconds, breaker_state, breaker_failures, breaker_opened_at, body, retry_attempts, retry_delay_seconds, retry_after_host_wide
 `
 
 type CreateJobParams struct {
-	Name            string
-	Url             string
-	Method          interface{}
-	Headers         sql.NullString
-	IntervalSeconds int64
-	NextRunAt       sql.NullTime
-	Active          sql.NullBool
+	Name                      string
+	Url                       string
+	Method                    interface{}
+	Headers                   sql.NullString
+	IntervalSeconds           int64
+	NextRunAt                 sql.NullTime
+	Active                    sql.NullBool
+	TlsClientCertFile         sql.NullString
+	TlsClientKeyFile          sql.NullString
+	TlsCaFile                 sql.NullString
+	TlsServerName             sql.NullString
+	TlsMinVersion             sql.NullString
+	TlsInsecureSkipVerify     sql.NullBool
+	ProxyUrl                  sql.NullString
+	FollowRedirects           sql.NullBool
+	MaxRedirects              sql.NullInt64
+	Resolve                   sql.NullString
+	CertExpiryDays            sql.NullInt64
+	CertExpiryAction          string
+	Type                      string
+	TimeoutSeconds            int64
+	DnsRecordType             sql.NullString
+	DnsExpected               sql.NullString
+	DnsServer                 sql.NullString
+	GrpcService               sql.NullString
+	GrpcTls                   sql.NullBool
+	FailOnErrorStatus         sql.NullBool
+	Steps                     sql.NullString
+	SendMessage               sql.NullString
+	ExpectMessage             sql.NullString
+	TrackChanges              sql.NullBool
+	TrackJsonPath             sql.NullString
+	MaxBodyBytes              int64
+	RedactHeaders             sql.NullString
+	RetentionKeepRuns         sql.NullInt64
+	RetentionKeepDays         sql.NullInt64
+	RetentionKeepFailuresDays sql.NullInt64
+	AlertChannels             sql.NullString
+	AlertConsecutiveFailures  sql.NullInt64
+	AlertFailureRate          sql.NullInt64
+	AlertFailureWindow        int64
+	AlertRenotifyMinutes      sql.NullInt64
+	AlertEscalationChannels   sql.NullString
+	AlertEscalationMinutes    sql.NullInt64
+	Tags                      sql.NullString
+	HeartbeatToken            sql.NullString
+	HeartbeatGraceSeconds     sql.NullInt64
+	TriggerToken              sql.NullString
+	TriggerSecret             sql.NullString
+	TriggerRateLimit          sql.NullInt64
+	BreakerFailureThreshold   sql.NullInt64
+	BreakerProbeSeconds       sql.NullInt64
+	Body                      sql.NullString
+	RetryAttempts             sql.NullInt64
+	RetryDelaySeconds         sql.NullInt64
+	RetryAfterHostWide        sql.NullBool
 }
 
 func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, error) {
@@ -38,81 +294,1809 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		arg.IntervalSeconds,
 		arg.NextRunAt,
 		arg.Active,
+		arg.TlsClientCertFile,
+		arg.TlsClientKeyFile,
+		arg.TlsCaFile,
+		arg.TlsServerName,
+		arg.TlsMinVersion,
+		arg.TlsInsecureSkipVerify,
+		arg.ProxyUrl,
+		arg.FollowRedirects,
+		arg.MaxRedirects,
+		arg.Resolve,
+		arg.CertExpiryDays,
+		arg.CertExpiryAction,
+		arg.Type,
+		arg.TimeoutSeconds,
+		arg.DnsRecordType,
+		arg.DnsExpected,
+		arg.DnsServer,
+		arg.GrpcService,
+		arg.GrpcTls,
+		arg.FailOnErrorStatus,
+		arg.Steps,
+		arg.SendMessage,
+		arg.ExpectMessage,
+		arg.TrackChanges,
+		arg.TrackJsonPath,
+		arg.MaxBodyBytes,
+		arg.RedactHeaders,
+		arg.RetentionKeepRuns,
+		arg.RetentionKeepDays,
+		arg.RetentionKeepFailuresDays,
+		arg.AlertChannels,
+		arg.AlertConsecutiveFailures,
+		arg.AlertFailureRate,
+		arg.AlertFailureWindow,
+		arg.AlertRenotifyMinutes,
+		arg.AlertEscalationChannels,
+		arg.AlertEscalationMinutes,
+		arg.Tags,
+		arg.HeartbeatToken,
+		arg.HeartbeatGraceSeconds,
+		arg.TriggerToken,
+		arg.TriggerSecret,
+		arg.TriggerRateLimit,
+		arg.BreakerFailureThreshold,
+		arg.BreakerProbeSeconds,
+		arg.Body,
+		arg.RetryAttempts,
+		arg.RetryDelaySeconds,
+		arg.RetryAfterHostWide,
 	)
 	var i Job
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Url,
-		&i.Method,
-		&i.Headers,
-		&i.IntervalSeconds,
-		&i.NextRunAt,
-		&i.Active,
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.Method,
+		&i.Headers,
+		&i.IntervalSeconds,
+		&i.NextRunAt,
+		&i.Active,
+		&i.TlsClientCertFile,
+		&i.TlsClientKeyFile,
+		&i.TlsCaFile,
+		&i.TlsServerName,
+		&i.TlsMinVersion,
+		&i.TlsInsecureSkipVerify,
+		&i.ProxyUrl,
+		&i.FollowRedirects,
+		&i.MaxRedirects,
+		&i.Resolve,
+		&i.CertExpiryDays,
+		&i.CertExpiryAction,
+		&i.Type,
+		&i.TimeoutSeconds,
+		&i.DnsRecordType,
+		&i.DnsExpected,
+		&i.DnsServer,
+		&i.GrpcService,
+		&i.GrpcTls,
+		&i.FailOnErrorStatus,
+		&i.Steps,
+		&i.SendMessage,
+		&i.ExpectMessage,
+		&i.TrackChanges,
+		&i.TrackJsonPath,
+		&i.MaxBodyBytes,
+		&i.RedactHeaders,
+		&i.RetentionKeepRuns,
+		&i.RetentionKeepDays,
+		&i.RetentionKeepFailuresDays,
+		&i.AlertChannels,
+		&i.AlertConsecutiveFailures,
+		&i.AlertFailureRate,
+		&i.AlertFailureWindow,
+		&i.AlertRenotifyMinutes,
+		&i.AlertEscalationChannels,
+		&i.AlertEscalationMinutes,
+		&i.Tags,
+		&i.HeartbeatToken,
+		&i.HeartbeatGraceSeconds,
+		&i.HeartbeatStartedAt,
+		&i.TriggerToken,
+		&i.TriggerSecret,
+		&i.TriggerRateLimit,
+		&i.BreakerFailureThreshold,
+		&i.BreakerProbeSeconds,
+		&i.BreakerState,
+		&i.BreakerFailures,
+		&i.BreakerOpenedAt,
+		&i.Body,
+		&i.RetryAttempts,
+		&i.RetryDelaySeconds,
+		&i.RetryAfterHostWide,
+	)
+	return i, err
+}
+
+const createJobRun = `-- name: CreateJobRun :one
+INSERT INTO job_runs (job_id, status, started_at, replay_of_run_id)
+VALUES (?, ?, ?, ?)
+RETURNING id, job_id, status, response_code, response_body, started_at, finished_at, dns_ms, connect_ms, tls_ms, ttfb_ms, total_ms, error, body_hash, changed, body_diff, response_headers, response_encoding, response_size, response_truncated, replay_of_run_id
+`
+
+type CreateJobRunParams struct {
+	JobID         int64
+	Status        sql.NullString
+	StartedAt     sql.NullTime
+	ReplayOfRunID sql.NullInt64
+}
+
+func (q *Queries) CreateJobRun(ctx context.Context, arg CreateJobRunParams) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, createJobRun,
+		arg.JobID,
+		arg.Status,
+		arg.StartedAt,
+		arg.ReplayOfRunID,
+	)
+	var i JobRun
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Status,
+		&i.ResponseCode,
+		&i.ResponseBody,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.DnsMs,
+		&i.ConnectMs,
+		&i.TlsMs,
+		&i.TtfbMs,
+		&i.TotalMs,
+		&i.Error,
+		&i.BodyHash,
+		&i.Changed,
+		&i.BodyDiff,
+		&i.ResponseHeaders,
+		&i.ResponseEncoding,
+		&i.ResponseSize,
+		&i.ResponseTruncated,
+		&i.ReplayOfRunID,
+	)
+	return i, err
+}
+
+const createJobRunStep = `-- name: CreateJobRunStep :exec
+INSERT INTO job_run_steps (
+  job_run_id, position, name, status, response_code, duration_ms, error
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateJobRunStepParams struct {
+	JobRunID     int64
+	Position     int64
+	Name         string
+	Status       string
+	ResponseCode sql.NullInt64
+	DurationMs   sql.NullInt64
+	Error        sql.NullString
+}
+
+func (q *Queries) CreateJobRunStep(ctx context.Context, arg CreateJobRunStepParams) error {
+	_, err := q.db.ExecContext(ctx, createJobRunStep,
+		arg.JobRunID,
+		arg.Position,
+		arg.Name,
+		arg.Status,
+		arg.ResponseCode,
+		arg.DurationMs,
+		arg.Error,
+	)
+	return err
+}
+
+const createNotificationChannel = `-- name: CreateNotificationChannel :one
+INSERT INTO notification_channels (name, type, config, created_at)
+VALUES (?, ?, ?, ?)
+RETURNING id, name, type, config, created_at
+`
+
+type CreateNotificationChannelParams struct {
+	Name      string
+	Type      string
+	Config    string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateNotificationChannel(ctx context.Context, arg CreateNotificationChannelParams) (NotificationChannel, error) {
+	row := q.db.QueryRowContext(ctx, createNotificationChannel,
+		arg.Name,
+		arg.Type,
+		arg.Config,
+		arg.CreatedAt,
+	)
+	var i NotificationChannel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.Config,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createRunCertificate = `-- name: CreateRunCertificate :exec
+INSERT INTO run_certificates (
+  job_run_id, job_id, host, position, subject, issuer, not_after
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateRunCertificateParams struct {
+	JobRunID int64
+	JobID    int64
+	Host     string
+	Position int64
+	Subject  string
+	Issuer   string
+	NotAfter time.Time
+}
+
+func (q *Queries) CreateRunCertificate(ctx context.Context, arg CreateRunCertificateParams) error {
+	_, err := q.db.ExecContext(ctx, createRunCertificate,
+		arg.JobRunID,
+		arg.JobID,
+		arg.Host,
+		arg.Position,
+		arg.Subject,
+		arg.Issuer,
+		arg.NotAfter,
+	)
+	return err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+  subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at
+) VALUES (
+  ?, ?, ?, ?, 'pending', ?, ?
+)
+RETURNING id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, response_code, error, created_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	SubscriptionID int64
+	EventID        int64
+	EventType      string
+	Payload        string
+	NextAttemptAt  sql.NullTime
+	CreatedAt      time.Time
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, createWebhookDelivery,
+		arg.SubscriptionID,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+		arg.NextAttemptAt,
+		arg.CreatedAt,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastAttemptAt,
+		&i.ResponseCode,
+		&i.Error,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (url, secret, events, active, created_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, url, secret, events, active, created_at
+`
+
+type CreateWebhookSubscriptionParams struct {
+	Url       string
+	Secret    string
+	Events    string
+	Active    bool
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, createWebhookSubscription,
+		arg.Url,
+		arg.Secret,
+		arg.Events,
+		arg.Active,
+		arg.CreatedAt,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteDeadLetter = `-- name: DeleteDeadLetter :exec
+DELETE FROM dead_letters WHERE id = ?
+`
+
+func (q *Queries) DeleteDeadLetter(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteDeadLetter, id)
+	return err
+}
+
+const deleteDeadLettersReplayedBefore = `-- name: DeleteDeadLettersReplayedBefore :execrows
+DELETE FROM dead_letters WHERE replayed_at IS NOT NULL AND replayed_at < ?
+`
+
+func (q *Queries) DeleteDeadLettersReplayedBefore(ctx context.Context, replayedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDeadLettersReplayedBefore, replayedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteJob = `-- name: DeleteJob :exec
+DELETE FROM jobs
+WHERE id = ?
+`
+
+func (q *Queries) DeleteJob(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteJob, id)
+	return err
+}
+
+const deleteJobLatencyRollupsBefore = `-- name: DeleteJobLatencyRollupsBefore :execrows
+DELETE FROM job_latency_rollups WHERE bucket_start < ?
+`
+
+func (q *Queries) DeleteJobLatencyRollupsBefore(ctx context.Context, bucketStart int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteJobLatencyRollupsBefore, bucketStart)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteJobRollupsBefore = `-- name: DeleteJobRollupsBefore :execrows
+DELETE FROM job_rollups WHERE bucket_start < ?
+`
+
+func (q *Queries) DeleteJobRollupsBefore(ctx context.Context, bucketStart int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteJobRollupsBefore, bucketStart)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteJobRunStepsByRunIDs = `-- name: DeleteJobRunStepsByRunIDs :exec
+DELETE FROM job_run_steps WHERE job_run_id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeleteJobRunStepsByRunIDs(ctx context.Context, ids []int64) error {
+	query := deleteJobRunStepsByRunIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const deleteJobRunsByIDs = `-- name: DeleteJobRunsByIDs :execrows
+DELETE FROM job_runs WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeleteJobRunsByIDs(ctx context.Context, ids []int64) (int64, error) {
+	query := deleteJobRunsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	result, err := q.db.ExecContext(ctx, query, queryParams...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteNotificationChannel = `-- name: DeleteNotificationChannel :execrows
+DELETE FROM notification_channels WHERE id = ?
+`
+
+func (q *Queries) DeleteNotificationChannel(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteNotificationChannel, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOrphanedAlertStates = `-- name: DeleteOrphanedAlertStates :exec
+DELETE FROM job_alert_states WHERE job_id NOT IN (SELECT id FROM jobs)
+`
+
+func (q *Queries) DeleteOrphanedAlertStates(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteOrphanedAlertStates)
+	return err
+}
+
+const deleteOrphanedDeadLetters = `-- name: DeleteOrphanedDeadLetters :exec
+DELETE FROM dead_letters WHERE job_id NOT IN (SELECT id FROM jobs)
+`
+
+func (q *Queries) DeleteOrphanedDeadLetters(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteOrphanedDeadLetters)
+	return err
+}
+
+const deleteOrphanedIncidents = `-- name: DeleteOrphanedIncidents :exec
+DELETE FROM incidents WHERE job_id NOT IN (SELECT id FROM jobs)
+`
+
+func (q *Queries) DeleteOrphanedIncidents(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteOrphanedIncidents)
+	return err
+}
+
+const deleteOrphanedTrackedContent = `-- name: DeleteOrphanedTrackedContent :exec
+DELETE FROM job_tracked_content WHERE job_id NOT IN (SELECT id FROM jobs)
+`
+
+func (q *Queries) DeleteOrphanedTrackedContent(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteOrphanedTrackedContent)
+	return err
+}
+
+const deleteRunCertificatesByRunIDs = `-- name: DeleteRunCertificatesByRunIDs :exec
+DELETE FROM run_certificates WHERE job_run_id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeleteRunCertificatesByRunIDs(ctx context.Context, ids []int64) error {
+	query := deleteRunCertificatesByRunIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const deleteWebhookDeliveriesBefore = `-- name: DeleteWebhookDeliveriesBefore :execrows
+DELETE FROM webhook_deliveries WHERE status != 'pending' AND created_at < ?
+`
+
+func (q *Queries) DeleteWebhookDeliveriesBefore(ctx context.Context, createdAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhookDeliveriesBefore, createdAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteWebhookDeliveriesBySubscription = `-- name: DeleteWebhookDeliveriesBySubscription :exec
+DELETE FROM webhook_deliveries WHERE subscription_id = ?
+`
+
+func (q *Queries) DeleteWebhookDeliveriesBySubscription(ctx context.Context, subscriptionID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhookDeliveriesBySubscription, subscriptionID)
+	return err
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions WHERE id = ?
+`
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhookSubscription, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const finishJobHeartbeat = `-- name: FinishJobHeartbeat :exec
+UPDATE jobs
+SET heartbeat_started_at = NULL, next_run_at = ?
+WHERE id = ?
+`
+
+type FinishJobHeartbeatParams struct {
+	NextRunAt sql.NullTime
+	ID        int64
+}
+
+func (q *Queries) FinishJobHeartbeat(ctx context.Context, arg FinishJobHeartbeatParams) error {
+	_, err := q.db.ExecContext(ctx, finishJobHeartbeat, arg.NextRunAt, arg.ID)
+	return err
+}
+
+const getActiveIncidentForJob = `-- name: GetActiveIncidentForJob :one
+SELECT id, job_id, state, reason, last_run_id, last_error, consecutive_failures, opened_at, acknowledged_at, acknowledged_by, escalated_at, resolved_at, last_notified_at, notifications FROM incidents
+WHERE job_id = ? AND state != 'resolved'
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetActiveIncidentForJob(ctx context.Context, jobID int64) (Incident, error) {
+	row := q.db.QueryRowContext(ctx, getActiveIncidentForJob, jobID)
+	var i Incident
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.State,
+		&i.Reason,
+		&i.LastRunID,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.OpenedAt,
+		&i.AcknowledgedAt,
+		&i.AcknowledgedBy,
+		&i.EscalatedAt,
+		&i.ResolvedAt,
+		&i.LastNotifiedAt,
+		&i.Notifications,
+	)
+	return i, err
+}
+
+const getActiveWebhookSubscriptions = `-- name: GetActiveWebhookSubscriptions :many
+SELECT id, url, secret, events, active, created_at FROM webhook_subscriptions
+WHERE active = 1
+ORDER BY id
+`
+
+func (q *Queries) GetActiveWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveWebhookSubscriptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllJobs = `-- name: GetAllJobs :many
+SELECT id, name, url, method, headers, interval_seconds, next_run_at, active, tls_client_cert_file, tls_client_key_file, tls_ca_file, tls_server_name, tls_min_version, tls_insecure_skip_verify, proxy_url, follow_redirects, max_redirects, resolve, cert_expiry_days, cert_expiry_action, type, timeout_seconds, dns_record_type, dns_expected, dns_server, grpc_service, grpc_tls, fail_on_error_status, steps, send_message, expect_message, track_changes, track_json_path, max_body_bytes, redact_headers, retention_keep_runs, retention_keep_days, retention_keep_failures_days, alert_channels, alert_consecutive_failures, alert_failure_rate, alert_failure_window, alert_renotify_minutes, alert_escalation_channels, alert_escalation_minutes, tags, heartbeat_token, heartbeat_grace_seconds, heartbeat_started_at, trigger_token, trigger_secret, trigger_rate_limit, breaker_failure_threshold, breaker_probe_seconds, breaker_state, breaker_failures, breaker_opened_at, body, retry_attempts, retry_delay_seconds, retry_after_host_wide FROM jobs
+ORDER BY id
+`
+
+func (q *Queries) GetAllJobs(ctx context.Context) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, getAllJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.Method,
+			&i.Headers,
+			&i.IntervalSeconds,
+			&i.NextRunAt,
+			&i.Active,
+			&i.TlsClientCertFile,
+			&i.TlsClientKeyFile,
+			&i.TlsCaFile,
+			&i.TlsServerName,
+			&i.TlsMinVersion,
+			&i.TlsInsecureSkipVerify,
+			&i.ProxyUrl,
+			&i.FollowRedirects,
+			&i.MaxRedirects,
+			&i.Resolve,
+			&i.CertExpiryDays,
+			&i.CertExpiryAction,
+			&i.Type,
+			&i.TimeoutSeconds,
+			&i.DnsRecordType,
+			&i.DnsExpected,
+			&i.DnsServer,
+			&i.GrpcService,
+			&i.GrpcTls,
+			&i.FailOnErrorStatus,
+			&i.Steps,
+			&i.SendMessage,
+			&i.ExpectMessage,
+			&i.TrackChanges,
+			&i.TrackJsonPath,
+			&i.MaxBodyBytes,
+			&i.RedactHeaders,
+			&i.RetentionKeepRuns,
+			&i.RetentionKeepDays,
+			&i.RetentionKeepFailuresDays,
+			&i.AlertChannels,
+			&i.AlertConsecutiveFailures,
+			&i.AlertFailureRate,
+			&i.AlertFailureWindow,
+			&i.AlertRenotifyMinutes,
+			&i.AlertEscalationChannels,
+			&i.AlertEscalationMinutes,
+			&i.Tags,
+			&i.HeartbeatToken,
+			&i.HeartbeatGraceSeconds,
+			&i.HeartbeatStartedAt,
+			&i.TriggerToken,
+			&i.TriggerSecret,
+			&i.TriggerRateLimit,
+			&i.BreakerFailureThreshold,
+			&i.BreakerProbeSeconds,
+			&i.BreakerState,
+			&i.BreakerFailures,
+			&i.BreakerOpenedAt,
+			&i.Body,
+			&i.RetryAttempts,
+			&i.RetryDelaySeconds,
+			&i.RetryAfterHostWide,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCertificateExpiries = `-- name: GetCertificateExpiries :many
+SELECT c.job_id, j.name AS job_name, c.job_run_id, c.host, c.subject, c.issuer, c.not_after
+FROM run_certificates c
+JOIN jobs j ON j.id = c.job_id
+WHERE c.position = 0
+  AND c.job_run_id = (
+    SELECT MAX(latest.job_run_id) FROM run_certificates latest
+    WHERE latest.job_id = c.job_id
+  )
+ORDER BY c.not_after ASC
+`
+
+type GetCertificateExpiriesRow struct {
+	JobID    int64
+	JobName  string
+	JobRunID int64
+	Host     string
+	Subject  string
+	Issuer   string
+	NotAfter time.Time
+}
+
+func (q *Queries) GetCertificateExpiries(ctx context.Context) ([]GetCertificateExpiriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCertificateExpiries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCertificateExpiriesRow
+	for rows.Next() {
+		var i GetCertificateExpiriesRow
+		if err := rows.Scan(
+			&i.JobID,
+			&i.JobName,
+			&i.JobRunID,
+			&i.Host,
+			&i.Subject,
+			&i.Issuer,
+			&i.NotAfter,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeadLetterByID = `-- name: GetDeadLetterByID :one
+SELECT id, job_id, job_run_id, method, url, headers, body, attempts, response_code, error, created_at, replay_run_id, replayed_at FROM dead_letters WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetDeadLetterByID(ctx context.Context, id int64) (DeadLetter, error) {
+	row := q.db.QueryRowContext(ctx, getDeadLetterByID, id)
+	var i DeadLetter
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.JobRunID,
+		&i.Method,
+		&i.Url,
+		&i.Headers,
+		&i.Body,
+		&i.Attempts,
+		&i.ResponseCode,
+		&i.Error,
+		&i.CreatedAt,
+		&i.ReplayRunID,
+		&i.ReplayedAt,
+	)
+	return i, err
+}
+
+const getDueJobs = `-- name: GetDueJobs :many
+SELECT id, name, url, method, headers, interval_seconds, next_run_at, active, tls_client_cert_file, tls_client_key_file, tls_ca_file, tls_server_name, tls_min_version, tls_insecure_skip_verify, proxy_url, follow_redirects, max_redirects, resolve, cert_expiry_days, cert_expiry_action, type, timeout_seconds, dns_record_type, dns_expected, dns_server, grpc_service, grpc_tls, fail_on_error_status, steps, send_message, expect_message, track_changes, track_json_path, max_body_bytes, redact_headers, retention_keep_runs, retention_keep_days, retention_keep_failures_days, alert_channels, alert_consecutive_failures, alert_failure_rate, alert_failure_window, alert_renotify_minutes, alert_escalation_channels, alert_escalation_minutes, tags, heartbeat_token, heartbeat_grace_seconds, heartbeat_started_at, trigger_token, trigger_secret, trigger_rate_limit, breaker_failure_threshold, breaker_probe_seconds, breaker_state, breaker_failures, breaker_opened_at, body, retry_attempts, retry_delay_seconds, retry_after_host_wide FROM jobs
+WHERE active = 1
+  AND next_run_at IS NOT NULL
+  AND next_run_at <= datetime('now')
+ORDER BY next_run_at ASC
+`
+
+func (q *Queries) GetDueJobs(ctx context.Context) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, getDueJobs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.Method,
+			&i.Headers,
+			&i.IntervalSeconds,
+			&i.NextRunAt,
+			&i.Active,
+			&i.TlsClientCertFile,
+			&i.TlsClientKeyFile,
+			&i.TlsCaFile,
+			&i.TlsServerName,
+			&i.TlsMinVersion,
+			&i.TlsInsecureSkipVerify,
+			&i.ProxyUrl,
+			&i.FollowRedirects,
+			&i.MaxRedirects,
+			&i.Resolve,
+			&i.CertExpiryDays,
+			&i.CertExpiryAction,
+			&i.Type,
+			&i.TimeoutSeconds,
+			&i.DnsRecordType,
+			&i.DnsExpected,
+			&i.DnsServer,
+			&i.GrpcService,
+			&i.GrpcTls,
+			&i.FailOnErrorStatus,
+			&i.Steps,
+			&i.SendMessage,
+			&i.ExpectMessage,
+			&i.TrackChanges,
+			&i.TrackJsonPath,
+			&i.MaxBodyBytes,
+			&i.RedactHeaders,
+			&i.RetentionKeepRuns,
+			&i.RetentionKeepDays,
+			&i.RetentionKeepFailuresDays,
+			&i.AlertChannels,
+			&i.AlertConsecutiveFailures,
+			&i.AlertFailureRate,
+			&i.AlertFailureWindow,
+			&i.AlertRenotifyMinutes,
+			&i.AlertEscalationChannels,
+			&i.AlertEscalationMinutes,
+			&i.Tags,
+			&i.HeartbeatToken,
+			&i.HeartbeatGraceSeconds,
+			&i.HeartbeatStartedAt,
+			&i.TriggerToken,
+			&i.TriggerSecret,
+			&i.TriggerRateLimit,
+			&i.BreakerFailureThreshold,
+			&i.BreakerProbeSeconds,
+			&i.BreakerState,
+			&i.BreakerFailures,
+			&i.BreakerOpenedAt,
+			&i.Body,
+			&i.RetryAttempts,
+			&i.RetryDelaySeconds,
+			&i.RetryAfterHostWide,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDueWebhookDeliveries = `-- name: GetDueWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, response_code, error, created_at FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at <= ?
+ORDER BY next_attempt_at
+LIMIT ?
+`
+
+type GetDueWebhookDeliveriesParams struct {
+	NextAttemptAt sql.NullTime
+	Limit         int64
+}
+
+func (q *Queries) GetDueWebhookDeliveries(ctx context.Context, arg GetDueWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, getDueWebhookDeliveries, arg.NextAttemptAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastAttemptAt,
+			&i.ResponseCode,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExpiredJobRunIDs = `-- name: GetExpiredJobRunIDs :many
+SELECT id FROM job_runs
+WHERE job_id = ?1
+  AND (
+    (status IN ('failed', 'running') AND started_at < ?2)
+    OR (status NOT IN ('failed', 'running') AND (started_at < ?3 OR id < ?4))
+  )
+ORDER BY id
+LIMIT ?5
+`
+
+type GetExpiredJobRunIDsParams struct {
+	JobID        int64
+	FailedBefore sql.NullTime
+	Before       sql.NullTime
+	MinKeptID    int64
+	BatchSize    int64
+}
+
+// Failed runs (and runs left "running" by a crash) only expire by age at
+// failed_before; every other run expires at before or when older than
+// min_kept_id.
+func (q *Queries) GetExpiredJobRunIDs(ctx context.Context, arg GetExpiredJobRunIDsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getExpiredJobRunIDs,
+		arg.JobID,
+		arg.FailedBefore,
+		arg.Before,
+		arg.MinKeptID,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIncident = `-- name: GetIncident :one
+SELECT id, job_id, state, reason, last_run_id, last_error, consecutive_failures, opened_at, acknowledged_at, acknowledged_by, escalated_at, resolved_at, last_notified_at, notifications FROM incidents WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetIncident(ctx context.Context, id int64) (Incident, error) {
+	row := q.db.QueryRowContext(ctx, getIncident, id)
+	var i Incident
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.State,
+		&i.Reason,
+		&i.LastRunID,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.OpenedAt,
+		&i.AcknowledgedAt,
+		&i.AcknowledgedBy,
+		&i.EscalatedAt,
+		&i.ResolvedAt,
+		&i.LastNotifiedAt,
+		&i.Notifications,
+	)
+	return i, err
+}
+
+const getJobAlertState = `-- name: GetJobAlertState :one
+SELECT job_id, state, consecutive_failures, changed_at FROM job_alert_states WHERE job_id = ? LIMIT 1
+`
+
+func (q *Queries) GetJobAlertState(ctx context.Context, jobID int64) (JobAlertState, error) {
+	row := q.db.QueryRowContext(ctx, getJobAlertState, jobID)
+	var i JobAlertState
+	err := row.Scan(
+		&i.JobID,
+		&i.State,
+		&i.ConsecutiveFailures,
+		&i.ChangedAt,
+	)
+	return i, err
+}
+
+const getJobByHeartbeatToken = `-- name: GetJobByHeartbeatToken :one
+SELECT id, name, url, method, headers, interval_seconds, next_run_at, active, tls_client_cert_file, tls_client_key_file, tls_ca_file, tls_server_name, tls_min_version, tls_insecure_skip_verify, proxy_url, follow_redirects, max_redirects, resolve, cert_expiry_days, cert_expiry_action, type, timeout_seconds, dns_record_type, dns_expected, dns_server, grpc_service, grpc_tls, fail_on_error_status, steps, send_message, expect_message, track_changes, track_json_path, max_body_bytes, redact_headers, retention_keep_runs, retention_keep_days, retention_keep_failures_days, alert_channels, alert_consecutive_failures, alert_failure_rate, alert_failure_window, alert_renotify_minutes, alert_escalation_channels, alert_escalation_minutes, tags, heartbeat_token, heartbeat_grace_seconds, heartbeat_started_at, trigger_token, trigger_secret, trigger_rate_limit, breaker_failure_threshold, breaker_probe_seconds, breaker_state, breaker_failures, breaker_opened_at, body, retry_attempts, retry_delay_seconds, retry_after_host_wide FROM jobs WHERE heartbeat_token = ? LIMIT 1
+`
+
+func (q *Queries) GetJobByHeartbeatToken(ctx context.Context, heartbeatToken sql.NullString) (Job, error) {
+	row := q.db.QueryRowContext(ctx, getJobByHeartbeatToken, heartbeatToken)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.Method,
+		&i.Headers,
+		&i.IntervalSeconds,
+		&i.NextRunAt,
+		&i.Active,
+		&i.TlsClientCertFile,
+		&i.TlsClientKeyFile,
+		&i.TlsCaFile,
+		&i.TlsServerName,
+		&i.TlsMinVersion,
+		&i.TlsInsecureSkipVerify,
+		&i.ProxyUrl,
+		&i.FollowRedirects,
+		&i.MaxRedirects,
+		&i.Resolve,
+		&i.CertExpiryDays,
+		&i.CertExpiryAction,
+		&i.Type,
+		&i.TimeoutSeconds,
+		&i.DnsRecordType,
+		&i.DnsExpected,
+		&i.DnsServer,
+		&i.GrpcService,
+		&i.GrpcTls,
+		&i.FailOnErrorStatus,
+		&i.Steps,
+		&i.SendMessage,
+		&i.ExpectMessage,
+		&i.TrackChanges,
+		&i.TrackJsonPath,
+		&i.MaxBodyBytes,
+		&i.RedactHeaders,
+		&i.RetentionKeepRuns,
+		&i.RetentionKeepDays,
+		&i.RetentionKeepFailuresDays,
+		&i.AlertChannels,
+		&i.AlertConsecutiveFailures,
+		&i.AlertFailureRate,
+		&i.AlertFailureWindow,
+		&i.AlertRenotifyMinutes,
+		&i.AlertEscalationChannels,
+		&i.AlertEscalationMinutes,
+		&i.Tags,
+		&i.HeartbeatToken,
+		&i.HeartbeatGraceSeconds,
+		&i.HeartbeatStartedAt,
+		&i.TriggerToken,
+		&i.TriggerSecret,
+		&i.TriggerRateLimit,
+		&i.BreakerFailureThreshold,
+		&i.BreakerProbeSeconds,
+		&i.BreakerState,
+		&i.BreakerFailures,
+		&i.BreakerOpenedAt,
+		&i.Body,
+		&i.RetryAttempts,
+		&i.RetryDelaySeconds,
+		&i.RetryAfterHostWide,
+	)
+	return i, err
+}
+
+const getJobByID = `-- name: GetJobByID :one
+SELECT id, name, url, method, headers, interval_seconds, next_run_at, active, tls_client_cert_file, tls_client_key_file, tls_ca_file, tls_server_name, tls_min_version, tls_insecure_skip_verify, proxy_url, follow_redirects, max_redirects, resolve, cert_expiry_days, cert_expiry_action, type, timeout_seconds, dns_record_type, dns_expected, dns_server, grpc_service, grpc_tls, fail_on_error_status, steps, send_message, expect_message, track_changes, track_json_path, max_body_bytes, redact_headers, retention_keep_runs, retention_keep_days, retention_keep_failures_days, alert_channels, alert_consecutive_failures, alert_failure_rate, alert_failure_window, alert_renotify_minutes, alert_escalation_channels, alert_escalation_minutes, tags, heartbeat_token, heartbeat_grace_seconds, heartbeat_started_at, trigger_token, trigger_secret, trigger_rate_limit, breaker_failure_threshold, breaker_probe_seconds, breaker_state, breaker_failures, breaker_opened_at, body, retry_attempts, retry_delay_seconds, retry_after_host_wide FROM jobs WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetJobByID(ctx context.Context, id int64) (Job, error) {
+	row := q.db.QueryRowContext(ctx, getJobByID, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.Method,
+		&i.Headers,
+		&i.IntervalSeconds,
+		&i.NextRunAt,
+		&i.Active,
+		&i.TlsClientCertFile,
+		&i.TlsClientKeyFile,
+		&i.TlsCaFile,
+		&i.TlsServerName,
+		&i.TlsMinVersion,
+		&i.TlsInsecureSkipVerify,
+		&i.ProxyUrl,
+		&i.FollowRedirects,
+		&i.MaxRedirects,
+		&i.Resolve,
+		&i.CertExpiryDays,
+		&i.CertExpiryAction,
+		&i.Type,
+		&i.TimeoutSeconds,
+		&i.DnsRecordType,
+		&i.DnsExpected,
+		&i.DnsServer,
+		&i.GrpcService,
+		&i.GrpcTls,
+		&i.FailOnErrorStatus,
+		&i.Steps,
+		&i.SendMessage,
+		&i.ExpectMessage,
+		&i.TrackChanges,
+		&i.TrackJsonPath,
+		&i.MaxBodyBytes,
+		&i.RedactHeaders,
+		&i.RetentionKeepRuns,
+		&i.RetentionKeepDays,
+		&i.RetentionKeepFailuresDays,
+		&i.AlertChannels,
+		&i.AlertConsecutiveFailures,
+		&i.AlertFailureRate,
+		&i.AlertFailureWindow,
+		&i.AlertRenotifyMinutes,
+		&i.AlertEscalationChannels,
+		&i.AlertEscalationMinutes,
+		&i.Tags,
+		&i.HeartbeatToken,
+		&i.HeartbeatGraceSeconds,
+		&i.HeartbeatStartedAt,
+		&i.TriggerToken,
+		&i.TriggerSecret,
+		&i.TriggerRateLimit,
+		&i.BreakerFailureThreshold,
+		&i.BreakerProbeSeconds,
+		&i.BreakerState,
+		&i.BreakerFailures,
+		&i.BreakerOpenedAt,
+		&i.Body,
+		&i.RetryAttempts,
+		&i.RetryDelaySeconds,
+		&i.RetryAfterHostWide,
+	)
+	return i, err
+}
+
+const getJobByTriggerToken = `-- name: GetJobByTriggerToken :one
+SELECT id, name, url, method, headers, interval_seconds, next_run_at, active, tls_client_cert_file, tls_client_key_file, tls_ca_file, tls_server_name, tls_min_version, tls_insecure_skip_verify, proxy_url, follow_redirects, max_redirects, resolve, cert_expiry_days, cert_expiry_action, type, timeout_seconds, dns_record_type, dns_expected, dns_server, grpc_service, grpc_tls, fail_on_error_status, steps, send_message, expect_message, track_changes, track_json_path, max_body_bytes, redact_headers, retention_keep_runs, retention_keep_days, retention_keep_failures_days, alert_channels, alert_consecutive_failures, alert_failure_rate, alert_failure_window, alert_renotify_minutes, alert_escalation_channels, alert_escalation_minutes, tags, heartbeat_token, heartbeat_grace_seconds, heartbeat_started_at, trigger_token, trigger_secret, trigger_rate_limit, breaker_failure_threshold, breaker_probe_seconds, breaker_state, breaker_failures, breaker_opened_at, body, retry_attempts, retry_delay_seconds, retry_after_host_wide FROM jobs WHERE trigger_token = ? LIMIT 1
+`
+
+func (q *Queries) GetJobByTriggerToken(ctx context.Context, triggerToken sql.NullString) (Job, error) {
+	row := q.db.QueryRowContext(ctx, getJobByTriggerToken, triggerToken)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.Method,
+		&i.Headers,
+		&i.IntervalSeconds,
+		&i.NextRunAt,
+		&i.Active,
+		&i.TlsClientCertFile,
+		&i.TlsClientKeyFile,
+		&i.TlsCaFile,
+		&i.TlsServerName,
+		&i.TlsMinVersion,
+		&i.TlsInsecureSkipVerify,
+		&i.ProxyUrl,
+		&i.FollowRedirects,
+		&i.MaxRedirects,
+		&i.Resolve,
+		&i.CertExpiryDays,
+		&i.CertExpiryAction,
+		&i.Type,
+		&i.TimeoutSeconds,
+		&i.DnsRecordType,
+		&i.DnsExpected,
+		&i.DnsServer,
+		&i.GrpcService,
+		&i.GrpcTls,
+		&i.FailOnErrorStatus,
+		&i.Steps,
+		&i.SendMessage,
+		&i.ExpectMessage,
+		&i.TrackChanges,
+		&i.TrackJsonPath,
+		&i.MaxBodyBytes,
+		&i.RedactHeaders,
+		&i.RetentionKeepRuns,
+		&i.RetentionKeepDays,
+		&i.RetentionKeepFailuresDays,
+		&i.AlertChannels,
+		&i.AlertConsecutiveFailures,
+		&i.AlertFailureRate,
+		&i.AlertFailureWindow,
+		&i.AlertRenotifyMinutes,
+		&i.AlertEscalationChannels,
+		&i.AlertEscalationMinutes,
+		&i.Tags,
+		&i.HeartbeatToken,
+		&i.HeartbeatGraceSeconds,
+		&i.HeartbeatStartedAt,
+		&i.TriggerToken,
+		&i.TriggerSecret,
+		&i.TriggerRateLimit,
+		&i.BreakerFailureThreshold,
+		&i.BreakerProbeSeconds,
+		&i.BreakerState,
+		&i.BreakerFailures,
+		&i.BreakerOpenedAt,
+		&i.Body,
+		&i.RetryAttempts,
+		&i.RetryDelaySeconds,
+		&i.RetryAfterHostWide,
+	)
+	return i, err
+}
+
+const getJobLatencyHistogram = `-- name: GetJobLatencyHistogram :many
+SELECT le_ms, CAST(SUM(runs) AS INTEGER) AS runs
+FROM job_latency_rollups
+WHERE job_id = ? AND bucket_start >= ?
+GROUP BY le_ms
+`
+
+type GetJobLatencyHistogramParams struct {
+	JobID       int64
+	BucketStart int64
+}
+
+type GetJobLatencyHistogramRow struct {
+	LeMs int64
+	Runs int64
+}
+
+func (q *Queries) GetJobLatencyHistogram(ctx context.Context, arg GetJobLatencyHistogramParams) ([]GetJobLatencyHistogramRow, error) {
+	rows, err := q.db.QueryContext(ctx, getJobLatencyHistogram, arg.JobID, arg.BucketStart)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetJobLatencyHistogramRow
+	for rows.Next() {
+		var i GetJobLatencyHistogramRow
+		if err := rows.Scan(&i.LeMs, &i.Runs); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getJobRollups = `-- name: GetJobRollups :many
+SELECT job_id, bucket_start, status, runs, latency_sum_ms, latency_count FROM job_rollups
+WHERE job_id = ? AND bucket_start >= ?
+ORDER BY bucket_start
+`
+
+type GetJobRollupsParams struct {
+	JobID       int64
+	BucketStart int64
+}
+
+func (q *Queries) GetJobRollups(ctx context.Context, arg GetJobRollupsParams) ([]JobRollup, error) {
+	rows, err := q.db.QueryContext(ctx, getJobRollups, arg.JobID, arg.BucketStart)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRollup
+	for rows.Next() {
+		var i JobRollup
+		if err := rows.Scan(
+			&i.JobID,
+			&i.BucketStart,
+			&i.Status,
+			&i.Runs,
+			&i.LatencySumMs,
+			&i.LatencyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getJobRunByID = `-- name: GetJobRunByID :one
+SELECT id, job_id, status, response_code, response_body, started_at, finished_at, dns_ms, connect_ms, tls_ms, ttfb_ms, total_ms, error, body_hash, changed, body_diff, response_headers, response_encoding, response_size, response_truncated, replay_of_run_id FROM job_runs WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetJobRunByID(ctx context.Context, id int64) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, getJobRunByID, id)
+	var i JobRun
+	err := row.Scan(
+		&i.ID,
+		&i.JobID,
+		&i.Status,
+		&i.ResponseCode,
+		&i.ResponseBody,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.DnsMs,
+		&i.ConnectMs,
+		&i.TlsMs,
+		&i.TtfbMs,
+		&i.TotalMs,
+		&i.Error,
+		&i.BodyHash,
+		&i.Changed,
+		&i.BodyDiff,
+		&i.ResponseHeaders,
+		&i.ResponseEncoding,
+		&i.ResponseSize,
+		&i.ResponseTruncated,
+		&i.ReplayOfRunID,
+	)
+	return i, err
+}
+
+const getJobRunSteps = `-- name: GetJobRunSteps :many
+SELECT id, job_run_id, position, name, status, response_code, duration_ms, error FROM job_run_steps
+WHERE job_run_id = ?
+ORDER BY position
+`
+
+func (q *Queries) GetJobRunSteps(ctx context.Context, jobRunID int64) ([]JobRunStep, error) {
+	rows, err := q.db.QueryContext(ctx, getJobRunSteps, jobRunID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRunStep
+	for rows.Next() {
+		var i JobRunStep
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobRunID,
+			&i.Position,
+			&i.Name,
+			&i.Status,
+			&i.ResponseCode,
+			&i.DurationMs,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getJobRuns = `-- name: GetJobRuns :many
+SELECT id, job_id, status, response_code, response_body, started_at, finished_at, dns_ms, connect_ms, tls_ms, ttfb_ms, total_ms, error, body_hash, changed, body_diff, response_headers, response_encoding, response_size, response_truncated, replay_of_run_id FROM job_runs
+WHERE job_id = ?
+ORDER BY id DESC
+LIMIT ?
+`
+
+type GetJobRunsParams struct {
+	JobID int64
+	Limit int64
+}
+
+func (q *Queries) GetJobRuns(ctx context.Context, arg GetJobRunsParams) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, getJobRuns, arg.JobID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRun
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.Status,
+			&i.ResponseCode,
+			&i.ResponseBody,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.DnsMs,
+			&i.ConnectMs,
+			&i.TlsMs,
+			&i.TtfbMs,
+			&i.TotalMs,
+			&i.Error,
+			&i.BodyHash,
+			&i.Changed,
+			&i.BodyDiff,
+			&i.ResponseHeaders,
+			&i.ResponseEncoding,
+			&i.ResponseSize,
+			&i.ResponseTruncated,
+			&i.ReplayOfRunID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNotificationChannel = `-- name: GetNotificationChannel :one
+SELECT id, name, type, config, created_at FROM notification_channels WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetNotificationChannel(ctx context.Context, id int64) (NotificationChannel, error) {
+	row := q.db.QueryRowContext(ctx, getNotificationChannel, id)
+	var i NotificationChannel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.Config,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getNotificationChannels = `-- name: GetNotificationChannels :many
+SELECT id, name, type, config, created_at FROM notification_channels
+ORDER BY id
+`
+
+func (q *Queries) GetNotificationChannels(ctx context.Context) ([]NotificationChannel, error) {
+	rows, err := q.db.QueryContext(ctx, getNotificationChannels)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NotificationChannel
+	for rows.Next() {
+		var i NotificationChannel
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Type,
+			&i.Config,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNotificationChannelsByIDs = `-- name: GetNotificationChannelsByIDs :many
+SELECT id, name, type, config, created_at FROM notification_channels
+WHERE id IN (/*SLICE:ids*/?)
+ORDER BY id
+`
+
+func (q *Queries) GetNotificationChannelsByIDs(ctx context.Context, ids []int64) ([]NotificationChannel, error) {
+	query := getNotificationChannelsByIDs
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NotificationChannel
+	for rows.Next() {
+		var i NotificationChannel
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Type,
+			&i.Config,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNthLatestJobRunID = `-- name: GetNthLatestJobRunID :one
+SELECT id FROM job_runs
+WHERE job_id = ?
+ORDER BY id DESC
+LIMIT 1 OFFSET ?
+`
+
+type GetNthLatestJobRunIDParams struct {
+	JobID  int64
+	Offset int64
+}
+
+func (q *Queries) GetNthLatestJobRunID(ctx context.Context, arg GetNthLatestJobRunIDParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getNthLatestJobRunID, arg.JobID, arg.Offset)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getOpenIncidents = `-- name: GetOpenIncidents :many
+SELECT id, job_id, state, reason, last_run_id, last_error, consecutive_failures, opened_at, acknowledged_at, acknowledged_by, escalated_at, resolved_at, last_notified_at, notifications FROM incidents
+WHERE state = 'open'
+ORDER BY id
+`
+
+func (q *Queries) GetOpenIncidents(ctx context.Context) ([]Incident, error) {
+	rows, err := q.db.QueryContext(ctx, getOpenIncidents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Incident
+	for rows.Next() {
+		var i Incident
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.State,
+			&i.Reason,
+			&i.LastRunID,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.OpenedAt,
+			&i.AcknowledgedAt,
+			&i.AcknowledgedBy,
+			&i.EscalatedAt,
+			&i.ResolvedAt,
+			&i.LastNotifiedAt,
+			&i.Notifications,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrphanedJobRunIDs = `-- name: GetOrphanedJobRunIDs :many
+SELECT id FROM job_runs
+WHERE job_id NOT IN (SELECT id FROM jobs)
+ORDER BY id
+LIMIT ?
+`
+
+// Runs left behind by deleted jobs.
+func (q *Queries) GetOrphanedJobRunIDs(ctx context.Context, limit int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getOrphanedJobRunIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrackedContent = `-- name: GetTrackedContent :one
+SELECT job_id, body_hash, content, updated_at FROM job_tracked_content WHERE job_id = ? LIMIT 1
+`
+
+func (q *Queries) GetTrackedContent(ctx context.Context, jobID int64) (JobTrackedContent, error) {
+	row := q.db.QueryRowContext(ctx, getTrackedContent, jobID)
+	var i JobTrackedContent
+	err := row.Scan(
+		&i.JobID,
+		&i.BodyHash,
+		&i.Content,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const createJobRun = `-- name: CreateJobRun :one
-INSERT INTO job_runs (job_id, status, started_at)
-VALUES (?, ?, ?)
-RETURNING id, job_id, status, response_code, response_body, started_at, finished_at
+const getWebhookDeliveries = `-- name: GetWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, response_code, error, created_at FROM webhook_deliveries
+WHERE subscription_id = ?
+ORDER BY id DESC
+LIMIT ?
 `
 
-type CreateJobRunParams struct {
-	JobID     int64
-	Status    sql.NullString
-	StartedAt sql.NullTime
+type GetWebhookDeliveriesParams struct {
+	SubscriptionID int64
+	Limit          int64
 }
 
-func (q *Queries) CreateJobRun(ctx context.Context, arg CreateJobRunParams) (JobRun, error) {
-	row := q.db.QueryRowContext(ctx, createJobRun, arg.JobID, arg.Status, arg.StartedAt)
-	var i JobRun
+func (q *Queries) GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookDeliveries, arg.SubscriptionID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastAttemptAt,
+			&i.ResponseCode,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookSubscription = `-- name: GetWebhookSubscription :one
+SELECT id, url, secret, events, active, created_at FROM webhook_subscriptions WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookSubscription, id)
+	var i WebhookSubscription
 	err := row.Scan(
 		&i.ID,
-		&i.JobID,
-		&i.Status,
-		&i.ResponseCode,
-		&i.ResponseBody,
-		&i.StartedAt,
-		&i.FinishedAt,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Active,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const deleteJob = `-- name: DeleteJob :exec
-DELETE FROM jobs
-WHERE id = ?
+const getWebhookSubscriptions = `-- name: GetWebhookSubscriptions :many
+SELECT id, url, secret, events, active, created_at FROM webhook_subscriptions
+ORDER BY id
 `
 
-func (q *Queries) DeleteJob(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteJob, id)
+func (q *Queries) GetWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookSubscriptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookSubscription
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const linkDeadLetterReplay = `-- name: LinkDeadLetterReplay :exec
+UPDATE dead_letters SET replay_run_id = ? WHERE id = ?
+`
+
+type LinkDeadLetterReplayParams struct {
+	ReplayRunID sql.NullInt64
+	ID          int64
+}
+
+func (q *Queries) LinkDeadLetterReplay(ctx context.Context, arg LinkDeadLetterReplayParams) error {
+	_, err := q.db.ExecContext(ctx, linkDeadLetterReplay, arg.ReplayRunID, arg.ID)
 	return err
 }
 
-const getAllJobs = `-- name: GetAllJobs :many
-SELECT id, name, url, method, headers, interval_seconds, next_run_at, active FROM jobs
-ORDER BY id
+const listDeadLetters = `-- name: ListDeadLetters :many
+SELECT id, job_id, job_run_id, method, url, headers, body, attempts, response_code, error, created_at, replay_run_id, replayed_at FROM dead_letters
+WHERE (CAST(?1 AS INTEGER) = 0 OR job_id = ?1)
+  AND (CAST(?2 AS TEXT) = ''
+    OR (?2 = 'pending' AND replayed_at IS NULL)
+    OR (?2 = 'replayed' AND replayed_at IS NOT NULL))
+ORDER BY id DESC
+LIMIT ?3
 `
 
-func (q *Queries) GetAllJobs(ctx context.Context) ([]Job, error) {
-	rows, err := q.db.QueryContext(ctx, getAllJobs)
+type ListDeadLettersParams struct {
+	JobID  int64
+	Status string
+	Limit  int64
+}
+
+func (q *Queries) ListDeadLetters(ctx context.Context, arg ListDeadLettersParams) ([]DeadLetter, error) {
+	rows, err := q.db.QueryContext(ctx, listDeadLetters, arg.JobID, arg.Status, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Job
+	var items []DeadLetter
 	for rows.Next() {
-		var i Job
+		var i DeadLetter
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Url,
+			&i.JobID,
+			&i.JobRunID,
 			&i.Method,
+			&i.Url,
 			&i.Headers,
-			&i.IntervalSeconds,
-			&i.NextRunAt,
-			&i.Active,
+			&i.Body,
+			&i.Attempts,
+			&i.ResponseCode,
+			&i.Error,
+			&i.CreatedAt,
+			&i.ReplayRunID,
+			&i.ReplayedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -127,32 +2111,44 @@ func (q *Queries) GetAllJobs(ctx context.Context) ([]Job, error) {
 	return items, nil
 }
 
-const getDueJobs = `-- name: GetDueJobs :many
-SELECT id, name, url, method, headers, interval_seconds, next_run_at, active FROM jobs
-WHERE active = 1
-  AND next_run_at IS NOT NULL
-  AND next_run_at <= datetime('now')
-ORDER BY next_run_at ASC
+const listIncidents = `-- name: ListIncidents :many
+SELECT id, job_id, state, reason, last_run_id, last_error, consecutive_failures, opened_at, acknowledged_at, acknowledged_by, escalated_at, resolved_at, last_notified_at, notifications FROM incidents
+WHERE (CAST(?1 AS TEXT) = '' OR state = ?1)
+  AND (CAST(?2 AS INTEGER) = 0 OR job_id = ?2)
+ORDER BY id DESC
+LIMIT ?3
 `
 
-func (q *Queries) GetDueJobs(ctx context.Context) ([]Job, error) {
-	rows, err := q.db.QueryContext(ctx, getDueJobs)
+type ListIncidentsParams struct {
+	State string
+	JobID int64
+	Limit int64
+}
+
+func (q *Queries) ListIncidents(ctx context.Context, arg ListIncidentsParams) ([]Incident, error) {
+	rows, err := q.db.QueryContext(ctx, listIncidents, arg.State, arg.JobID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Job
+	var items []Incident
 	for rows.Next() {
-		var i Job
+		var i Incident
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Url,
-			&i.Method,
-			&i.Headers,
-			&i.IntervalSeconds,
-			&i.NextRunAt,
-			&i.Active,
+			&i.JobID,
+			&i.State,
+			&i.Reason,
+			&i.LastRunID,
+			&i.LastError,
+			&i.ConsecutiveFailures,
+			&i.OpenedAt,
+			&i.AcknowledgedAt,
+			&i.AcknowledgedBy,
+			&i.EscalatedAt,
+			&i.ResolvedAt,
+			&i.LastNotifiedAt,
+			&i.Notifications,
 		); err != nil {
 			return nil, err
 		}
@@ -167,41 +2163,214 @@ func (q *Queries) GetDueJobs(ctx context.Context) ([]Job, error) {
 	return items, nil
 }
 
-const getJobByID = `-- name: GetJobByID :one
-SELECT id, name, url, method, headers, interval_seconds, next_run_at, active FROM jobs WHERE id = ? LIMIT 1
+const markDeadLetterReplayed = `-- name: MarkDeadLetterReplayed :exec
+UPDATE dead_letters SET replayed_at = ? WHERE id = ?
 `
 
-func (q *Queries) GetJobByID(ctx context.Context, id int64) (Job, error) {
-	row := q.db.QueryRowContext(ctx, getJobByID, id)
-	var i Job
+type MarkDeadLetterReplayedParams struct {
+	ReplayedAt sql.NullTime
+	ID         int64
+}
+
+func (q *Queries) MarkDeadLetterReplayed(ctx context.Context, arg MarkDeadLetterReplayedParams) error {
+	_, err := q.db.ExecContext(ctx, markDeadLetterReplayed, arg.ReplayedAt, arg.ID)
+	return err
+}
+
+const markIncidentEscalated = `-- name: MarkIncidentEscalated :execrows
+UPDATE incidents
+SET escalated_at = ?, last_notified_at = ?, notifications = notifications + 1
+WHERE id = ? AND state = 'open' AND escalated_at IS NULL
+`
+
+type MarkIncidentEscalatedParams struct {
+	EscalatedAt    sql.NullTime
+	LastNotifiedAt time.Time
+	ID             int64
+}
+
+func (q *Queries) MarkIncidentEscalated(ctx context.Context, arg MarkIncidentEscalatedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markIncidentEscalated, arg.EscalatedAt, arg.LastNotifiedAt, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markIncidentNotified = `-- name: MarkIncidentNotified :execrows
+UPDATE incidents
+SET last_notified_at = ?, notifications = notifications + 1
+WHERE id = ? AND state = 'open'
+`
+
+type MarkIncidentNotifiedParams struct {
+	LastNotifiedAt time.Time
+	ID             int64
+}
+
+func (q *Queries) MarkIncidentNotified(ctx context.Context, arg MarkIncidentNotifiedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markIncidentNotified, arg.LastNotifiedAt, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resolveIncident = `-- name: ResolveIncident :one
+UPDATE incidents
+SET state = 'resolved', resolved_at = ?
+WHERE id = ? AND state != 'resolved'
+RETURNING id, job_id, state, reason, last_run_id, last_error, consecutive_failures, opened_at, acknowledged_at, acknowledged_by, escalated_at, resolved_at, last_notified_at, notifications
+`
+
+type ResolveIncidentParams struct {
+	ResolvedAt sql.NullTime
+	ID         int64
+}
+
+func (q *Queries) ResolveIncident(ctx context.Context, arg ResolveIncidentParams) (Incident, error) {
+	row := q.db.QueryRowContext(ctx, resolveIncident, arg.ResolvedAt, arg.ID)
+	var i Incident
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Url,
-		&i.Method,
-		&i.Headers,
-		&i.IntervalSeconds,
-		&i.NextRunAt,
-		&i.Active,
+		&i.JobID,
+		&i.State,
+		&i.Reason,
+		&i.LastRunID,
+		&i.LastError,
+		&i.ConsecutiveFailures,
+		&i.OpenedAt,
+		&i.AcknowledgedAt,
+		&i.AcknowledgedBy,
+		&i.EscalatedAt,
+		&i.ResolvedAt,
+		&i.LastNotifiedAt,
+		&i.Notifications,
 	)
 	return i, err
 }
 
+const startJobHeartbeat = `-- name: StartJobHeartbeat :exec
+UPDATE jobs
+SET heartbeat_started_at = ?
+WHERE id = ?
+`
+
+type StartJobHeartbeatParams struct {
+	HeartbeatStartedAt sql.NullTime
+	ID                 int64
+}
+
+func (q *Queries) StartJobHeartbeat(ctx context.Context, arg StartJobHeartbeatParams) error {
+	_, err := q.db.ExecContext(ctx, startJobHeartbeat, arg.HeartbeatStartedAt, arg.ID)
+	return err
+}
+
+const updateIncidentFailure = `-- name: UpdateIncidentFailure :exec
+UPDATE incidents
+SET last_run_id = ?, last_error = ?, consecutive_failures = ?
+WHERE id = ? AND state != 'resolved'
+`
+
+type UpdateIncidentFailureParams struct {
+	LastRunID           sql.NullInt64
+	LastError           sql.NullString
+	ConsecutiveFailures int64
+	ID                  int64
+}
+
+func (q *Queries) UpdateIncidentFailure(ctx context.Context, arg UpdateIncidentFailureParams) error {
+	_, err := q.db.ExecContext(ctx, updateIncidentFailure,
+		arg.LastRunID,
+		arg.LastError,
+		arg.ConsecutiveFailures,
+		arg.ID,
+	)
+	return err
+}
+
 const updateJob = `-- name: UpdateJob :one
 UPDATE jobs
-SET name = ?, url = ?, method = ?, headers = ?, interval_seconds = ?, active = ?
+SET name = ?, url = ?, method = ?, headers = ?, interval_seconds = ?, active = ?,
+    tls_client_cert_file = ?, tls_client_key_file = ?, tls_ca_file = ?,
+    tls_server_name = ?, tls_min_version = ?, tls_insecure_skip_verify = ?,
+    proxy_url = ?, follow_redirects = ?, max_redirects = ?, resolve = ?,
+    cert_expiry_days = ?, cert_expiry_action = ?,
+    type = ?, timeout_seconds = ?,
+    dns_record_type = ?, dns_expected = ?, dns_server = ?,
+    grpc_service = ?, grpc_tls = ?, fail_on_error_status = ?, steps = ?,
+    send_message = ?, expect_message = ?,
+    track_changes = ?, track_json_path = ?,
+    max_body_bytes = ?, redact_headers = ?,
+    retention_keep_runs = ?, retention_keep_days = ?, retention_keep_failures_days = ?,
+    alert_channels = ?, alert_consecutive_failures = ?, alert_failure_rate = ?, alert_failure_window = ?,
+    alert_renotify_minutes = ?, alert_escalation_channels = ?, alert_escalation_minutes = ?,
+    tags = ?, heartbeat_token = ?, heartbeat_grace_seconds = ?,
+    trigger_token = ?, trigger_secret = ?, trigger_rate_limit = ?,
+    breaker_failure_threshold = ?, breaker_probe_seconds = ?,
+    body = ?, retry_attempts = ?, retry_delay_seconds = ?,
+    retry_after_host_wide = ?
 WHERE id = ?
-RETURNING id, name, url, method, headers, interval_seconds, next_run_at, active
+RETURNING id, name, url, method, headers, interval_seconds, next_run_at, active, tls_client_cert_file, tls_client_key_file, tls_ca_file, tls_server_name, tls_min_version, tls_insecure_skip_verify, proxy_url, follow_redirects, max_redirects, resolve, cert_expiry_days, cert_expiry_action, type, timeout_seconds, dns_record_type, dns_expected, dns_server, grpc_service, grpc_tls, fail_on_error_status, steps, send_message, expect_message, track_changes, track_json_path, max_body_bytes, redact_headers, retention_keep_runs, retention_keep_days, retention_keep_failures_days, alert_channels, alert_consecutive_failures, alert_failure_rate, alert_failure_window, alert_renotify_minutes, alert_escalation_channels, alert_escalation_minutes, tags, heartbeat_token, heartbeat_grace_seconds, heartbeat_started_at, trigger_token, trigger_secret, trigger_rate_limit, breaker_failure_threshold, breaker_probe_seconds, breaker_state, breaker_failures, breaker_opened_at, body, retry_attempts, retry_delay_seconds, retry_after_host_wide
 `
 
 type UpdateJobParams struct {
-	Name            string
-	Url             string
-	Method          interface{}
-	Headers         sql.NullString
-	IntervalSeconds int64
-	Active          sql.NullBool
-	ID              int64
+	Name                      string
+	Url                       string
+	Method                    interface{}
+	Headers                   sql.NullString
+	IntervalSeconds           int64
+	Active                    sql.NullBool
+	TlsClientCertFile         sql.NullString
+	TlsClientKeyFile          sql.NullString
+	TlsCaFile                 sql.NullString
+	TlsServerName             sql.NullString
+	TlsMinVersion             sql.NullString
+	TlsInsecureSkipVerify     sql.NullBool
+	ProxyUrl                  sql.NullString
+	FollowRedirects           sql.NullBool
+	MaxRedirects              sql.NullInt64
+	Resolve                   sql.NullString
+	CertExpiryDays            sql.NullInt64
+	CertExpiryAction          string
+	Type                      string
+	TimeoutSeconds            int64
+	DnsRecordType             sql.NullString
+	DnsExpected               sql.NullString
+	DnsServer                 sql.NullString
+	GrpcService               sql.NullString
+	GrpcTls                   sql.NullBool
+	FailOnErrorStatus         sql.NullBool
+	Steps                     sql.NullString
+	SendMessage               sql.NullString
+	ExpectMessage             sql.NullString
+	TrackChanges              sql.NullBool
+	TrackJsonPath             sql.NullString
+	MaxBodyBytes              int64
+	RedactHeaders             sql.NullString
+	RetentionKeepRuns         sql.NullInt64
+	RetentionKeepDays         sql.NullInt64
+	RetentionKeepFailuresDays sql.NullInt64
+	AlertChannels             sql.NullString
+	AlertConsecutiveFailures  sql.NullInt64
+	AlertFailureRate          sql.NullInt64
+	AlertFailureWindow        int64
+	AlertRenotifyMinutes      sql.NullInt64
+	AlertEscalationChannels   sql.NullString
+	AlertEscalationMinutes    sql.NullInt64
+	Tags                      sql.NullString
+	HeartbeatToken            sql.NullString
+	HeartbeatGraceSeconds     sql.NullInt64
+	TriggerToken              sql.NullString
+	TriggerSecret             sql.NullString
+	TriggerRateLimit          sql.NullInt64
+	BreakerFailureThreshold   sql.NullInt64
+	BreakerProbeSeconds       sql.NullInt64
+	Body                      sql.NullString
+	RetryAttempts             sql.NullInt64
+	RetryDelaySeconds         sql.NullInt64
+	RetryAfterHostWide        sql.NullBool
+	ID                        int64
 }
 
 func (q *Queries) UpdateJob(ctx context.Context, arg UpdateJobParams) (Job, error) {
@@ -212,6 +2381,55 @@ func (q *Queries) UpdateJob(ctx context.Context, arg UpdateJobParams) (Job, erro
 		arg.Headers,
 		arg.IntervalSeconds,
 		arg.Active,
+		arg.TlsClientCertFile,
+		arg.TlsClientKeyFile,
+		arg.TlsCaFile,
+		arg.TlsServerName,
+		arg.TlsMinVersion,
+		arg.TlsInsecureSkipVerify,
+		arg.ProxyUrl,
+		arg.FollowRedirects,
+		arg.MaxRedirects,
+		arg.Resolve,
+		arg.CertExpiryDays,
+		arg.CertExpiryAction,
+		arg.Type,
+		arg.TimeoutSeconds,
+		arg.DnsRecordType,
+		arg.DnsExpected,
+		arg.DnsServer,
+		arg.GrpcService,
+		arg.GrpcTls,
+		arg.FailOnErrorStatus,
+		arg.Steps,
+		arg.SendMessage,
+		arg.ExpectMessage,
+		arg.TrackChanges,
+		arg.TrackJsonPath,
+		arg.MaxBodyBytes,
+		arg.RedactHeaders,
+		arg.RetentionKeepRuns,
+		arg.RetentionKeepDays,
+		arg.RetentionKeepFailuresDays,
+		arg.AlertChannels,
+		arg.AlertConsecutiveFailures,
+		arg.AlertFailureRate,
+		arg.AlertFailureWindow,
+		arg.AlertRenotifyMinutes,
+		arg.AlertEscalationChannels,
+		arg.AlertEscalationMinutes,
+		arg.Tags,
+		arg.HeartbeatToken,
+		arg.HeartbeatGraceSeconds,
+		arg.TriggerToken,
+		arg.TriggerSecret,
+		arg.TriggerRateLimit,
+		arg.BreakerFailureThreshold,
+		arg.BreakerProbeSeconds,
+		arg.Body,
+		arg.RetryAttempts,
+		arg.RetryDelaySeconds,
+		arg.RetryAfterHostWide,
 		arg.ID,
 	)
 	var i Job
@@ -224,10 +2442,86 @@ func (q *Queries) UpdateJob(ctx context.Context, arg UpdateJobParams) (Job, erro
 		&i.IntervalSeconds,
 		&i.NextRunAt,
 		&i.Active,
+		&i.TlsClientCertFile,
+		&i.TlsClientKeyFile,
+		&i.TlsCaFile,
+		&i.TlsServerName,
+		&i.TlsMinVersion,
+		&i.TlsInsecureSkipVerify,
+		&i.ProxyUrl,
+		&i.FollowRedirects,
+		&i.MaxRedirects,
+		&i.Resolve,
+		&i.CertExpiryDays,
+		&i.CertExpiryAction,
+		&i.Type,
+		&i.TimeoutSeconds,
+		&i.DnsRecordType,
+		&i.DnsExpected,
+		&i.DnsServer,
+		&i.GrpcService,
+		&i.GrpcTls,
+		&i.FailOnErrorStatus,
+		&i.Steps,
+		&i.SendMessage,
+		&i.ExpectMessage,
+		&i.TrackChanges,
+		&i.TrackJsonPath,
+		&i.MaxBodyBytes,
+		&i.RedactHeaders,
+		&i.RetentionKeepRuns,
+		&i.RetentionKeepDays,
+		&i.RetentionKeepFailuresDays,
+		&i.AlertChannels,
+		&i.AlertConsecutiveFailures,
+		&i.AlertFailureRate,
+		&i.AlertFailureWindow,
+		&i.AlertRenotifyMinutes,
+		&i.AlertEscalationChannels,
+		&i.AlertEscalationMinutes,
+		&i.Tags,
+		&i.HeartbeatToken,
+		&i.HeartbeatGraceSeconds,
+		&i.HeartbeatStartedAt,
+		&i.TriggerToken,
+		&i.TriggerSecret,
+		&i.TriggerRateLimit,
+		&i.BreakerFailureThreshold,
+		&i.BreakerProbeSeconds,
+		&i.BreakerState,
+		&i.BreakerFailures,
+		&i.BreakerOpenedAt,
+		&i.Body,
+		&i.RetryAttempts,
+		&i.RetryDelaySeconds,
+		&i.RetryAfterHostWide,
 	)
 	return i, err
 }
 
+const updateJobBreaker = `-- name: UpdateJobBreaker :exec
+UPDATE jobs
+SET breaker_state = ?, breaker_failures = ?, breaker_opened_at = ?
+WHERE id = ?
+`
+
+type UpdateJobBreakerParams struct {
+	BreakerState    string
+	BreakerFailures int64
+	BreakerOpenedAt sql.NullTime
+	ID              int64
+}
+
+func (q *Queries) UpdateJobBreaker(ctx context.Context, arg UpdateJobBreakerParams) error {
+	_, err := q.db.ExecContext(ctx, updateJobBreaker,
+		arg.BreakerState,
+		arg.BreakerFailures,
+		arg.BreakerOpenedAt,
+		arg.ID,
+	)
+	return err
+}
+
 const updateJobNextRun = `-- name: UpdateJobNextRun :exec
 UPDATE jobs
 SET next_run_at = ?
@@ -246,16 +2540,32 @@ func (q *Queries) UpdateJobNextRun(ctx context.Context, arg UpdateJobNextRunPara
 
 const updateJobRun = `-- name: UpdateJobRun :exec
 UPDATE job_runs
-SET status = ?, response_code = ?, response_body = ?, finished_at = ?
+SET status = ?, response_code = ?, response_body = ?, finished_at = ?,
+    dns_ms = ?, connect_ms = ?, tls_ms = ?, ttfb_ms = ?, total_ms = ?,
+    error = ?, body_hash = ?, changed = ?, body_diff = ?,
+    response_headers = ?, response_encoding = ?, response_size = ?, response_truncated = ?
 WHERE id = ?
 `
 
 type UpdateJobRunParams struct {
-	Status       sql.NullString
-	ResponseCode sql.NullInt64
-	ResponseBody sql.NullString
-	FinishedAt   sql.NullTime
-	ID           int64
+	Status            sql.NullString
+	ResponseCode      sql.NullInt64
+	ResponseBody      []byte
+	FinishedAt        sql.NullTime
+	DnsMs             sql.NullInt64
+	ConnectMs         sql.NullInt64
+	TlsMs             sql.NullInt64
+	TtfbMs            sql.NullInt64
+	TotalMs           sql.NullInt64
+	Error             sql.NullString
+	BodyHash          sql.NullString
+	Changed           sql.NullBool
+	BodyDiff          sql.NullString
+	ResponseHeaders   sql.NullString
+	ResponseEncoding  sql.NullString
+	ResponseSize      sql.NullInt64
+	ResponseTruncated sql.NullBool
+	ID                int64
 }
 
 func (q *Queries) UpdateJobRun(ctx context.Context, arg UpdateJobRunParams) error {
@@ -264,7 +2574,213 @@ func (q *Queries) UpdateJobRun(ctx context.Context, arg UpdateJobRunParams) erro
 		arg.ResponseCode,
 		arg.ResponseBody,
 		arg.FinishedAt,
+		arg.DnsMs,
+		arg.ConnectMs,
+		arg.TlsMs,
+		arg.TtfbMs,
+		arg.TotalMs,
+		arg.Error,
+		arg.BodyHash,
+		arg.Changed,
+		arg.BodyDiff,
+		arg.ResponseHeaders,
+		arg.ResponseEncoding,
+		arg.ResponseSize,
+		arg.ResponseTruncated,
+		arg.ID,
+	)
+	return err
+}
+
+const updateNotificationChannel = `-- name: UpdateNotificationChannel :one
+UPDATE notification_channels
+SET name = ?, type = ?, config = ?
+WHERE id = ?
+RETURNING id, name, type, config, created_at
+`
+
+type UpdateNotificationChannelParams struct {
+	Name   string
+	Type   string
+	Config string
+	ID     int64
+}
+
+func (q *Queries) UpdateNotificationChannel(ctx context.Context, arg UpdateNotificationChannelParams) (NotificationChannel, error) {
+	row := q.db.QueryRowContext(ctx, updateNotificationChannel,
+		arg.Name,
+		arg.Type,
+		arg.Config,
+		arg.ID,
+	)
+	var i NotificationChannel
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.Config,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateWebhookDeliveryAttempt = `-- name: UpdateWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = ?, attempts = ?, next_attempt_at = ?, last_attempt_at = ?,
+    response_code = ?, error = ?
+WHERE id = ?
+`
+
+type UpdateWebhookDeliveryAttemptParams struct {
+	Status        string
+	Attempts      int64
+	NextAttemptAt sql.NullTime
+	LastAttemptAt sql.NullTime
+	ResponseCode  sql.NullInt64
+	Error         sql.NullString
+	ID            int64
+}
+
+func (q *Queries) UpdateWebhookDeliveryAttempt(ctx context.Context, arg UpdateWebhookDeliveryAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, updateWebhookDeliveryAttempt,
+		arg.Status,
+		arg.Attempts,
+		arg.NextAttemptAt,
+		arg.LastAttemptAt,
+		arg.ResponseCode,
+		arg.Error,
+		arg.ID,
+	)
+	return err
+}
+
+const updateWebhookSubscription = `-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET url = ?, secret = ?, events = ?, active = ?
+WHERE id = ?
+RETURNING id, url, secret, events, active, created_at
+`
+
+type UpdateWebhookSubscriptionParams struct {
+	Url    string
+	Secret string
+	Events string
+	Active bool
+	ID     int64
+}
+
+func (q *Queries) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRowContext(ctx, updateWebhookSubscription,
+		arg.Url,
+		arg.Secret,
+		arg.Events,
+		arg.Active,
 		arg.ID,
 	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertJobAlertState = `-- name: UpsertJobAlertState :exec
+INSERT INTO job_alert_states (job_id, state, consecutive_failures, changed_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (job_id) DO UPDATE
+SET state = excluded.state,
+    consecutive_failures = excluded.consecutive_failures,
+    changed_at = excluded.changed_at
+`
+
+type UpsertJobAlertStateParams struct {
+	JobID               int64
+	State               string
+	ConsecutiveFailures int64
+	ChangedAt           time.Time
+}
+
+func (q *Queries) UpsertJobAlertState(ctx context.Context, arg UpsertJobAlertStateParams) error {
+	_, err := q.db.ExecContext(ctx, upsertJobAlertState,
+		arg.JobID,
+		arg.State,
+		arg.ConsecutiveFailures,
+		arg.ChangedAt,
+	)
+	return err
+}
+
+const upsertJobLatencyRollup = `-- name: UpsertJobLatencyRollup :exec
+INSERT INTO job_latency_rollups (job_id, bucket_start, le_ms, runs)
+VALUES (?, ?, ?, 1)
+ON CONFLICT (job_id, bucket_start, le_ms) DO UPDATE
+SET runs = runs + 1
+`
+
+type UpsertJobLatencyRollupParams struct {
+	JobID       int64
+	BucketStart int64
+	LeMs        int64
+}
+
+func (q *Queries) UpsertJobLatencyRollup(ctx context.Context, arg UpsertJobLatencyRollupParams) error {
+	_, err := q.db.ExecContext(ctx, upsertJobLatencyRollup, arg.JobID, arg.BucketStart, arg.LeMs)
+	return err
+}
+
+const upsertJobRollup = `-- name: UpsertJobRollup :exec
+INSERT INTO job_rollups (job_id, bucket_start, status, runs, latency_sum_ms, latency_count)
+VALUES (?, ?, ?, 1, ?, ?)
+ON CONFLICT (job_id, bucket_start, status) DO UPDATE
+SET runs = runs + 1,
+    latency_sum_ms = latency_sum_ms + excluded.latency_sum_ms,
+    latency_count = latency_count + excluded.latency_count
+`
+
+type UpsertJobRollupParams struct {
+	JobID        int64
+	BucketStart  int64
+	Status       string
+	LatencySumMs int64
+	LatencyCount int64
+}
+
+func (q *Queries) UpsertJobRollup(ctx context.Context, arg UpsertJobRollupParams) error {
+	_, err := q.db.ExecContext(ctx, upsertJobRollup,
+		arg.JobID,
+		arg.BucketStart,
+		arg.Status,
+		arg.LatencySumMs,
+		arg.LatencyCount,
+	)
+	return err
+}
+
+const upsertTrackedContent = `-- name: UpsertTrackedContent :exec
+INSERT INTO job_tracked_content (job_id, body_hash, content, updated_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (job_id) DO UPDATE
+SET body_hash = excluded.body_hash, content = excluded.content, updated_at = excluded.updated_at
+`
+
+type UpsertTrackedContentParams struct {
+	JobID     int64
+	BodyHash  string
+	Content   string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpsertTrackedContent(ctx context.Context, arg UpsertTrackedContentParams) error {
+	_, err := q.db.ExecContext(ctx, upsertTrackedContent,
+		arg.JobID,
+		arg.BodyHash,
+		arg.Content,
+		arg.UpdatedAt,
+	)
 	return err
 }
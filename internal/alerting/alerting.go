@@ -0,0 +1,480 @@
+package alerting
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/scheduler"
+	"time"
+)
+
+const (
+	StateHealthy = "healthy"
+	StateFailing = "failing"
+)
+
+const (
+	IncidentOpen         = "open"
+	IncidentAcknowledged = "acknowledged"
+	IncidentResolved     = "resolved"
+)
+
+// incidentCheckInterval is how often open incidents are checked for
+// re-notification and escalation.
+const incidentCheckInterval = 30 * time.Second
+
+var (
+	ErrIncidentNotFound = errors.New("incident not found")
+	ErrIncidentNotOpen  = errors.New("incident is not open")
+)
+
+// Alerter follows run results per job and notifies the job's channels when
+// it starts failing and when it recovers. A job alerts once it has at least
+// one threshold set: alert_consecutive_failures, or alert_failure_rate (a
+// percentage of the last alert_failure_window runs).
+//
+// Every failing period is one incident. While it is open the job's channels
+// are reminded every alert_renotify_minutes, and the escalation channels are
+// added after alert_escalation_minutes. Acknowledging stops both.
+type Alerter struct {
+	db     *db.Queries
+	ticker *time.Ticker
+	done   chan bool
+}
+
+func NewAlerter(database *db.Queries) *Alerter {
+	return &Alerter{
+		db:   database,
+		done: make(chan bool),
+	}
+}
+
+func (a *Alerter) Start(ctx context.Context) {
+	slog.Info("starting alerter", "interval", incidentCheckInterval.String())
+
+	a.ticker = time.NewTicker(incidentCheckInterval)
+
+	go a.run(ctx)
+}
+
+func (a *Alerter) Stop() {
+	if a.ticker == nil {
+		return
+	}
+
+	slog.Info("stopping alerter")
+	a.ticker.Stop()
+	a.done <- true
+}
+
+func (a *Alerter) run(ctx context.Context) {
+	for {
+		select {
+		case <-a.done:
+			slog.Info("alerter stopped")
+			return
+		case <-a.ticker.C:
+			a.checkIncidents(ctx, time.Now().UTC())
+		}
+	}
+}
+
+// RunFinished implements scheduler.RunObserver.
+func (a *Alerter) RunFinished(ctx context.Context, job db.Job, run scheduler.FinishedRun) {
+	if !alertingEnabled(job) {
+		return
+	}
+
+	logger := slog.With("job_id", job.ID, "run_id", run.ID)
+
+	state, err := a.db.GetJobAlertState(ctx, job.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		state = db.JobAlertState{JobID: job.ID, State: StateHealthy, ChangedAt: run.FinishedAt}
+	} else if err != nil {
+		logger.ErrorContext(ctx, "failed to load alert state", "error", err)
+		return
+	}
+
+	failed := run.Status == "failed"
+	if failed {
+		state.ConsecutiveFailures++
+	} else {
+		state.ConsecutiveFailures = 0
+	}
+
+	var event, reason string
+	switch {
+	case failed && state.State == StateHealthy:
+		reason, err = a.failureReason(ctx, job, state.ConsecutiveFailures)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to evaluate alert thresholds", "error", err)
+			return
+		}
+		if reason != "" {
+			event = EventFailing
+		}
+
+	case !failed && state.State == StateFailing:
+		// with a rate threshold a single success is not enough; the rate
+		// has to drop back under it as well
+		reason, err = a.failureReason(ctx, job, 0)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to evaluate alert thresholds", "error", err)
+			return
+		}
+		if reason == "" {
+			event = EventRecovered
+		}
+	}
+
+	if event != "" {
+		state.State = StateFailing
+		if event == EventRecovered {
+			state.State = StateHealthy
+		}
+		state.ChangedAt = run.FinishedAt
+	}
+
+	err = a.db.UpsertJobAlertState(ctx, db.UpsertJobAlertStateParams{
+		JobID:               job.ID,
+		State:               state.State,
+		ConsecutiveFailures: state.ConsecutiveFailures,
+		ChangedAt:           state.ChangedAt,
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to store alert state", "error", err)
+		return
+	}
+
+	switch event {
+	case EventFailing:
+		a.openIncident(ctx, job, run, reason, state.ConsecutiveFailures)
+	case EventRecovered:
+		a.resolveIncident(ctx, job, run)
+	default:
+		if failed && state.State == StateFailing {
+			a.recordIncidentFailure(ctx, job, run, state.ConsecutiveFailures)
+		}
+	}
+}
+
+// failureReason returns why the job counts as failing, or "" when no
+// threshold is crossed.
+func (a *Alerter) failureReason(ctx context.Context, job db.Job, consecutiveFailures int64) (string, error) {
+	if job.AlertConsecutiveFailures.Valid && consecutiveFailures >= job.AlertConsecutiveFailures.Int64 {
+		return fmt.Sprintf("%d consecutive failures", consecutiveFailures), nil
+	}
+
+	if !job.AlertFailureRate.Valid {
+		return "", nil
+	}
+
+	recent, err := a.db.CountRecentJobRunFailures(ctx, db.CountRecentJobRunFailuresParams{
+		JobID: job.ID,
+		Limit: job.AlertFailureWindow,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// wait for a full window so the first failure of a new job does not
+	// count as a 100% failure rate
+	if recent.Runs < job.AlertFailureWindow {
+		return "", nil
+	}
+
+	rate := recent.Failures * 100 / recent.Runs
+	if rate >= job.AlertFailureRate.Int64 {
+		return fmt.Sprintf("%d%% of the last %d runs failed", rate, recent.Runs), nil
+	}
+
+	return "", nil
+}
+
+// openIncident opens an incident for a job that just started failing, or
+// reuses the one still active, and notifies the job's channels.
+func (a *Alerter) openIncident(ctx context.Context, job db.Job, run scheduler.FinishedRun, reason string, consecutiveFailures int64) {
+	incident, err := a.db.GetActiveIncidentForJob(ctx, job.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		incident, err = a.db.CreateIncident(ctx, db.CreateIncidentParams{
+			JobID:               job.ID,
+			Reason:              reason,
+			LastRunID:           sql.NullInt64{Int64: run.ID, Valid: true},
+			LastError:           sql.NullString{String: run.Error, Valid: run.Error != ""},
+			ConsecutiveFailures: consecutiveFailures,
+			OpenedAt:            run.FinishedAt,
+			LastNotifiedAt:      run.FinishedAt,
+		})
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to open incident", "job_id", job.ID, "error", err)
+		return
+	}
+
+	slog.InfoContext(ctx, "incident opened", "job_id", job.ID, "incident_id", incident.ID, "reason", reason)
+
+	a.notify(ctx, job, recipients(job, incident), newNotification(EventFailing, job, incident))
+}
+
+func (a *Alerter) resolveIncident(ctx context.Context, job db.Job, run scheduler.FinishedRun) {
+	active, err := a.db.GetActiveIncidentForJob(ctx, job.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load incident", "job_id", job.ID, "error", err)
+		return
+	}
+
+	incident, err := a.db.ResolveIncident(ctx, db.ResolveIncidentParams{
+		ResolvedAt: sql.NullTime{Time: run.FinishedAt, Valid: true},
+		ID:         active.ID,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to resolve incident", "job_id", job.ID, "incident_id", active.ID, "error", err)
+		return
+	}
+
+	slog.InfoContext(ctx, "incident resolved", "job_id", job.ID, "incident_id", incident.ID)
+
+	notification := newNotification(EventRecovered, job, incident)
+	notification.RunID = run.ID
+	notification.Status = run.Status
+	notification.Error = ""
+	notification.Reason = ""
+	notification.ConsecutiveFailures = 0
+	notification.Time = run.FinishedAt
+	a.notify(ctx, job, recipients(job, incident), notification)
+}
+
+// recordIncidentFailure keeps the active incident's last error current so
+// reminders describe the latest failure.
+func (a *Alerter) recordIncidentFailure(ctx context.Context, job db.Job, run scheduler.FinishedRun, consecutiveFailures int64) {
+	active, err := a.db.GetActiveIncidentForJob(ctx, job.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err == nil {
+		err = a.db.UpdateIncidentFailure(ctx, db.UpdateIncidentFailureParams{
+			LastRunID:           sql.NullInt64{Int64: run.ID, Valid: true},
+			LastError:           sql.NullString{String: run.Error, Valid: run.Error != ""},
+			ConsecutiveFailures: consecutiveFailures,
+			ID:                  active.ID,
+		})
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update incident", "job_id", job.ID, "error", err)
+	}
+}
+
+// checkIncidents escalates and re-notifies open incidents that are due.
+func (a *Alerter) checkIncidents(ctx context.Context, now time.Time) {
+	incidents, err := a.db.GetOpenIncidents(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to fetch open incidents", "error", err)
+		return
+	}
+
+	for _, incident := range incidents {
+		job, err := a.db.GetJobByID(ctx, incident.JobID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && !alertingEnabled(job)) {
+			// the job was deleted or stopped alerting, nobody can
+			// recover it any more
+			a.db.ResolveIncident(ctx, db.ResolveIncidentParams{
+				ResolvedAt: sql.NullTime{Time: now, Valid: true},
+				ID:         incident.ID,
+			})
+			a.db.UpsertJobAlertState(ctx, db.UpsertJobAlertStateParams{
+				JobID:     incident.JobID,
+				State:     StateHealthy,
+				ChangedAt: now,
+			})
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to load job", "job_id", incident.JobID, "error", err)
+			continue
+		}
+
+		if !job.Active.Bool {
+			continue
+		}
+
+		escalationChannels := decodeIDs(job.AlertEscalationChannels)
+		if !incident.EscalatedAt.Valid && job.AlertEscalationMinutes.Valid && len(escalationChannels) > 0 &&
+			!now.Before(incident.OpenedAt.Add(time.Duration(job.AlertEscalationMinutes.Int64)*time.Minute)) {
+			escalated, err := a.db.MarkIncidentEscalated(ctx, db.MarkIncidentEscalatedParams{
+				EscalatedAt:    sql.NullTime{Time: now, Valid: true},
+				LastNotifiedAt: now,
+				ID:             incident.ID,
+			})
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to escalate incident", "incident_id", incident.ID, "error", err)
+				continue
+			}
+			if escalated == 1 {
+				slog.InfoContext(ctx, "incident escalated", "job_id", job.ID, "incident_id", incident.ID)
+				notification := newNotification(EventEscalated, job, incident)
+				notification.Time = now
+				a.notify(ctx, job, escalationChannels, notification)
+			}
+			continue
+		}
+
+		if job.AlertRenotifyMinutes.Valid &&
+			!now.Before(incident.LastNotifiedAt.Add(time.Duration(job.AlertRenotifyMinutes.Int64)*time.Minute)) {
+			notified, err := a.db.MarkIncidentNotified(ctx, db.MarkIncidentNotifiedParams{
+				LastNotifiedAt: now,
+				ID:             incident.ID,
+			})
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to update incident", "incident_id", incident.ID, "error", err)
+				continue
+			}
+			if notified == 1 {
+				notification := newNotification(EventStillFailing, job, incident)
+				notification.Time = now
+				a.notify(ctx, job, recipients(job, incident), notification)
+			}
+		}
+	}
+}
+
+// Acknowledge marks an open incident as handled by someone, which stops
+// reminders and escalation, and tells the channels that were alerted.
+func (a *Alerter) Acknowledge(ctx context.Context, incidentID int64, by string) (db.Incident, error) {
+	current, err := a.db.GetIncident(ctx, incidentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Incident{}, ErrIncidentNotFound
+	}
+	if err != nil {
+		return db.Incident{}, err
+	}
+	if current.State != IncidentOpen {
+		return current, ErrIncidentNotOpen
+	}
+
+	incident, err := a.db.AcknowledgeIncident(ctx, db.AcknowledgeIncidentParams{
+		AcknowledgedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		AcknowledgedBy: sql.NullString{String: by, Valid: by != ""},
+		ID:             incidentID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// resolved or acknowledged since we looked
+		return current, ErrIncidentNotOpen
+	}
+	if err != nil {
+		return db.Incident{}, err
+	}
+
+	job, err := a.db.GetJobByID(ctx, incident.JobID)
+	if err == nil {
+		notification := newNotification(EventAcknowledged, job, incident)
+		notification.Time = incident.AcknowledgedAt.Time
+		if by != "" {
+			notification.Reason = "acknowledged by " + by
+		}
+		a.notify(ctx, job, recipients(job, incident), notification)
+	}
+
+	return incident, nil
+}
+
+// recipients are the job's channels, plus its escalation channels once the
+// incident has been escalated.
+func recipients(job db.Job, incident db.Incident) []int64 {
+	channelIDs := decodeIDs(job.AlertChannels)
+	if incident.EscalatedAt.Valid {
+		channelIDs = append(channelIDs, decodeIDs(job.AlertEscalationChannels)...)
+	}
+	return channelIDs
+}
+
+func newNotification(event string, job db.Job, incident db.Incident) Notification {
+	return Notification{
+		Event:               event,
+		IncidentID:          incident.ID,
+		JobID:               job.ID,
+		JobName:             job.Name,
+		Target:              job.Url,
+		RunID:               incident.LastRunID.Int64,
+		Status:              "failed",
+		Error:               incident.LastError.String,
+		Reason:              incident.Reason,
+		ConsecutiveFailures: incident.ConsecutiveFailures,
+		Time:                incident.OpenedAt,
+	}
+}
+
+// notify delivers in the background: deliveries must not hold up the run,
+// nor be cut short when it ends.
+func (a *Alerter) notify(ctx context.Context, job db.Job, channelIDs []int64, notification Notification) {
+	if len(channelIDs) == 0 {
+		return
+	}
+
+	go a.deliver(context.WithoutCancel(ctx), job, dedupeIDs(channelIDs), notification)
+}
+
+func (a *Alerter) deliver(ctx context.Context, job db.Job, channelIDs []int64, notification Notification) {
+	channels, err := a.db.GetNotificationChannelsByIDs(ctx, channelIDs)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load notification channels", "job_id", job.ID, "error", err)
+		return
+	}
+
+	for _, channel := range channels {
+		if err := Send(ctx, channel, notification); err != nil {
+			slog.WarnContext(ctx, "failed to send notification",
+				"job_id", job.ID, "channel_id", channel.ID, "event", notification.Event, "error", err)
+			continue
+		}
+		slog.InfoContext(ctx, "notification sent", "job_id", job.ID, "channel_id", channel.ID, "event", notification.Event)
+	}
+}
+
+// Send delivers notification to a stored channel.
+func Send(ctx context.Context, channel db.NotificationChannel, notification Notification) error {
+	var config ChannelConfig
+	if err := json.Unmarshal([]byte(channel.Config), &config); err != nil {
+		return fmt.Errorf("invalid channel config: %w", err)
+	}
+
+	notifier, err := NewNotifier(channel.Type, config)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	return notifier.Notify(ctx, notification)
+}
+
+func alertingEnabled(job db.Job) bool {
+	return job.AlertConsecutiveFailures.Valid || job.AlertFailureRate.Valid
+}
+
+func decodeIDs(encoded sql.NullString) []int64 {
+	var ids []int64
+	if encoded.Valid {
+		json.Unmarshal([]byte(encoded.String), &ids)
+	}
+	return ids
+}
+
+func dedupeIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := ids[:0:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,157 @@
+package alerting
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSession is what the fake SMTP server received in one session.
+type smtpSession struct {
+	from       string
+	recipients []string
+	data       string
+	err        error
+}
+
+// fakeSMTP accepts a single SMTP session on localhost, offering the given
+// extensions in its EHLO answer, and reports what the client sent.
+func fakeSMTP(t *testing.T, extensions ...string) (host string, port int, sessions <-chan smtpSession) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	done := make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			done <- smtpSession{err: err}
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		done <- serveSMTP(textproto.NewConn(conn), extensions)
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, done
+}
+
+func serveSMTP(conn *textproto.Conn, extensions []string) smtpSession {
+	var session smtpSession
+
+	conn.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			session.err = err
+			return session
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			lines := append([]string{"fake"}, extensions...)
+			for i, ext := range lines {
+				separator := "-"
+				if i == len(lines)-1 {
+					separator = " "
+				}
+				conn.PrintfLine("250%s%s", separator, ext)
+			}
+		case "MAIL":
+			session.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			conn.PrintfLine("250 OK")
+		case "RCPT":
+			session.recipients = append(session.recipients, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			conn.PrintfLine("250 OK")
+		case "DATA":
+			conn.PrintfLine("354 go ahead")
+			data, err := conn.ReadDotBytes()
+			if err != nil {
+				session.err = err
+				return session
+			}
+			session.data = string(data)
+			conn.PrintfLine("250 queued")
+		case "QUIT":
+			conn.PrintfLine("221 bye")
+			return session
+		default:
+			conn.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestEmailNotifierSendsMail(t *testing.T) {
+	host, port, sessions := fakeSMTP(t)
+
+	notifier, err := NewNotifier(ChannelTypeEmail, ChannelConfig{
+		Host: host,
+		Port: port,
+		From: "pulse@example.com",
+		To:   []string{"oncall@example.com", "ops@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("NewNotifier: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := notifier.Notify(ctx, testNotification()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	session := <-sessions
+	if session.err != nil {
+		t.Fatalf("fake server: %v", session.err)
+	}
+	if session.from != "pulse@example.com" {
+		t.Errorf("MAIL FROM = %q", session.from)
+	}
+	if strings.Join(session.recipients, ",") != "oncall@example.com,ops@example.com" {
+		t.Errorf("RCPT TO = %v", session.recipients)
+	}
+	for _, want := range []string{
+		"Subject: [Pulse] checkout is failing\n",
+		"To: oncall@example.com, ops@example.com\n",
+		"Content-Type: text/plain; charset=UTF-8\n",
+		"Target: https://shop.example.com/health\n",
+		"Incident: 7\n",
+	} {
+		if !strings.Contains(session.data, want) {
+			t.Errorf("message lacks %q:\n%s", want, session.data)
+		}
+	}
+}
+
+func TestEmailNotifierRequiresAuthSupport(t *testing.T) {
+	host, port, _ := fakeSMTP(t)
+
+	notifier, err := NewNotifier(ChannelTypeEmail, ChannelConfig{
+		Host:     host,
+		Port:     port,
+		Username: "pulse",
+		Password: "secret",
+		From:     "pulse@example.com",
+		To:       []string{"oncall@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("NewNotifier: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = notifier.Notify(ctx, testNotification())
+	if err == nil || !strings.Contains(err.Error(), "AUTH") {
+		t.Errorf("Notify error = %v, want a missing AUTH error", err)
+	}
+}
@@ -0,0 +1,147 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"lucasbonna/pulse/internal/telemetry"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"time"
+)
+
+const (
+	ChannelTypeWebhook = "webhook"
+	ChannelTypeSlack   = "slack"
+	ChannelTypeEmail   = "email"
+)
+
+const (
+	EventFailing      = "job.failing"
+	EventStillFailing = "job.still_failing"
+	EventEscalated    = "job.escalated"
+	EventAcknowledged = "job.acknowledged"
+	EventRecovered    = "job.recovered"
+	EventTest         = "test"
+)
+
+// notifyTimeout bounds a single delivery to one channel.
+const notifyTimeout = 15 * time.Second
+
+var httpClient = &http.Client{
+	Timeout:   notifyTimeout,
+	Transport: telemetry.Transport(http.DefaultTransport),
+}
+
+// Notification is what every channel receives, as JSON for webhooks or
+// rendered as text for Slack and email.
+type Notification struct {
+	Event               string    `json:"event"`
+	IncidentID          int64     `json:"incident_id,omitempty"`
+	JobID               int64     `json:"job_id"`
+	JobName             string    `json:"job_name"`
+	Target              string    `json:"target"`
+	RunID               int64     `json:"run_id,omitempty"`
+	Status              string    `json:"status,omitempty"`
+	Error               string    `json:"error,omitempty"`
+	Reason              string    `json:"reason,omitempty"`
+	ConsecutiveFailures int64     `json:"consecutive_failures"`
+	Time                time.Time `json:"time"`
+}
+
+// Title is a one-line summary used as Slack text and email subject.
+func (n Notification) Title() string {
+	switch n.Event {
+	case EventFailing:
+		return fmt.Sprintf("%s is failing", n.JobName)
+	case EventStillFailing:
+		return fmt.Sprintf("%s is still failing", n.JobName)
+	case EventEscalated:
+		return fmt.Sprintf("%s is still failing (escalated)", n.JobName)
+	case EventAcknowledged:
+		return fmt.Sprintf("%s: incident acknowledged", n.JobName)
+	case EventRecovered:
+		return fmt.Sprintf("%s recovered", n.JobName)
+	default:
+		return "Pulse test notification"
+	}
+}
+
+// Text renders the notification for humans.
+func (n Notification) Text() string {
+	text := n.Title()
+	if n.Target != "" {
+		text += "\nTarget: " + n.Target
+	}
+	if n.Reason != "" {
+		text += "\nReason: " + n.Reason
+	}
+	if n.Error != "" {
+		text += "\nLast error: " + n.Error
+	}
+	if n.IncidentID != 0 {
+		text += fmt.Sprintf("\nIncident: %d", n.IncidentID)
+	}
+	if n.RunID != 0 {
+		text += fmt.Sprintf("\nRun: %d", n.RunID)
+	}
+	return text + "\nTime: " + n.Time.UTC().Format(time.RFC3339)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// ChannelConfig holds the settings of every channel type; each type only
+// reads its own fields.
+type ChannelConfig struct {
+	// webhook and slack
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// email
+	Host     string   `json:"host,omitempty"`
+	Port     int      `json:"port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+}
+
+// NewNotifier validates config for channelType and returns its notifier.
+func NewNotifier(channelType string, config ChannelConfig) (Notifier, error) {
+	switch channelType {
+	case ChannelTypeWebhook, ChannelTypeSlack:
+		parsed, err := url.Parse(config.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("%s channels need an http(s) url", channelType)
+		}
+		if channelType == ChannelTypeSlack {
+			return &slackNotifier{url: config.URL}, nil
+		}
+		return &webhookNotifier{url: config.URL, headers: config.Headers}, nil
+
+	case ChannelTypeEmail:
+		if config.Host == "" {
+			return nil, fmt.Errorf("email channels need an smtp host")
+		}
+		if config.Port == 0 {
+			config.Port = 587
+		}
+		if _, err := mail.ParseAddress(config.From); err != nil {
+			return nil, fmt.Errorf("invalid from address %q", config.From)
+		}
+		if len(config.To) == 0 {
+			return nil, fmt.Errorf("email channels need at least one recipient")
+		}
+		for _, to := range config.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return nil, fmt.Errorf("invalid recipient %q", to)
+			}
+		}
+		return &emailNotifier{config: config}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown channel type %q", channelType)
+	}
+}
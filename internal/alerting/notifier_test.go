@@ -0,0 +1,49 @@
+package alerting
+
+import "testing"
+
+func TestNewNotifierValidatesConfig(t *testing.T) {
+	email := ChannelConfig{Host: "smtp.example.com", From: "pulse@example.com", To: []string{"oncall@example.com"}}
+
+	tests := []struct {
+		name        string
+		channelType string
+		config      ChannelConfig
+		wantErr     bool
+	}{
+		{"webhook", ChannelTypeWebhook, ChannelConfig{URL: "https://hooks.example.com/pulse"}, false},
+		{"webhook without url", ChannelTypeWebhook, ChannelConfig{}, true},
+		{"webhook with ftp url", ChannelTypeWebhook, ChannelConfig{URL: "ftp://example.com/"}, true},
+		{"slack without host", ChannelTypeSlack, ChannelConfig{URL: "https://"}, true},
+		{"email", ChannelTypeEmail, email, false},
+		{"email without host", ChannelTypeEmail, ChannelConfig{From: email.From, To: email.To}, true},
+		{"email with bad sender", ChannelTypeEmail, ChannelConfig{Host: email.Host, From: "pulse", To: email.To}, true},
+		{"email without recipients", ChannelTypeEmail, ChannelConfig{Host: email.Host, From: email.From}, true},
+		{"email with bad recipient", ChannelTypeEmail, ChannelConfig{Host: email.Host, From: email.From, To: []string{"x"}}, true},
+		{"unknown type", "pager", ChannelConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNotifier(tt.channelType, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewNotifier error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewNotifierDefaultsEmailPort(t *testing.T) {
+	notifier, err := NewNotifier(ChannelTypeEmail, ChannelConfig{
+		Host: "smtp.example.com",
+		From: "pulse@example.com",
+		To:   []string{"oncall@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("NewNotifier: %v", err)
+	}
+
+	if port := notifier.(*emailNotifier).config.Port; port != 587 {
+		t.Errorf("port = %d, want 587", port)
+	}
+}
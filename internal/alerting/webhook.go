@@ -0,0 +1,76 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// webhookNotifier POSTs the notification as JSON.
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.url, n.headers, payload)
+}
+
+// slackNotifier posts to a Slack incoming webhook. Mattermost, Rocket.Chat
+// and Discord's /slack endpoints accept the same payload.
+type slackNotifier struct {
+	url string
+}
+
+func (n *slackNotifier) Notify(ctx context.Context, notification Notification) error {
+	icon := ":information_source:"
+	switch notification.Event {
+	case EventFailing, EventStillFailing, EventEscalated:
+		icon = ":red_circle:"
+	case EventAcknowledged:
+		icon = ":eyes:"
+	case EventRecovered:
+		icon = ":large_green_circle:"
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"text": icon + " " + notification.Text(),
+	})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.url, nil, payload)
+}
+
+func postJSON(ctx context.Context, url string, headers map[string]string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Pulse-Alerting")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook answered %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return nil
+}
@@ -0,0 +1,149 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capturedRequest is what a stand-in endpoint saw of one request.
+type capturedRequest struct {
+	method string
+	header http.Header
+	body   []byte
+}
+
+// newStandIn starts an endpoint answering status and sends every request it
+// receives on the returned channel.
+func newStandIn(t *testing.T, status int) (*httptest.Server, <-chan capturedRequest) {
+	t.Helper()
+
+	requests := make(chan capturedRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- capturedRequest{method: r.Method, header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+		io.WriteString(w, "stand-in answer\n")
+	}))
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func testNotification() Notification {
+	return Notification{
+		Event:               EventFailing,
+		IncidentID:          7,
+		JobID:               3,
+		JobName:             "checkout",
+		Target:              "https://shop.example.com/health",
+		RunID:               42,
+		Status:              "failed",
+		Error:               "unexpected status code 503",
+		Reason:              "3 consecutive failures",
+		ConsecutiveFailures: 3,
+		Time:                time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestWebhookNotifierPostsNotification(t *testing.T) {
+	server, requests := newStandIn(t, http.StatusNoContent)
+
+	notifier, err := NewNotifier(ChannelTypeWebhook, ChannelConfig{
+		URL:     server.URL + "/hook",
+		Headers: map[string]string{"X-Api-Key": "secret"},
+	})
+	if err != nil {
+		t.Fatalf("NewNotifier: %v", err)
+	}
+
+	notification := testNotification()
+	if err := notifier.Notify(context.Background(), notification); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	request := <-requests
+	if request.method != http.MethodPost {
+		t.Errorf("method = %s, want POST", request.method)
+	}
+	if got := request.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if got := request.header.Get("X-Api-Key"); got != "secret" {
+		t.Errorf("X-Api-Key = %q, want the configured header", got)
+	}
+
+	var received Notification
+	if err := json.Unmarshal(request.body, &received); err != nil {
+		t.Fatalf("body is not a notification: %v", err)
+	}
+	if received != notification {
+		t.Errorf("received %+v, want %+v", received, notification)
+	}
+}
+
+func TestWebhookNotifierReportsErrorAnswers(t *testing.T) {
+	server, _ := newStandIn(t, http.StatusBadGateway)
+
+	notifier, err := NewNotifier(ChannelTypeWebhook, ChannelConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewNotifier: %v", err)
+	}
+
+	err = notifier.Notify(context.Background(), testNotification())
+	if err == nil {
+		t.Fatal("Notify succeeded against a 502 answer")
+	}
+	if !strings.Contains(err.Error(), "502") || !strings.Contains(err.Error(), "stand-in answer") {
+		t.Errorf("error %q should carry the status and the answer", err)
+	}
+}
+
+func TestSlackNotifierPostsText(t *testing.T) {
+	tests := []struct {
+		event string
+		icon  string
+		title string
+	}{
+		{EventFailing, ":red_circle:", "checkout is failing"},
+		{EventStillFailing, ":red_circle:", "checkout is still failing"},
+		{EventEscalated, ":red_circle:", "checkout is still failing (escalated)"},
+		{EventAcknowledged, ":eyes:", "checkout: incident acknowledged"},
+		{EventRecovered, ":large_green_circle:", "checkout recovered"},
+		{EventTest, ":information_source:", "Pulse test notification"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			server, requests := newStandIn(t, http.StatusOK)
+
+			notifier, err := NewNotifier(ChannelTypeSlack, ChannelConfig{URL: server.URL})
+			if err != nil {
+				t.Fatalf("NewNotifier: %v", err)
+			}
+
+			notification := testNotification()
+			notification.Event = tt.event
+			if err := notifier.Notify(context.Background(), notification); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			var payload map[string]string
+			if err := json.Unmarshal((<-requests).body, &payload); err != nil {
+				t.Fatalf("body is not JSON: %v", err)
+			}
+			want := tt.icon + " " + tt.title + "\n"
+			if !strings.HasPrefix(payload["text"], want) {
+				t.Errorf("text = %q, want it to start with %q", payload["text"], want)
+			}
+			if !strings.Contains(payload["text"], "Last error: unexpected status code 503") {
+				t.Errorf("text = %q, want the last error", payload["text"])
+			}
+		})
+	}
+}
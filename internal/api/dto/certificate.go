@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+type CertificateExpiryResponse struct {
+	JobId         int64     `json:"job_id"`
+	JobName       string    `json:"job_name"`
+	JobRunId      int64     `json:"job_run_id"`
+	Host          string    `json:"host"`
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	NotAfter      time.Time `json:"not_after"`
+	DaysRemaining int64     `json:"days_remaining"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+type CreateChannelRequest struct {
+	Name   string        `json:"name" validate:"required,min=1,max=100"`
+	Type   string        `json:"type" validate:"required,oneof=webhook slack email"`
+	Config ChannelConfig `json:"config"`
+}
+
+type UpdateChannelRequest struct {
+	Name string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	// Config replaces the stored settings when present. A password of
+	// "********", as returned by the API, keeps the stored one.
+	Config *ChannelConfig `json:"config,omitempty"`
+}
+
+// ChannelConfig mirrors alerting.ChannelConfig. webhook and slack channels
+// use url (webhook also headers); email channels use the SMTP fields.
+type ChannelConfig struct {
+	URL     string            `json:"url,omitempty" validate:"max=2048"`
+	Headers map[string]string `json:"headers,omitempty" validate:"max=20"`
+
+	Host     string   `json:"host,omitempty" validate:"max=255"`
+	Port     int      `json:"port,omitempty" validate:"omitempty,min=1,max=65535"`
+	Username string   `json:"username,omitempty" validate:"max=255"`
+	Password string   `json:"password,omitempty" validate:"max=255"`
+	From     string   `json:"from,omitempty" validate:"max=255"`
+	To       []string `json:"to,omitempty" validate:"max=20,dive,max=255"`
+}
+
+type ChannelResponse struct {
+	Id        int64         `json:"id"`
+	Name      string        `json:"name"`
+	Type      string        `json:"type"`
+	Config    ChannelConfig `json:"config"`
+	CreatedAt time.Time     `json:"created_at"`
+}
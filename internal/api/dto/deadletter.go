@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type DeadLetterResponse struct {
+	Id       int64 `json:"id"`
+	JobId    int64 `json:"job_id"`
+	JobRunId int64 `json:"job_run_id"`
+	// Status is pending until the dead letter is replayed.
+	Status       string              `json:"status"`
+	Method       string              `json:"method"`
+	URL          string              `json:"url"`
+	Headers      map[string][]string `json:"headers"`
+	Body         *string             `json:"body"`
+	Attempts     int64               `json:"attempts"`
+	ResponseCode *int64              `json:"response_code"`
+	Error        string              `json:"error"`
+	CreatedAt    time.Time           `json:"created_at"`
+	// ReplayRunId is the run that last replayed the request.
+	ReplayRunId *int64     `json:"replay_run_id"`
+	ReplayedAt  *time.Time `json:"replayed_at"`
+}
+
+// ReplayDeadLettersRequest picks dead letters by ID, or all pending ones of
+// a job.
+type ReplayDeadLettersRequest struct {
+	IDs   []int64 `json:"ids,omitempty" validate:"omitempty,max=100,dive,min=1"`
+	JobId int64   `json:"job_id,omitempty" validate:"omitempty,min=1"`
+}
+
+type ReplayDeadLetterResponse struct {
+	Id     int64  `json:"id"`
+	JobId  int64  `json:"job_id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponse struct {
+	Status       string         `json:"status"`
+	ShuttingDown bool           `json:"shutting_down"`
+	Database     ReadinessCheck `json:"database"`
+	Scheduler    ReadinessCheck `json:"scheduler"`
+}
+
+type ReadinessCheck struct {
+	Status     string     `json:"status"`
+	Error      *string    `json:"error,omitempty"`
+	LastTickAt *time.Time `json:"last_tick_at,omitempty"`
+}
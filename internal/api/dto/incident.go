@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type IncidentResponse struct {
+	Id                  int64      `json:"id"`
+	JobId               int64      `json:"job_id"`
+	State               string     `json:"state"`
+	Reason              string     `json:"reason"`
+	LastRunId           *int64     `json:"last_run_id"`
+	LastError           *string    `json:"last_error"`
+	ConsecutiveFailures int64      `json:"consecutive_failures"`
+	OpenedAt            time.Time  `json:"opened_at"`
+	AcknowledgedAt      *time.Time `json:"acknowledged_at"`
+	AcknowledgedBy      *string    `json:"acknowledged_by"`
+	EscalatedAt         *time.Time `json:"escalated_at"`
+	ResolvedAt          *time.Time `json:"resolved_at"`
+	LastNotifiedAt      time.Time  `json:"last_notified_at"`
+	Notifications       int64      `json:"notifications"`
+}
+
+type AcknowledgeIncidentRequest struct {
+	By string `json:"by,omitempty" validate:"max=100"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type JanitorStatusResponse struct {
+	Enabled          bool       `json:"enabled"`
+	LastRunAt        *time.Time `json:"last_run_at"`
+	LastDurationMs   int64      `json:"last_duration_ms"`
+	LastDeletedRuns  int64      `json:"last_deleted_runs"`
+	TotalDeletedRuns int64      `json:"total_deleted_runs"`
+	LastVacuumAt     *time.Time `json:"last_vacuum_at"`
+	LastError        *string    `json:"last_error"`
+
+	KeepRuns         int64  `json:"keep_runs"`
+	KeepDays         int64  `json:"keep_days"`
+	KeepFailuresDays int64  `json:"keep_failures_days"`
+	Interval         string `json:"interval"`
+	VacuumInterval   string `json:"vacuum_interval"`
+}
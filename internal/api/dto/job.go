@@ -4,31 +4,268 @@ import "time"
 
 type CreateJobRequest struct {
 	Name            string `json:"name" validate:"required,min=1,max=100"`
-	URL             string `json:"url" validate:"required,url"`
-	Method          string `json:"method" validate:"required,oneof=GET POST PUT PATCH DELETE"`
+	Type            string `json:"type,omitempty" validate:"omitempty,oneof=http tcp dns grpc_health steps websocket sse heartbeat"`
+	URL             string `json:"url,omitempty" validate:"max=2048"`
+	Method          string `json:"method,omitempty" validate:"omitempty,oneof=GET POST PUT PATCH DELETE"`
 	Headers         string `json:"headers,omitempty" validate:"max=1000"`
 	IntervalSeconds int64  `json:"interval_seconds" validate:"required,min=1,max=86400"`
 	Active          bool   `json:"active,omitempty"`
+	TimeoutSeconds  *int64 `json:"timeout_seconds,omitempty" validate:"omitempty,min=1,max=300"`
+	// Body is sent with http requests; like the url and headers it may
+	// reference trigger variables as {{name}}.
+	Body string `json:"body,omitempty" validate:"max=65536"`
+	// RetryAttempts repeats failed http requests, waiting RetryDelaySeconds
+	// (default 1) and twice as long before each further attempt. Requests
+	// that still fail are kept as dead letters.
+	RetryAttempts     *int64 `json:"retry_attempts,omitempty" validate:"omitempty,min=0,max=10"`
+	RetryDelaySeconds *int64 `json:"retry_delay_seconds,omitempty" validate:"omitempty,min=1,max=60"`
+	// RetryAfterHostWide applies a Retry-After received by this job to
+	// every job on the same host.
+	RetryAfterHostWide bool `json:"retry_after_host_wide,omitempty"`
+	// Tags label jobs for filtering, for example in the event stream.
+	Tags []string `json:"tags,omitempty" validate:"max=20,dive,min=1,max=50"`
+	// HeartbeatGraceSeconds is how late a heartbeat job's ping may be
+	// beyond interval_seconds before the job fails.
+	HeartbeatGraceSeconds *int64 `json:"heartbeat_grace_seconds,omitempty" validate:"omitempty,min=0,max=86400"`
+	// TriggerEnabled gives the job a secret /trigger/{token} URL.
+	// TriggerSecret additionally requires an HMAC-SHA256 signature of the
+	// body; TriggerRateLimit caps triggers per minute (default 10).
+	TriggerEnabled   bool   `json:"trigger_enabled,omitempty"`
+	TriggerSecret    string `json:"trigger_secret,omitempty" validate:"omitempty,min=16,max=256"`
+	TriggerRateLimit *int64 `json:"trigger_rate_limit,omitempty" validate:"omitempty,min=1,max=600"`
+	// BreakerFailureThreshold opens the job's circuit breaker after that many
+	// consecutive failures; it then only runs a probe every
+	// BreakerProbeSeconds (default 300) until one succeeds.
+	BreakerFailureThreshold *int64 `json:"breaker_failure_threshold,omitempty" validate:"omitempty,min=1,max=1000"`
+	BreakerProbeSeconds     *int64 `json:"breaker_probe_seconds,omitempty" validate:"omitempty,min=1,max=604800"`
+	// FailOnErrorStatus fails http runs answered with a 4xx or 5xx status;
+	// without it any answer succeeds.
+	FailOnErrorStatus bool `json:"fail_on_error_status,omitempty"`
+
+	DNSRecordType string `json:"dns_record_type,omitempty" validate:"omitempty,oneof=A AAAA CNAME MX TXT NS"`
+	DNSExpected   string `json:"dns_expected,omitempty" validate:"max=500"`
+	DNSServer     string `json:"dns_server,omitempty" validate:"max=255"`
+	GRPCService   string `json:"grpc_service,omitempty" validate:"max=255"`
+	GRPCTLS       bool   `json:"grpc_tls,omitempty"`
+	Steps         []Step `json:"steps,omitempty" validate:"max=50,dive"`
+	SendMessage   string `json:"send_message,omitempty" validate:"max=65536"`
+	ExpectMessage string `json:"expect_message,omitempty" validate:"max=1000"`
+
+	TrackChanges  bool   `json:"track_changes,omitempty"`
+	TrackJSONPath string `json:"track_json_path,omitempty" validate:"max=500"`
+
+	MaxBodyBytes  *int64   `json:"max_body_bytes,omitempty" validate:"omitempty,min=0,max=1048576"`
+	RedactHeaders []string `json:"redact_headers,omitempty" validate:"max=50,dive,min=1,max=100"`
+
+	RetentionKeepRuns         *int64 `json:"retention_keep_runs,omitempty" validate:"omitempty,min=0,max=1000000"`
+	RetentionKeepDays         *int64 `json:"retention_keep_days,omitempty" validate:"omitempty,min=0,max=3650"`
+	RetentionKeepFailuresDays *int64 `json:"retention_keep_failures_days,omitempty" validate:"omitempty,min=0,max=3650"`
+
+	AlertChannels            []int64 `json:"alert_channels,omitempty" validate:"max=20,dive,min=1"`
+	AlertConsecutiveFailures *int64  `json:"alert_consecutive_failures,omitempty" validate:"omitempty,min=0,max=1000"`
+	AlertFailureRate         *int64  `json:"alert_failure_rate,omitempty" validate:"omitempty,min=0,max=100"`
+	AlertFailureWindow       *int64  `json:"alert_failure_window,omitempty" validate:"omitempty,min=2,max=1000"`
+	AlertRenotifyMinutes     *int64  `json:"alert_renotify_minutes,omitempty" validate:"omitempty,min=0,max=10080"`
+	AlertEscalationChannels  []int64 `json:"alert_escalation_channels,omitempty" validate:"max=20,dive,min=1"`
+	AlertEscalationMinutes   *int64  `json:"alert_escalation_minutes,omitempty" validate:"omitempty,min=0,max=10080"`
+
+	TLSClientCertFile     string `json:"tls_client_cert_file,omitempty" validate:"required_with=TLSClientKeyFile,max=500"`
+	TLSClientKeyFile      string `json:"tls_client_key_file,omitempty" validate:"required_with=TLSClientCertFile,max=500"`
+	TLSCAFile             string `json:"tls_ca_file,omitempty" validate:"max=500"`
+	TLSServerName         string `json:"tls_server_name,omitempty" validate:"max=255"`
+	TLSMinVersion         string `json:"tls_min_version,omitempty" validate:"omitempty,oneof=1.0 1.1 1.2 1.3"`
+	TLSInsecureSkipVerify bool   `json:"tls_insecure_skip_verify,omitempty"`
+
+	ProxyURL        string   `json:"proxy_url,omitempty" validate:"max=500"`
+	FollowRedirects *bool    `json:"follow_redirects,omitempty"`
+	MaxRedirects    *int64   `json:"max_redirects,omitempty" validate:"omitempty,min=1,max=50"`
+	Resolve         []string `json:"resolve,omitempty" validate:"max=20,dive,max=300"`
+
+	CertExpiryDays   *int64 `json:"cert_expiry_days,omitempty" validate:"omitempty,min=1,max=365"`
+	CertExpiryAction string `json:"cert_expiry_action,omitempty" validate:"omitempty,oneof=fail warn"`
 }
 
 type CreateJobResponse struct {
 	Id              int64      `json:"id"`
 	Name            string     `json:"name"`
+	Type            string     `json:"type"`
 	Url             string     `json:"url"`
 	Method          string     `json:"method"`
 	Headers         *string    `json:"headers"`
+	Body            *string    `json:"body"`
 	IntervalSeconds int64      `json:"interval_seconds"`
 	NextRunAt       *time.Time `json:"next_run_at"`
 	Active          *bool      `json:"active"`
+	TimeoutSeconds  int64      `json:"timeout_seconds"`
+	Tags            []string   `json:"tags"`
+
+	RetryAttempts      *int64 `json:"retry_attempts"`
+	RetryDelaySeconds  *int64 `json:"retry_delay_seconds"`
+	RetryAfterHostWide bool   `json:"retry_after_host_wide"`
+
+	// HeartbeatToken is the secret in the /ping/{token} URLs of heartbeat
+	// jobs.
+	HeartbeatToken        *string    `json:"heartbeat_token"`
+	HeartbeatGraceSeconds *int64     `json:"heartbeat_grace_seconds"`
+	HeartbeatStartedAt    *time.Time `json:"heartbeat_started_at"`
+
+	// TriggerSecret is masked; only whether one is set is revealed.
+	TriggerToken     *string `json:"trigger_token"`
+	TriggerSecret    *string `json:"trigger_secret"`
+	TriggerRateLimit *int64  `json:"trigger_rate_limit"`
+
+	// BreakerState is closed, open or half_open while a probe runs.
+	BreakerFailureThreshold *int64     `json:"breaker_failure_threshold"`
+	BreakerProbeSeconds     *int64     `json:"breaker_probe_seconds"`
+	BreakerState            string     `json:"breaker_state"`
+	BreakerFailures         int64      `json:"breaker_failures"`
+	BreakerOpenedAt         *time.Time `json:"breaker_opened_at"`
+
+	FailOnErrorStatus bool `json:"fail_on_error_status"`
+
+	DNSRecordType *string `json:"dns_record_type"`
+	DNSExpected   *string `json:"dns_expected"`
+	DNSServer     *string `json:"dns_server"`
+	GRPCService   *string `json:"grpc_service"`
+	GRPCTLS       bool    `json:"grpc_tls"`
+	Steps         []Step  `json:"steps"`
+	SendMessage   *string `json:"send_message"`
+	ExpectMessage *string `json:"expect_message"`
+
+	TrackChanges  bool    `json:"track_changes"`
+	TrackJSONPath *string `json:"track_json_path"`
+
+	MaxBodyBytes  int64    `json:"max_body_bytes"`
+	RedactHeaders []string `json:"redact_headers"`
+
+	RetentionKeepRuns         *int64 `json:"retention_keep_runs"`
+	RetentionKeepDays         *int64 `json:"retention_keep_days"`
+	RetentionKeepFailuresDays *int64 `json:"retention_keep_failures_days"`
+
+	AlertChannels            []int64 `json:"alert_channels"`
+	AlertConsecutiveFailures *int64  `json:"alert_consecutive_failures"`
+	AlertFailureRate         *int64  `json:"alert_failure_rate"`
+	AlertFailureWindow       int64   `json:"alert_failure_window"`
+	AlertRenotifyMinutes     *int64  `json:"alert_renotify_minutes"`
+	AlertEscalationChannels  []int64 `json:"alert_escalation_channels"`
+	AlertEscalationMinutes   *int64  `json:"alert_escalation_minutes"`
+
+	TLSClientCertFile     *string `json:"tls_client_cert_file"`
+	TLSClientKeyFile      *string `json:"tls_client_key_file"`
+	TLSCAFile             *string `json:"tls_ca_file"`
+	TLSServerName         *string `json:"tls_server_name"`
+	TLSMinVersion         *string `json:"tls_min_version"`
+	TLSInsecureSkipVerify bool    `json:"tls_insecure_skip_verify"`
+
+	ProxyURL        *string  `json:"proxy_url"`
+	FollowRedirects bool     `json:"follow_redirects"`
+	MaxRedirects    int64    `json:"max_redirects"`
+	Resolve         []string `json:"resolve"`
+
+	CertExpiryDays   *int64 `json:"cert_expiry_days"`
+	CertExpiryAction string `json:"cert_expiry_action"`
 }
 
 type UpdateJobRequest struct {
 	Name            string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
-	URL             string `json:"url,omitempty" validate:"omitempty,url"`
+	Type            string `json:"type,omitempty" validate:"omitempty,oneof=http tcp dns grpc_health steps websocket sse heartbeat"`
+	URL             string `json:"url,omitempty" validate:"omitempty,max=2048"`
 	Method          string `json:"method,omitempty" validate:"omitempty,oneof=GET POST PUT PATCH DELETE"`
 	Headers         string `json:"headers,omitempty" validate:"max=1000"`
 	IntervalSeconds *int64 `json:"interval_seconds,omitempty" validate:"omitempty,min=1,max=86400"`
 	Active          *bool  `json:"active,omitempty"`
+	TimeoutSeconds  *int64 `json:"timeout_seconds,omitempty" validate:"omitempty,min=1,max=300"`
+	// Tags replace the stored ones when present; [] clears them.
+	Tags                  []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,min=1,max=50"`
+	HeartbeatGraceSeconds *int64   `json:"heartbeat_grace_seconds,omitempty" validate:"omitempty,min=0,max=86400"`
+
+	// An empty Body sends none; a RetryAttempts of 0 stops retrying and
+	// dead-lettering and a RetryDelaySeconds of 0 restores the default.
+	Body               *string `json:"body,omitempty" validate:"omitempty,max=65536"`
+	RetryAttempts      *int64  `json:"retry_attempts,omitempty" validate:"omitempty,min=0,max=10"`
+	RetryDelaySeconds  *int64  `json:"retry_delay_seconds,omitempty" validate:"omitempty,min=0,max=60"`
+	RetryAfterHostWide *bool   `json:"retry_after_host_wide,omitempty"`
+
+	// TriggerEnabled false removes the trigger URL; true keeps the current
+	// token or creates one. An empty TriggerSecret stops requiring
+	// signatures and a TriggerRateLimit of 0 restores the default.
+	TriggerEnabled   *bool   `json:"trigger_enabled,omitempty"`
+	TriggerSecret    *string `json:"trigger_secret,omitempty" validate:"omitempty,max=256"`
+	TriggerRateLimit *int64  `json:"trigger_rate_limit,omitempty" validate:"omitempty,min=0,max=600"`
+
+	// A BreakerFailureThreshold of 0 removes the circuit breaker and closes
+	// it; a BreakerProbeSeconds of 0 restores the default.
+	BreakerFailureThreshold *int64 `json:"breaker_failure_threshold,omitempty" validate:"omitempty,min=0,max=1000"`
+	BreakerProbeSeconds     *int64 `json:"breaker_probe_seconds,omitempty" validate:"omitempty,min=0,max=604800"`
+
+	FailOnErrorStatus *bool `json:"fail_on_error_status,omitempty"`
+
+	DNSRecordType *string `json:"dns_record_type,omitempty" validate:"omitempty,oneof='' A AAAA CNAME MX TXT NS"`
+	DNSExpected   *string `json:"dns_expected,omitempty" validate:"omitempty,max=500"`
+	DNSServer     *string `json:"dns_server,omitempty" validate:"omitempty,max=255"`
+	GRPCService   *string `json:"grpc_service,omitempty" validate:"omitempty,max=255"`
+	GRPCTLS       *bool   `json:"grpc_tls,omitempty"`
+	// Steps replaces the whole step list when present.
+	Steps []Step `json:"steps,omitempty" validate:"omitempty,max=50,dive"`
+
+	SendMessage   *string `json:"send_message,omitempty" validate:"omitempty,max=65536"`
+	ExpectMessage *string `json:"expect_message,omitempty" validate:"omitempty,max=1000"`
+
+	TrackChanges  *bool   `json:"track_changes,omitempty"`
+	TrackJSONPath *string `json:"track_json_path,omitempty" validate:"omitempty,max=500"`
+
+	// MaxBodyBytes set to 0 stops storing response bodies.
+	MaxBodyBytes *int64 `json:"max_body_bytes,omitempty" validate:"omitempty,min=0,max=1048576"`
+	// RedactHeaders replaces the list when present; [] clears it.
+	RedactHeaders []string `json:"redact_headers,omitempty" validate:"omitempty,max=50,dive,min=1,max=100"`
+
+	// Retention overrides set to 0 fall back to the global setting.
+	RetentionKeepRuns         *int64 `json:"retention_keep_runs,omitempty" validate:"omitempty,min=0,max=1000000"`
+	RetentionKeepDays         *int64 `json:"retention_keep_days,omitempty" validate:"omitempty,min=0,max=3650"`
+	RetentionKeepFailuresDays *int64 `json:"retention_keep_failures_days,omitempty" validate:"omitempty,min=0,max=3650"`
+
+	// Alert channel lists replace the stored ones when present; [] clears
+	// them. Alert thresholds and delays set to 0 are disabled.
+	AlertChannels            []int64 `json:"alert_channels,omitempty" validate:"omitempty,max=20,dive,min=1"`
+	AlertConsecutiveFailures *int64  `json:"alert_consecutive_failures,omitempty" validate:"omitempty,min=0,max=1000"`
+	AlertFailureRate         *int64  `json:"alert_failure_rate,omitempty" validate:"omitempty,min=0,max=100"`
+	AlertFailureWindow       *int64  `json:"alert_failure_window,omitempty" validate:"omitempty,min=2,max=1000"`
+	AlertRenotifyMinutes     *int64  `json:"alert_renotify_minutes,omitempty" validate:"omitempty,min=0,max=10080"`
+	AlertEscalationChannels  []int64 `json:"alert_escalation_channels,omitempty" validate:"omitempty,max=20,dive,min=1"`
+	AlertEscalationMinutes   *int64  `json:"alert_escalation_minutes,omitempty" validate:"omitempty,min=0,max=10080"`
+
+	// TLS settings are pointers so an empty string can clear them.
+	TLSClientCertFile     *string `json:"tls_client_cert_file,omitempty" validate:"omitempty,max=500"`
+	TLSClientKeyFile      *string `json:"tls_client_key_file,omitempty" validate:"omitempty,max=500"`
+	TLSCAFile             *string `json:"tls_ca_file,omitempty" validate:"omitempty,max=500"`
+	TLSServerName         *string `json:"tls_server_name,omitempty" validate:"omitempty,max=255"`
+	TLSMinVersion         *string `json:"tls_min_version,omitempty" validate:"omitempty,oneof='' 1.0 1.1 1.2 1.3"`
+	TLSInsecureSkipVerify *bool   `json:"tls_insecure_skip_verify,omitempty"`
+
+	ProxyURL        *string `json:"proxy_url,omitempty" validate:"omitempty,max=500"`
+	FollowRedirects *bool   `json:"follow_redirects,omitempty"`
+	MaxRedirects    *int64  `json:"max_redirects,omitempty" validate:"omitempty,min=1,max=50"`
+	// Resolve replaces the override list when present; [] clears it.
+	Resolve []string `json:"resolve,omitempty" validate:"omitempty,max=20,dive,max=300"`
+
+	// CertExpiryDays set to 0 disables the expiry check.
+	CertExpiryDays   *int64 `json:"cert_expiry_days,omitempty" validate:"omitempty,min=0,max=365"`
+	CertExpiryAction string `json:"cert_expiry_action,omitempty" validate:"omitempty,oneof=fail warn"`
+}
+
+// Step is one request of a "steps" job. URL, header values and body may
+// reference variables extracted by earlier steps as {{name}}. Extract maps a
+// variable name to a JSON path into the body ("$.token") or a response
+// header ("header:Location").
+type Step struct {
+	Name               string            `json:"name,omitempty" validate:"max=100"`
+	Method             string            `json:"method,omitempty" validate:"omitempty,oneof=GET POST PUT PATCH DELETE"`
+	URL                string            `json:"url" validate:"required,max=2048"`
+	Headers            map[string]string `json:"headers,omitempty" validate:"max=50"`
+	Body               string            `json:"body,omitempty" validate:"max=65536"`
+	Extract            map[string]string `json:"extract,omitempty" validate:"max=20,dive,startswith=$|startswith=header:"`
+	ExpectStatus       int               `json:"expect_status,omitempty" validate:"omitempty,min=100,max=599"`
+	ExpectBodyContains string            `json:"expect_body_contains,omitempty" validate:"max=1000"`
 }
 
 type JobIDRequest struct {
@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+type JobRunResponse struct {
+	Id           int64      `json:"id"`
+	JobId        int64      `json:"job_id"`
+	Status       string     `json:"status"`
+	ResponseCode *int64     `json:"response_code"`
+	StartedAt    *time.Time `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	Error        *string    `json:"error"`
+	Timings      RunTimings `json:"timings"`
+	BodyHash     *string    `json:"body_hash"`
+	Changed      bool       `json:"changed"`
+
+	ResponseSize      *int64 `json:"response_size"`
+	ResponseTruncated bool   `json:"response_truncated"`
+
+	// ReplayOfRunId links a replayed dead letter to the run that failed.
+	ReplayOfRunId *int64 `json:"replay_of_run_id"`
+
+	Steps    []JobRunStepResponse `json:"steps,omitempty"`
+	BodyDiff *string              `json:"body_diff,omitempty"`
+	// ResponseHeaders are the stored (redacted) response headers.
+	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+}
+
+type JobRunStepResponse struct {
+	Position     int64   `json:"position"`
+	Name         string  `json:"name"`
+	Status       string  `json:"status"`
+	ResponseCode *int64  `json:"response_code"`
+	DurationMs   *int64  `json:"duration_ms"`
+	Error        *string `json:"error"`
+}
+
+// RunTimings is the request phase breakdown in milliseconds. Phases that
+// were skipped, e.g. DNS on a reused connection, are null.
+type RunTimings struct {
+	DNSLookupMs       *int64 `json:"dns_lookup_ms"`
+	TCPConnectMs      *int64 `json:"tcp_connect_ms"`
+	TLSHandshakeMs    *int64 `json:"tls_handshake_ms"`
+	TimeToFirstByteMs *int64 `json:"time_to_first_byte_ms"`
+	TotalMs           *int64 `json:"total_ms"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+type JobStatsResponse struct {
+	JobId  int64     `json:"job_id"`
+	Window string    `json:"window"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+
+	TotalRuns     int64            `json:"total_runs"`
+	StatusCounts  map[string]int64 `json:"status_counts"`
+	SuccessRate   *float64         `json:"success_rate"`
+	UptimePercent *float64         `json:"uptime_percent"`
+	Latency       LatencyStats     `json:"latency"`
+
+	Series []JobStatsBucket `json:"series"`
+}
+
+// LatencyStats covers runs that did not fail. Percentiles are estimated
+// from a histogram, so they are accurate to the width of its buckets.
+type LatencyStats struct {
+	AvgMs *float64 `json:"avg_ms"`
+	P50Ms *float64 `json:"p50_ms"`
+	P95Ms *float64 `json:"p95_ms"`
+	P99Ms *float64 `json:"p99_ms"`
+}
+
+type JobStatsBucket struct {
+	Start         time.Time        `json:"start"`
+	Runs          int64            `json:"runs"`
+	StatusCounts  map[string]int64 `json:"status_counts"`
+	UptimePercent *float64         `json:"uptime_percent"`
+	AvgLatencyMs  *float64         `json:"avg_latency_ms"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type TriggerJobRequest struct {
+	// Variables fill {{name}} references in the job's templates.
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+type TriggerJobResponse struct {
+	JobId  int64  `json:"job_id"`
+	Status string `json:"status"`
+}
@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CreateWebhookRequest struct {
+	URL string `json:"url" validate:"required,url,max=2048"`
+	// Events lists the event types to deliver; "*" means all of them.
+	Events []string `json:"events" validate:"required,min=1,max=20,dive,oneof=* run.started run.succeeded run.failed job.created job.updated job.deleted job.breaker_opened job.breaker_half_opened job.breaker_closed"`
+	// Secret signs the payloads. A random one is generated when empty.
+	Secret string `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	Active *bool  `json:"active,omitempty"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    string   `json:"url,omitempty" validate:"omitempty,url,max=2048"`
+	Events []string `json:"events,omitempty" validate:"omitempty,min=1,max=20,dive,oneof=* run.started run.succeeded run.failed job.created job.updated job.deleted job.breaker_opened job.breaker_half_opened job.breaker_closed"`
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+type WebhookResponse struct {
+	Id     int64    `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret is only returned when the subscription is created.
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	Id            int64           `json:"id"`
+	EventId       int64           `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	Status        string          `json:"status"`
+	Attempts      int64           `json:"attempts"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseCode  *int64          `json:"response_code,omitempty"`
+	Error         *string         `json:"error,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
@@ -1,35 +1,80 @@
 package api
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
 	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/alerting"
 	internal_middleware "lucasbonna/pulse/internal/api/middleware"
 	"lucasbonna/pulse/internal/api/routes"
 	"lucasbonna/pulse/internal/config"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/janitor"
+	"lucasbonna/pulse/internal/metrics"
+	"lucasbonna/pulse/internal/scheduler"
+	"lucasbonna/pulse/internal/telemetry"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
 type Server struct {
-	db     *db.Queries
-	config *config.Env
+	db        *db.Queries
+	dbConn    *sql.DB
+	config    *config.Env
+	scheduler *scheduler.Scheduler
+	janitor   *janitor.Janitor
+	alerter   *alerting.Alerter
+	events    *events.Bus
+	stream    *events.Stream
+
+	httpServer   *http.Server
+	shuttingDown atomic.Bool
+	// streamsDone is closed on shutdown to end open event streams
+	streamsDone chan struct{}
 }
 
-func NewServer(database *db.Queries, config *config.Env) *Server {
+func NewServer(database *db.Queries, dbConn *sql.DB, config *config.Env, scheduler *scheduler.Scheduler, janitor *janitor.Janitor, alerter *alerting.Alerter, bus *events.Bus, stream *events.Stream) *Server {
 	return &Server{
-		db:     database,
-		config: config,
+		db:        database,
+		dbConn:    dbConn,
+		config:    config,
+		scheduler: scheduler,
+		janitor:   janitor,
+		alerter:   alerter,
+		events:    bus,
+		stream:    stream,
+
+		streamsDone: make(chan struct{}),
 	}
 }
 
 func (s *Server) startRoutes() http.Handler {
 	r := chi.NewRouter()
 
-	jobResource := routes.NewJobResource(s.db)
+	jobResource := routes.NewJobResource(s.db, s.events)
+	certificateResource := routes.NewCertificateResource(s.db)
+	runResource := routes.NewRunResource(s.db)
+	janitorResource := routes.NewJanitorResource(s.janitor)
+	channelResource := routes.NewChannelResource(s.db)
+	incidentResource := routes.NewIncidentResource(s.db, s.alerter)
+	webhookResource := routes.NewWebhookResource(s.db)
+	eventResource := routes.NewEventResource(s.stream, s.streamsDone)
+	deadLetterResource := routes.NewDeadLetterResource(s.db, s.scheduler)
 
 	r.Mount("/jobs", jobResource.Routes())
+	r.Mount("/certificates", certificateResource.Routes())
+	r.Mount("/runs", runResource.Routes())
+	r.Mount("/janitor", janitorResource.Routes())
+	r.Mount("/channels", channelResource.Routes())
+	r.Mount("/incidents", incidentResource.Routes())
+	r.Mount("/webhooks", webhookResource.Routes())
+	r.Mount("/events", eventResource.Routes())
+	r.Mount("/dead-letters", deadLetterResource.Routes())
 
 	return r
 }
@@ -37,20 +82,58 @@ func (s *Server) startRoutes() http.Handler {
 func (s *Server) Start() error {
 	r := chi.NewRouter()
 
-	r.Use(middleware.Logger)
+	r.Use(middleware.RequestID)
+	// tracing goes first so request log lines carry the trace ID
+	r.Use(telemetry.Middleware)
+	r.Use(internal_middleware.RequestLogger)
+	r.Use(metrics.Middleware)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.AllowContentType("application/json"))
 	r.Use(middleware.CleanPath)
 	r.Use(middleware.RedirectSlashes)
 
-	r.Use(internal_middleware.AuthenticationMiddleware(s.config.Token))
+	// probes stay outside authentication so orchestrators can reach them
+	healthResource := routes.NewHealthResource(s.dbConn, s.scheduler, &s.shuttingDown)
+	r.Get("/healthz", healthResource.Healthz)
+	r.Get("/readyz", healthResource.Readyz)
+
+	// heartbeat pings authenticate with the token in their path and may
+	// carry any body, such as a cron job's output
+	pingResource := routes.NewPingResource(s.db, s.scheduler)
+	r.Mount("/ping", pingResource.Routes())
 
-	r.Mount("/api", s.startRoutes())
+	// trigger URLs authenticate with their token and an optional signature
+	triggerResource := routes.NewTriggerResource(s.db, s.scheduler)
+	r.Mount("/trigger", triggerResource.Routes())
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.AllowContentType("application/json"))
+		r.Use(internal_middleware.AuthenticationMiddleware(s.config.Token))
+		r.Mount("/api", s.startRoutes())
+	})
+
+	s.httpServer = &http.Server{
+		Addr:    ":" + s.config.Port,
+		Handler: r,
+	}
 
-	log.Println("starting http server on port ", s.config.Port)
-	if err := http.ListenAndServe(":"+s.config.Port, r); err != nil {
+	slog.Info("starting http server", "port", s.config.Port)
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 
 	return nil
 }
+
+// Shutdown fails readiness, then stops accepting connections and waits for
+// in-flight requests until ctx expires.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if !s.shuttingDown.Swap(true) {
+		close(s.streamsDone)
+	}
+	if s.httpServer == nil {
+		return nil
+	}
+
+	slog.Info("shutting down http server")
+	return s.httpServer.Shutdown(ctx)
+}
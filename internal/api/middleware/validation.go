@@ -131,6 +131,8 @@ func (vm *ValidationMiddleware) formatValidationErrors(err error) []string {
 		switch err.Tag() {
 		case "required":
 			errors = append(errors, fmt.Sprintf("'%s' is required", err.Field()))
+		case "required_with":
+			errors = append(errors, fmt.Sprintf("'%s' is required when %s is set", err.Field(), err.Param()))
 		case "email":
 			errors = append(errors, fmt.Sprintf("'%s' must be a valid email", err.Field()))
 		case "min":
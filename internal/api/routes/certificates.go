@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"context"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CertificatesResource struct {
+	db *db.Queries
+}
+
+func NewCertificateResource(database *db.Queries) *CertificatesResource {
+	return &CertificatesResource{
+		db: database,
+	}
+}
+
+func (cs CertificatesResource) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/", cs.GetCertificateExpiries)
+	return r
+}
+
+// GetCertificateExpiries lists the leaf certificate seen on the latest run
+// of every HTTPS job, soonest expiry first.
+func (cs CertificatesResource) GetCertificateExpiries(w http.ResponseWriter, r *http.Request) {
+	expiries, err := cs.db.GetCertificateExpiries(context.Background())
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch certificates")
+		return
+	}
+
+	now := time.Now()
+
+	certificates := []dto.CertificateExpiryResponse{}
+	for _, expiry := range expiries {
+		certificates = append(certificates, dto.CertificateExpiryResponse{
+			JobId:         expiry.JobID,
+			JobName:       expiry.JobName,
+			JobRunId:      expiry.JobRunID,
+			Host:          expiry.Host,
+			Subject:       expiry.Subject,
+			Issuer:        expiry.Issuer,
+			NotAfter:      expiry.NotAfter,
+			DaysRemaining: int64(expiry.NotAfter.Sub(now).Hours() / 24),
+		})
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, certificates)
+}
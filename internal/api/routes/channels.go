@@ -0,0 +1,229 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/alerting"
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/api/middleware"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maskedPassword replaces stored SMTP passwords in responses.
+const maskedPassword = "********"
+
+type ChannelsResource struct {
+	db         *db.Queries
+	validation *middleware.ValidationMiddleware
+}
+
+func NewChannelResource(database *db.Queries) *ChannelsResource {
+	return &ChannelsResource{
+		db:         database,
+		validation: middleware.NewValidationMiddleware(),
+	}
+}
+
+func (cs ChannelsResource) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/", cs.GetChannels)
+	r.With(middleware.ValidateBody(cs.validation, dto.CreateChannelRequest{})).Post("/", cs.CreateChannel)
+	r.Get("/{id}", cs.GetChannel)
+	r.With(middleware.ValidateBody(cs.validation, dto.UpdateChannelRequest{})).Patch("/{id}", cs.UpdateChannel)
+	r.Delete("/{id}", cs.DeleteChannel)
+	r.Post("/{id}/test", cs.TestChannel)
+	return r
+}
+
+func (cs ChannelsResource) GetChannels(w http.ResponseWriter, r *http.Request) {
+	channels, err := cs.db.GetNotificationChannels(context.Background())
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch channels")
+		return
+	}
+
+	responses := []dto.ChannelResponse{}
+	for _, channel := range channels {
+		responses = append(responses, fromDBChannel(channel))
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, responses)
+}
+
+func (cs ChannelsResource) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	data := middleware.GetValidatedData[dto.CreateChannelRequest](r)
+
+	config, err := encodeChannelConfig(data.Type, alerting.ChannelConfig(data.Config))
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	channel, err := cs.db.CreateNotificationChannel(context.Background(), db.CreateNotificationChannelParams{
+		Name:      data.Name,
+		Type:      data.Type,
+		Config:    config,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to create channel")
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, fromDBChannel(channel))
+}
+
+func (cs ChannelsResource) GetChannel(w http.ResponseWriter, r *http.Request) {
+	channel, ok := cs.loadChannel(w, r)
+	if !ok {
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, fromDBChannel(channel))
+}
+
+func (cs ChannelsResource) UpdateChannel(w http.ResponseWriter, r *http.Request) {
+	channel, ok := cs.loadChannel(w, r)
+	if !ok {
+		return
+	}
+
+	data := middleware.GetValidatedData[dto.UpdateChannelRequest](r)
+
+	name := channel.Name
+	if data.Name != "" {
+		name = data.Name
+	}
+
+	config := channel.Config
+	if data.Config != nil {
+		update := alerting.ChannelConfig(*data.Config)
+		if update.Password == maskedPassword {
+			var current alerting.ChannelConfig
+			json.Unmarshal([]byte(channel.Config), &current)
+			update.Password = current.Password
+		}
+
+		var err error
+		config, err = encodeChannelConfig(channel.Type, update)
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	updated, err := cs.db.UpdateNotificationChannel(context.Background(), db.UpdateNotificationChannelParams{
+		Name:   name,
+		Type:   channel.Type,
+		Config: config,
+		ID:     channel.ID,
+	})
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to update channel")
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, fromDBChannel(updated))
+}
+
+// DeleteChannel removes a channel. Jobs still listing it skip it when
+// alerting.
+func (cs ChannelsResource) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	channelID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid channel ID")
+		return
+	}
+
+	deleted, err := cs.db.DeleteNotificationChannel(context.Background(), channelID)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to delete channel")
+		return
+	}
+	if deleted == 0 {
+		utils.WriteJsonError(w, http.StatusNotFound, "channel not found")
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, "channel deleted")
+}
+
+// TestChannel sends a test notification and reports the delivery error, if
+// any, as 502.
+func (cs ChannelsResource) TestChannel(w http.ResponseWriter, r *http.Request) {
+	channel, ok := cs.loadChannel(w, r)
+	if !ok {
+		return
+	}
+
+	err := alerting.Send(r.Context(), channel, alerting.Notification{
+		Event:   alerting.EventTest,
+		JobName: "Pulse",
+		Reason:  "test notification for channel " + channel.Name,
+		Time:    time.Now().UTC(),
+	})
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, "notification sent")
+}
+
+func (cs ChannelsResource) loadChannel(w http.ResponseWriter, r *http.Request) (db.NotificationChannel, bool) {
+	channelID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid channel ID")
+		return db.NotificationChannel{}, false
+	}
+
+	channel, err := cs.db.GetNotificationChannel(context.Background(), channelID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteJsonError(w, http.StatusNotFound, "channel not found")
+			return db.NotificationChannel{}, false
+		}
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch channel")
+		return db.NotificationChannel{}, false
+	}
+
+	return channel, true
+}
+
+func encodeChannelConfig(channelType string, config alerting.ChannelConfig) (string, error) {
+	if _, err := alerting.NewNotifier(channelType, config); err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encoded), nil
+}
+
+func fromDBChannel(channel db.NotificationChannel) dto.ChannelResponse {
+	var config alerting.ChannelConfig
+	json.Unmarshal([]byte(channel.Config), &config)
+	if config.Password != "" {
+		config.Password = maskedPassword
+	}
+
+	return dto.ChannelResponse{
+		Id:        channel.ID,
+		Name:      channel.Name,
+		Type:      channel.Type,
+		Config:    dto.ChannelConfig(config),
+		CreatedAt: channel.CreatedAt,
+	}
+}
@@ -0,0 +1,328 @@
+package routes
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/api/middleware"
+	"lucasbonna/pulse/internal/scheduler"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const maxBulkReplay = 100
+
+var (
+	errReplayJobGone    = errors.New("job no longer exists")
+	errReplayJobNotHTTP = errors.New("job is no longer an http job")
+)
+
+type DeadLettersResource struct {
+	db         *db.Queries
+	scheduler  *scheduler.Scheduler
+	validation *middleware.ValidationMiddleware
+}
+
+func NewDeadLetterResource(database *db.Queries, scheduler *scheduler.Scheduler) *DeadLettersResource {
+	return &DeadLettersResource{
+		db:         database,
+		scheduler:  scheduler,
+		validation: middleware.NewValidationMiddleware(),
+	}
+}
+
+func (ds DeadLettersResource) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/", ds.GetDeadLetters)
+	r.With(middleware.ValidateBody(ds.validation, dto.ReplayDeadLettersRequest{})).Post("/replay", ds.ReplayDeadLetters)
+	r.Get("/{id}", ds.GetDeadLetter)
+	r.Delete("/{id}", ds.DeleteDeadLetter)
+	r.Post("/{id}/replay", ds.ReplayDeadLetter)
+	return r
+}
+
+// GetDeadLetters lists dead letters, newest first, optionally filtered by
+// status and job_id.
+func (ds DeadLettersResource) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	status := query.Get("status")
+	switch status {
+	case "", "pending", "replayed":
+	default:
+		utils.WriteJsonError(w, http.StatusBadRequest, "status must be pending or replayed")
+		return
+	}
+
+	var jobID int64
+	if jobIDStr := query.Get("job_id"); jobIDStr != "" {
+		var err error
+		jobID, err = strconv.ParseInt(jobIDStr, 10, 64)
+		if err != nil || jobID < 1 {
+			utils.WriteJsonError(w, http.StatusBadRequest, "invalid job ID")
+			return
+		}
+	}
+
+	limit := int64(50)
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit < 1 || limit > 500 {
+			utils.WriteJsonError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+	}
+
+	letters, err := ds.db.ListDeadLetters(context.Background(), db.ListDeadLettersParams{
+		JobID:  jobID,
+		Status: status,
+		Limit:  limit,
+	})
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch dead letters")
+		return
+	}
+
+	responses := []dto.DeadLetterResponse{}
+	jobs := make(map[int64]db.Job)
+	for _, letter := range letters {
+		job, ok := jobs[letter.JobID]
+		if !ok {
+			job, err = ds.letterJob(r.Context(), letter)
+			if err != nil {
+				utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch job")
+				return
+			}
+			jobs[letter.JobID] = job
+		}
+		responses = append(responses, fromDBDeadLetter(letter, job))
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, responses)
+}
+
+func (ds DeadLettersResource) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	letter, ok := ds.loadDeadLetter(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := ds.letterJob(r.Context(), letter)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch job")
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, fromDBDeadLetter(letter, job))
+}
+
+// DeleteDeadLetter discards a dead letter that should not be replayed.
+func (ds DeadLettersResource) DeleteDeadLetter(w http.ResponseWriter, r *http.Request) {
+	letter, ok := ds.loadDeadLetter(w, r)
+	if !ok {
+		return
+	}
+
+	if err := ds.db.DeleteDeadLetter(context.Background(), letter.ID); err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to delete dead letter")
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, "dead letter deleted")
+}
+
+// ReplayDeadLetter re-sends the stored request of one dead letter as a new
+// run of its job.
+func (ds DeadLettersResource) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	letter, ok := ds.loadDeadLetter(w, r)
+	if !ok {
+		return
+	}
+
+	err := ds.replay(r.Context(), letter.JobID, []db.DeadLetter{letter})
+	if err != nil {
+		switch {
+		case errors.Is(err, errReplayJobGone):
+			utils.WriteJsonError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, errReplayJobNotHTTP):
+			utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, scheduler.ErrJobRunning):
+			utils.WriteJsonError(w, http.StatusConflict, err.Error())
+		default:
+			slog.ErrorContext(r.Context(), "failed to replay dead letter", "dead_letter_id", letter.ID, "error", err)
+			utils.WriteJsonError(w, http.StatusInternalServerError, "failed to replay dead letter")
+		}
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusAccepted, dto.ReplayDeadLetterResponse{
+		Id:     letter.ID,
+		JobId:  letter.JobID,
+		Status: "replaying",
+	})
+}
+
+// ReplayDeadLetters replays up to 100 dead letters, picked by ID or as the
+// newest pending ones of a job. Each job replays its letters oldest first;
+// the response reports per dead letter whether its replay started.
+func (ds DeadLettersResource) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	data := middleware.GetValidatedData[dto.ReplayDeadLettersRequest](r)
+
+	if (len(data.IDs) == 0) == (data.JobId == 0) {
+		utils.WriteJsonError(w, http.StatusBadRequest, "either ids or job_id is required")
+		return
+	}
+
+	var letters []db.DeadLetter
+	responses := []dto.ReplayDeadLetterResponse{}
+
+	if data.JobId != 0 {
+		var err error
+		letters, err = ds.db.ListDeadLetters(context.Background(), db.ListDeadLettersParams{
+			JobID:  data.JobId,
+			Status: "pending",
+			Limit:  maxBulkReplay,
+		})
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch dead letters")
+			return
+		}
+	} else {
+		for _, id := range data.IDs {
+			letter, err := ds.db.GetDeadLetterByID(context.Background(), id)
+			if err != nil {
+				message := "failed to fetch dead letter"
+				if errors.Is(err, sql.ErrNoRows) {
+					message = "dead letter not found"
+				}
+				responses = append(responses, dto.ReplayDeadLetterResponse{Id: id, Status: "failed", Error: message})
+				continue
+			}
+			letters = append(letters, letter)
+		}
+	}
+
+	slices.SortFunc(letters, func(a, b db.DeadLetter) int { return cmp.Compare(a.ID, b.ID) })
+	letters = slices.CompactFunc(letters, func(a, b db.DeadLetter) bool { return a.ID == b.ID })
+
+	var jobIDs []int64
+	for _, letter := range letters {
+		if !slices.Contains(jobIDs, letter.JobID) {
+			jobIDs = append(jobIDs, letter.JobID)
+		}
+	}
+
+	for _, jobID := range jobIDs {
+		var jobLetters []db.DeadLetter
+		for _, letter := range letters {
+			if letter.JobID == jobID {
+				jobLetters = append(jobLetters, letter)
+			}
+		}
+
+		err := ds.replay(r.Context(), jobID, jobLetters)
+		for _, letter := range jobLetters {
+			response := dto.ReplayDeadLetterResponse{Id: letter.ID, JobId: jobID, Status: "replaying"}
+			if err != nil {
+				response.Status = "failed"
+				response.Error = err.Error()
+			}
+			responses = append(responses, response)
+		}
+	}
+
+	utils.WriteJsonResponse(w, http.StatusAccepted, responses)
+}
+
+// replay starts replaying letters, which all belong to the job.
+func (ds DeadLettersResource) replay(ctx context.Context, jobID int64, letters []db.DeadLetter) error {
+	job, err := ds.db.GetJobByID(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errReplayJobGone
+		}
+		return err
+	}
+	if job.Type != scheduler.JobTypeHTTP {
+		return errReplayJobNotHTTP
+	}
+
+	if err := ds.scheduler.Replay(ctx, job, letters); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "replaying dead letters", "job_id", jobID, "count", len(letters))
+	return nil
+}
+
+func (ds DeadLettersResource) loadDeadLetter(w http.ResponseWriter, r *http.Request) (db.DeadLetter, bool) {
+	letterID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid dead letter ID")
+		return db.DeadLetter{}, false
+	}
+
+	letter, err := ds.db.GetDeadLetterByID(context.Background(), letterID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteJsonError(w, http.StatusNotFound, "dead letter not found")
+			return db.DeadLetter{}, false
+		}
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch dead letter")
+		return db.DeadLetter{}, false
+	}
+
+	return letter, true
+}
+
+// letterJob returns the job whose headers settings apply to letter, or an
+// empty job once it was deleted.
+func (ds DeadLettersResource) letterJob(ctx context.Context, letter db.DeadLetter) (db.Job, error) {
+	job, err := ds.db.GetJobByID(ctx, letter.JobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Job{}, nil
+	}
+	return job, err
+}
+
+// fromDBDeadLetter converts letter, redacting its headers like the run
+// records of job.
+func fromDBDeadLetter(letter db.DeadLetter, job db.Job) dto.DeadLetterResponse {
+	response := dto.DeadLetterResponse{
+		Id:        letter.ID,
+		JobId:     letter.JobID,
+		JobRunId:  letter.JobRunID,
+		Status:    "pending",
+		Method:    letter.Method,
+		URL:       letter.Url,
+		Attempts:  letter.Attempts,
+		Error:     letter.Error,
+		CreatedAt: letter.CreatedAt,
+	}
+
+	var headers http.Header
+	json.Unmarshal([]byte(letter.Headers), &headers)
+	if redacted, err := scheduler.RedactRequestHeaders(job, headers); err == nil {
+		response.Headers = redacted
+	}
+	response.Body = stringPtr(letter.Body)
+	response.ResponseCode = int64Ptr(letter.ResponseCode)
+	response.ReplayRunId = int64Ptr(letter.ReplayRunID)
+	if letter.ReplayedAt.Valid {
+		response.Status = "replayed"
+		response.ReplayedAt = &letter.ReplayedAt.Time
+	}
+
+	return response
+}
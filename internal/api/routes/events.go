@@ -0,0 +1,145 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// keepaliveInterval is how often an idle stream sends a comment, so proxies
+// do not close it.
+const keepaliveInterval = 15 * time.Second
+
+type EventsResource struct {
+	stream *events.Stream
+	// done is closed on shutdown to end open streams, which would otherwise
+	// hold the graceful shutdown until it times out
+	done <-chan struct{}
+}
+
+func NewEventResource(stream *events.Stream, done <-chan struct{}) *EventsResource {
+	return &EventsResource{
+		stream: stream,
+		done:   done,
+	}
+}
+
+func (es EventsResource) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/", es.StreamEvents)
+	return r
+}
+
+// StreamEvents streams events as Server-Sent Events. Repeated job_id and tag
+// parameters narrow the stream to events about any of those jobs or tags.
+// Reconnecting clients send Last-Event-ID (or last_event_id) and receive the
+// buffered events they missed first, preceded by an events.missed event when
+// some are no longer buffered.
+func (es EventsResource) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var jobIDs []int64
+	for _, jobIDStr := range query["job_id"] {
+		jobID, err := strconv.ParseInt(jobIDStr, 10, 64)
+		if err != nil || jobID < 1 {
+			utils.WriteJsonError(w, http.StatusBadRequest, "invalid job ID")
+			return
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+	tags := query["tag"]
+
+	lastEventIDStr := r.Header.Get("Last-Event-ID")
+	if lastEventIDStr == "" {
+		lastEventIDStr = query.Get("last_event_id")
+	}
+	var lastEventID int64
+	if lastEventIDStr != "" {
+		var err error
+		lastEventID, err = strconv.ParseInt(lastEventIDStr, 10, 64)
+		if err != nil || lastEventID < 0 {
+			utils.WriteJsonError(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	matches := func(event events.Event) bool {
+		if len(jobIDs) == 0 && len(tags) == 0 {
+			return true
+		}
+		if slices.Contains(jobIDs, event.JobID) {
+			return true
+		}
+		return slices.ContainsFunc(event.JobTags, func(tag string) bool {
+			return slices.Contains(tags, tag)
+		})
+	}
+
+	backlog, missed, live, unsubscribe := es.stream.Subscribe(lastEventID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, ": connected\n\n")
+	if missed {
+		// no id, so the client's Last-Event-ID stays where it was
+		fmt.Fprintf(w, "event: %s\ndata: {\"last_event_id\":%d}\n\n", events.EventsMissed, lastEventID)
+	}
+	for _, event := range backlog {
+		if matches(event) {
+			writeEvent(w, event)
+		}
+	}
+	flusher.Flush()
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-es.done:
+			return
+		case event, ok := <-live:
+			if !ok {
+				// too slow; the client reconnects with Last-Event-ID
+				return
+			}
+			if !matches(event) {
+				continue
+			}
+			writeEvent(w, event)
+			flusher.Flush()
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"bufio"
+	"context"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/events"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readEvents reads the type and ID of the first n events of an event
+// stream, ignoring comments.
+func readEvents(t *testing.T, scanner *bufio.Scanner, n int) []string {
+	t.Helper()
+
+	var got []string
+	var event, id string
+	for len(got) < n && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case line == "" && event != "":
+			got = append(got, event+"#"+id)
+			event, id = "", ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	return got
+}
+
+func TestStreamEventsResume(t *testing.T) {
+	bus := events.NewBus()
+	stream := events.NewStream(bus, 2)
+	var ids []string
+	bus.Subscribe(func(event events.Event) { ids = append(ids, strconv.FormatInt(event.ID, 10)) })
+	for jobID := range int64(4) {
+		bus.Publish(events.RunFailed, db.Job{ID: jobID + 1}, nil)
+	}
+
+	done := make(chan struct{})
+	server := httptest.NewServer(NewEventResource(stream, done).Routes())
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(done) })
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		query       string
+		want        []string
+	}{
+		{"inside the buffer", ids[1], "", []string{"run.failed#" + ids[2], "run.failed#" + ids[3]}},
+		{"past the buffer", ids[0], "", []string{"events.missed#", "run.failed#" + ids[2], "run.failed#" + ids[3]}},
+		{"filtered", ids[0], "?job_id=4", []string{"events.missed#", "run.failed#" + ids[3]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+tt.query, nil)
+			request.Header.Set("Last-Event-ID", tt.lastEventID)
+			resp, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatalf("GET: %v", err)
+			}
+			defer resp.Body.Close()
+
+			got := readEvents(t, bufio.NewScanner(resp.Body), len(tt.want))
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
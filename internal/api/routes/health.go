@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/scheduler"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// schedulerStaleAfter is how long the scheduler loop may go without
+	// completing a tick before readiness fails. It ticks every second.
+	schedulerStaleAfter = 10 * time.Second
+	databasePingTimeout = 2 * time.Second
+)
+
+// HealthResource serves the unauthenticated liveness and readiness probes.
+type HealthResource struct {
+	dbConn       *sql.DB
+	scheduler    *scheduler.Scheduler
+	shuttingDown *atomic.Bool
+}
+
+func NewHealthResource(dbConn *sql.DB, scheduler *scheduler.Scheduler, shuttingDown *atomic.Bool) *HealthResource {
+	return &HealthResource{
+		dbConn:       dbConn,
+		scheduler:    scheduler,
+		shuttingDown: shuttingDown,
+	}
+}
+
+// Healthz reports that the process is up and serving HTTP.
+func (hr HealthResource) Healthz(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJsonResponse(w, http.StatusOK, dto.HealthResponse{Status: "ok"})
+}
+
+// Readyz checks the database, that the scheduler loop is still ticking and
+// that the server is not shutting down. Any failure answers 503.
+func (hr HealthResource) Readyz(w http.ResponseWriter, r *http.Request) {
+	response := dto.ReadinessResponse{
+		Status:       "ok",
+		ShuttingDown: hr.shuttingDown.Load(),
+		Database:     dto.ReadinessCheck{Status: "ok"},
+		Scheduler:    dto.ReadinessCheck{Status: "ok"},
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), databasePingTimeout)
+	defer cancel()
+	if err := hr.dbConn.PingContext(ctx); err != nil {
+		response.Database = failedCheck(err.Error())
+	}
+
+	lastTick := hr.scheduler.LastTick()
+	if lastTick.IsZero() {
+		response.Scheduler = failedCheck("scheduler has not started")
+	} else {
+		if age := time.Since(lastTick); age > schedulerStaleAfter {
+			response.Scheduler = failedCheck(fmt.Sprintf("last tick was %s ago", age.Round(time.Second)))
+		}
+		response.Scheduler.LastTickAt = &lastTick
+	}
+
+	statusCode := http.StatusOK
+	if response.ShuttingDown || response.Database.Status != "ok" || response.Scheduler.Status != "ok" {
+		response.Status = "unavailable"
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	utils.WriteJsonResponse(w, statusCode, response)
+}
+
+func failedCheck(message string) dto.ReadinessCheck {
+	return dto.ReadinessCheck{Status: "fail", Error: &message}
+}
@@ -0,0 +1,175 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/alerting"
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type IncidentsResource struct {
+	db      *db.Queries
+	alerter *alerting.Alerter
+}
+
+func NewIncidentResource(database *db.Queries, alerter *alerting.Alerter) *IncidentsResource {
+	return &IncidentsResource{
+		db:      database,
+		alerter: alerter,
+	}
+}
+
+func (is IncidentsResource) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/", is.GetIncidents)
+	r.Get("/{id}", is.GetIncident)
+	r.Post("/{id}/ack", is.AcknowledgeIncident)
+	return r
+}
+
+// GetIncidents lists incidents, newest first, optionally filtered by state
+// and job_id.
+func (is IncidentsResource) GetIncidents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	state := query.Get("state")
+	switch state {
+	case "", alerting.IncidentOpen, alerting.IncidentAcknowledged, alerting.IncidentResolved:
+	default:
+		utils.WriteJsonError(w, http.StatusBadRequest, "state must be open, acknowledged or resolved")
+		return
+	}
+
+	var jobID int64
+	if jobIDStr := query.Get("job_id"); jobIDStr != "" {
+		var err error
+		jobID, err = strconv.ParseInt(jobIDStr, 10, 64)
+		if err != nil || jobID < 1 {
+			utils.WriteJsonError(w, http.StatusBadRequest, "invalid job ID")
+			return
+		}
+	}
+
+	limit := int64(50)
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit < 1 || limit > 500 {
+			utils.WriteJsonError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+	}
+
+	incidents, err := is.db.ListIncidents(context.Background(), db.ListIncidentsParams{
+		State: state,
+		JobID: jobID,
+		Limit: limit,
+	})
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch incidents")
+		return
+	}
+
+	responses := []dto.IncidentResponse{}
+	for _, incident := range incidents {
+		responses = append(responses, fromDBIncident(incident))
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, responses)
+}
+
+func (is IncidentsResource) GetIncident(w http.ResponseWriter, r *http.Request) {
+	incidentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid incident ID")
+		return
+	}
+
+	incident, err := is.db.GetIncident(context.Background(), incidentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteJsonError(w, http.StatusNotFound, "incident not found")
+			return
+		}
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch incident")
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, fromDBIncident(incident))
+}
+
+// AcknowledgeIncident stops reminders and escalation for an open incident.
+// The body is optional: {"by": "alice"} records who took it.
+func (is IncidentsResource) AcknowledgeIncident(w http.ResponseWriter, r *http.Request) {
+	incidentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid incident ID")
+		return
+	}
+
+	var data dto.AcknowledgeIncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil && !errors.Is(err, io.EOF) {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if len(data.By) > 100 {
+		utils.WriteJsonError(w, http.StatusBadRequest, "by must be at most 100 characters")
+		return
+	}
+
+	incident, err := is.alerter.Acknowledge(r.Context(), incidentID, data.By)
+	switch {
+	case errors.Is(err, alerting.ErrIncidentNotFound):
+		utils.WriteJsonError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, alerting.ErrIncidentNotOpen):
+		utils.WriteJsonError(w, http.StatusConflict, "incident is already "+incident.State)
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "failed to acknowledge incident", "incident_id", incidentID, "error", err)
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to acknowledge incident")
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, fromDBIncident(incident))
+}
+
+func fromDBIncident(incident db.Incident) dto.IncidentResponse {
+	response := dto.IncidentResponse{
+		Id:                  incident.ID,
+		JobId:               incident.JobID,
+		State:               incident.State,
+		Reason:              incident.Reason,
+		ConsecutiveFailures: incident.ConsecutiveFailures,
+		OpenedAt:            incident.OpenedAt,
+		LastNotifiedAt:      incident.LastNotifiedAt,
+		Notifications:       incident.Notifications,
+	}
+
+	response.LastRunId = int64Ptr(incident.LastRunID)
+	response.LastError = stringPtr(incident.LastError)
+	response.AcknowledgedBy = stringPtr(incident.AcknowledgedBy)
+
+	if incident.AcknowledgedAt.Valid {
+		response.AcknowledgedAt = &incident.AcknowledgedAt.Time
+	}
+	if incident.EscalatedAt.Valid {
+		response.EscalatedAt = &incident.EscalatedAt.Time
+	}
+	if incident.ResolvedAt.Valid {
+		response.ResolvedAt = &incident.ResolvedAt.Time
+	}
+
+	return response
+}
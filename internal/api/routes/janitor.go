@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/janitor"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type JanitorResource struct {
+	janitor *janitor.Janitor
+}
+
+func NewJanitorResource(janitor *janitor.Janitor) *JanitorResource {
+	return &JanitorResource{
+		janitor: janitor,
+	}
+}
+
+func (jr JanitorResource) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/", jr.GetStatus)
+	return r
+}
+
+// GetStatus reports the retention policy and the outcome of the janitor's
+// most recent pass.
+func (jr JanitorResource) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status := jr.janitor.Status()
+	policy := jr.janitor.Policy()
+
+	response := dto.JanitorStatusResponse{
+		Enabled:          policy.JanitorInterval > 0,
+		LastRunAt:        status.LastRunAt,
+		LastDurationMs:   status.LastDuration.Milliseconds(),
+		LastDeletedRuns:  status.LastDeletedRuns,
+		TotalDeletedRuns: status.TotalDeletedRuns,
+		LastVacuumAt:     status.LastVacuumAt,
+
+		KeepRuns:         policy.KeepRuns,
+		KeepDays:         policy.KeepDays,
+		KeepFailuresDays: policy.KeepFailuresDays,
+		Interval:         policy.JanitorInterval.String(),
+		VacuumInterval:   policy.VacuumInterval.String(),
+	}
+
+	if status.LastError != "" {
+		response.LastError = &status.LastError
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, response)
+}
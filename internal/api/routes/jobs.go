@@ -3,10 +3,15 @@ package routes
 import (
 	"context"
 	"database/sql"
-	"log"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"lucasbonna/pulse/db"
 	"lucasbonna/pulse/internal/api/dto"
 	"lucasbonna/pulse/internal/api/middleware"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/scheduler"
 	"lucasbonna/pulse/internal/utils"
 	"net/http"
 	"strconv"
@@ -17,12 +22,14 @@ import (
 
 type JobsResource struct {
 	db         *db.Queries
+	events     *events.Bus
 	validation *middleware.ValidationMiddleware
 }
 
-func NewJobResource(database *db.Queries) *JobsResource {
+func NewJobResource(database *db.Queries, bus *events.Bus) *JobsResource {
 	return &JobsResource{
 		db:         database,
+		events:     bus,
 		validation: middleware.NewValidationMiddleware(),
 	}
 }
@@ -34,6 +41,8 @@ func (js JobsResource) Routes() http.Handler {
 	r.With(middleware.ValidateBody(js.validation, dto.CreateJobRequest{})).Post("/", js.CreateJob)
 	r.With(middleware.ValidateBody(js.validation, dto.UpdateJobRequest{})).Patch("/{id}", js.UpdateJob)
 	r.Delete("/{id}", js.DeleteJob)
+	r.Get("/{id}/runs", js.GetJobRuns)
+	r.Get("/{id}/stats", js.GetJobStats)
 	return r
 }
 
@@ -60,34 +69,255 @@ func (js JobsResource) DeleteJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the job is loaded first so job.deleted carries its tags, and is only
+	// published when there was a job to delete
+	job, err := js.db.GetJobByID(context.Background(), jobID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to delete job")
+		return
+	}
+	found := err == nil
+
 	err = js.db.DeleteJob(context.Background(), jobID)
 	if err != nil {
 		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to delete job")
 		return
 	}
 
+	if found {
+		js.events.Publish(events.JobDeleted, job, map[string]int64{"job_id": jobID})
+	}
+
 	utils.WriteJsonResponse(w, http.StatusOK, "job deleted")
 }
 
+func (js JobsResource) GetJobRuns(w http.ResponseWriter, r *http.Request) {
+	jobIDStr := chi.URLParam(r, "id")
+	jobID, err := strconv.ParseInt(jobIDStr, 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid job ID")
+		return
+	}
+
+	limit := int64(50)
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit < 1 || limit > 500 {
+			utils.WriteJsonError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+	}
+
+	runs, err := js.db.GetJobRuns(context.Background(), db.GetJobRunsParams{
+		JobID: jobID,
+		Limit: limit,
+	})
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch job runs")
+		return
+	}
+
+	runResponses := []dto.JobRunResponse{}
+	for _, run := range runs {
+		runResponses = append(runResponses, fromDBJobRun(run))
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, runResponses)
+}
+
 func (js JobsResource) CreateJob(w http.ResponseWriter, r *http.Request) {
 	data := middleware.GetValidatedData[dto.CreateJobRequest](r)
 
+	jobType := scheduler.JobTypeHTTP
+	if data.Type != "" {
+		jobType = data.Type
+	}
+
+	if err := scheduler.ValidateTarget(jobType, data.URL); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	steps, err := encodeSteps(jobType, data.Steps)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := validateTracking(jobType, data.TrackChanges, data.TrackJSONPath); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	method := "GET"
+	if data.Method != "" {
+		method = data.Method
+	}
+
+	if _, err := scheduler.ParseHeaders(data.Headers); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	timeoutSeconds := int64(30)
+	if data.TimeoutSeconds != nil {
+		timeoutSeconds = *data.TimeoutSeconds
+	}
+
+	if err := validateConnectionSettings(data.ProxyURL, data.Resolve); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resolve, err := encodeStringList(data.Resolve)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	followRedirects := true
+	if data.FollowRedirects != nil {
+		followRedirects = *data.FollowRedirects
+	}
+
+	maxRedirects := int64(10)
+	if data.MaxRedirects != nil {
+		maxRedirects = *data.MaxRedirects
+	}
+
+	maxBodyBytes := int64(65536)
+	if data.MaxBodyBytes != nil {
+		maxBodyBytes = *data.MaxBodyBytes
+	}
+
+	redactHeaders, err := encodeStringList(data.RedactHeaders)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tags, err := encodeStringList(data.Tags)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// heartbeat jobs are not run but fail when no ping arrives in time, so
+	// their first deadline is a full period away
+	nextRunAt := time.Now()
+	heartbeatGrace := nullPositiveInt64(data.HeartbeatGraceSeconds)
+	heartbeatToken := sql.NullString{}
+	if jobType == scheduler.JobTypeHeartbeat {
+		nextRunAt = scheduler.HeartbeatDeadline(nextRunAt, data.IntervalSeconds, heartbeatGrace.Int64)
+		heartbeatToken = sql.NullString{String: randomToken(16), Valid: true}
+	}
+
+	triggerToken := sql.NullString{}
+	if data.TriggerEnabled {
+		triggerToken = sql.NullString{String: randomToken(16), Valid: true}
+	}
+
+	certExpiryAction := "fail"
+	if data.CertExpiryAction != "" {
+		certExpiryAction = data.CertExpiryAction
+	}
+
+	alertChannels, err := js.encodeAlertChannels(r.Context(), data.AlertChannels)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	alertFailureWindow := int64(20)
+	if data.AlertFailureWindow != nil {
+		alertFailureWindow = *data.AlertFailureWindow
+	}
+
+	alertEscalationChannels, err := js.encodeAlertChannels(r.Context(), data.AlertEscalationChannels)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	createdJob, err := js.db.CreateJob(context.Background(), db.CreateJobParams{
 		Name:            data.Name,
 		Url:             data.URL,
-		Method:          data.Method,
-		Headers:         sql.NullString{String: data.Headers, Valid: data.Method != ""},
+		Method:          method,
+		Headers:         nullString(data.Headers),
 		IntervalSeconds: data.IntervalSeconds,
-		NextRunAt:       sql.NullTime{Time: time.Now(), Valid: true},
+		NextRunAt:       sql.NullTime{Time: nextRunAt, Valid: true},
 		Active:          sql.NullBool{Bool: data.Active, Valid: true},
+
+		TlsClientCertFile:     nullString(data.TLSClientCertFile),
+		TlsClientKeyFile:      nullString(data.TLSClientKeyFile),
+		TlsCaFile:             nullString(data.TLSCAFile),
+		TlsServerName:         nullString(data.TLSServerName),
+		TlsMinVersion:         nullString(data.TLSMinVersion),
+		TlsInsecureSkipVerify: sql.NullBool{Bool: data.TLSInsecureSkipVerify, Valid: true},
+
+		ProxyUrl:        nullString(data.ProxyURL),
+		FollowRedirects: sql.NullBool{Bool: followRedirects, Valid: true},
+		MaxRedirects:    sql.NullInt64{Int64: maxRedirects, Valid: true},
+		Resolve:         resolve,
+
+		CertExpiryDays:   nullInt64(data.CertExpiryDays),
+		CertExpiryAction: certExpiryAction,
+
+		Type:           jobType,
+		TimeoutSeconds: timeoutSeconds,
+		DnsRecordType:  nullString(data.DNSRecordType),
+		DnsExpected:    nullString(data.DNSExpected),
+		DnsServer:      nullString(data.DNSServer),
+		GrpcService:    nullString(data.GRPCService),
+		GrpcTls:        sql.NullBool{Bool: data.GRPCTLS, Valid: true},
+		Steps:          steps,
+		SendMessage:    nullString(data.SendMessage),
+		ExpectMessage:  nullString(data.ExpectMessage),
+		TrackChanges:   sql.NullBool{Bool: data.TrackChanges, Valid: true},
+		TrackJsonPath:  nullString(data.TrackJSONPath),
+		MaxBodyBytes:   maxBodyBytes,
+		RedactHeaders:  redactHeaders,
+
+		RetentionKeepRuns:         nullPositiveInt64(data.RetentionKeepRuns),
+		RetentionKeepDays:         nullPositiveInt64(data.RetentionKeepDays),
+		RetentionKeepFailuresDays: nullPositiveInt64(data.RetentionKeepFailuresDays),
+
+		AlertChannels:            alertChannels,
+		AlertConsecutiveFailures: nullPositiveInt64(data.AlertConsecutiveFailures),
+		AlertFailureRate:         nullPositiveInt64(data.AlertFailureRate),
+		AlertFailureWindow:       alertFailureWindow,
+		AlertRenotifyMinutes:     nullPositiveInt64(data.AlertRenotifyMinutes),
+		AlertEscalationChannels:  alertEscalationChannels,
+		AlertEscalationMinutes:   nullPositiveInt64(data.AlertEscalationMinutes),
+
+		Tags: tags,
+
+		HeartbeatToken:        heartbeatToken,
+		HeartbeatGraceSeconds: heartbeatGrace,
+
+		TriggerToken:     triggerToken,
+		TriggerSecret:    nullString(data.TriggerSecret),
+		TriggerRateLimit: nullPositiveInt64(data.TriggerRateLimit),
+
+		BreakerFailureThreshold: nullPositiveInt64(data.BreakerFailureThreshold),
+		BreakerProbeSeconds:     nullPositiveInt64(data.BreakerProbeSeconds),
+
+		Body:               nullString(data.Body),
+		RetryAttempts:      nullPositiveInt64(data.RetryAttempts),
+		RetryDelaySeconds:  nullPositiveInt64(data.RetryDelaySeconds),
+		RetryAfterHostWide: sql.NullBool{Bool: data.RetryAfterHostWide, Valid: true},
+		FailOnErrorStatus:  sql.NullBool{Bool: data.FailOnErrorStatus, Valid: true},
 	})
 	if err != nil {
-		log.Println("error creating job", err)
+		slog.ErrorContext(r.Context(), "failed to create job", "error", err)
 		utils.WriteJsonResponse(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	utils.WriteJsonResponse(w, http.StatusOK, fromDBJob(createdJob))
+	response := fromDBJob(createdJob)
+	js.events.Publish(events.JobCreated, createdJob, jobEventData(response))
+
+	utils.WriteJsonResponse(w, http.StatusOK, response)
 }
 
 func (js JobsResource) UpdateJob(w http.ResponseWriter, r *http.Request) {
@@ -115,11 +345,30 @@ func (js JobsResource) UpdateJob(w http.ResponseWriter, r *http.Request) {
 		name = data.Name
 	}
 
+	jobType := currentJob.Type
+	if data.Type != "" {
+		jobType = data.Type
+	}
+
 	url := currentJob.Url
 	if data.URL != "" {
 		url = data.URL
 	}
 
+	if err := scheduler.ValidateTarget(jobType, url); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	steps := currentJob.Steps
+	if data.Steps != nil || jobType != currentJob.Type {
+		steps, err = encodeSteps(jobType, data.Steps)
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	method := currentJob.Method
 	if data.Method != "" {
 		method = data.Method
@@ -127,6 +376,10 @@ func (js JobsResource) UpdateJob(w http.ResponseWriter, r *http.Request) {
 
 	headers := currentJob.Headers
 	if data.Headers != "" {
+		if _, err := scheduler.ParseHeaders(data.Headers); err != nil {
+			utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		headers = sql.NullString{String: data.Headers, Valid: true}
 	}
 
@@ -140,6 +393,159 @@ func (js JobsResource) UpdateJob(w http.ResponseWriter, r *http.Request) {
 		active = sql.NullBool{Bool: *data.Active, Valid: true}
 	}
 
+	tlsInsecureSkipVerify := currentJob.TlsInsecureSkipVerify
+	if data.TLSInsecureSkipVerify != nil {
+		tlsInsecureSkipVerify = sql.NullBool{Bool: *data.TLSInsecureSkipVerify, Valid: true}
+	}
+
+	clientCertFile := mergeNullString(currentJob.TlsClientCertFile, data.TLSClientCertFile)
+	clientKeyFile := mergeNullString(currentJob.TlsClientKeyFile, data.TLSClientKeyFile)
+	if clientCertFile.Valid != clientKeyFile.Valid {
+		utils.WriteJsonError(w, http.StatusBadRequest, "tls_client_cert_file and tls_client_key_file must be set together")
+		return
+	}
+
+	proxyURL := mergeNullString(currentJob.ProxyUrl, data.ProxyURL)
+
+	resolve := currentJob.Resolve
+	if data.Resolve != nil {
+		resolve, err = encodeStringList(data.Resolve)
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := validateConnectionSettings(proxyURL.String, data.Resolve); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	followRedirects := currentJob.FollowRedirects
+	if data.FollowRedirects != nil {
+		followRedirects = sql.NullBool{Bool: *data.FollowRedirects, Valid: true}
+	}
+
+	maxRedirects := currentJob.MaxRedirects
+	if data.MaxRedirects != nil {
+		maxRedirects = sql.NullInt64{Int64: *data.MaxRedirects, Valid: true}
+	}
+
+	certExpiryDays := currentJob.CertExpiryDays
+	if data.CertExpiryDays != nil {
+		certExpiryDays = sql.NullInt64{Int64: *data.CertExpiryDays, Valid: *data.CertExpiryDays > 0}
+	}
+
+	certExpiryAction := currentJob.CertExpiryAction
+	if data.CertExpiryAction != "" {
+		certExpiryAction = data.CertExpiryAction
+	}
+
+	timeoutSeconds := currentJob.TimeoutSeconds
+	if data.TimeoutSeconds != nil {
+		timeoutSeconds = *data.TimeoutSeconds
+	}
+
+	grpcTLS := currentJob.GrpcTls
+	if data.GRPCTLS != nil {
+		grpcTLS = sql.NullBool{Bool: *data.GRPCTLS, Valid: true}
+	}
+
+	retryAfterHostWide := currentJob.RetryAfterHostWide
+	if data.RetryAfterHostWide != nil {
+		retryAfterHostWide = sql.NullBool{Bool: *data.RetryAfterHostWide, Valid: true}
+	}
+
+	failOnErrorStatus := currentJob.FailOnErrorStatus
+	if data.FailOnErrorStatus != nil {
+		failOnErrorStatus = sql.NullBool{Bool: *data.FailOnErrorStatus, Valid: true}
+	}
+
+	trackChanges := currentJob.TrackChanges
+	if data.TrackChanges != nil {
+		trackChanges = sql.NullBool{Bool: *data.TrackChanges, Valid: true}
+	}
+
+	trackJSONPath := mergeNullString(currentJob.TrackJsonPath, data.TrackJSONPath)
+
+	if err := validateTracking(jobType, trackChanges.Bool, trackJSONPath.String); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	maxBodyBytes := currentJob.MaxBodyBytes
+	if data.MaxBodyBytes != nil {
+		maxBodyBytes = *data.MaxBodyBytes
+	}
+
+	redactHeaders := currentJob.RedactHeaders
+	if data.RedactHeaders != nil {
+		redactHeaders, err = encodeStringList(data.RedactHeaders)
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	tags := currentJob.Tags
+	if data.Tags != nil {
+		tags, err = encodeStringList(data.Tags)
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	heartbeatToken := sql.NullString{}
+	if jobType == scheduler.JobTypeHeartbeat {
+		heartbeatToken = currentJob.HeartbeatToken
+		if !heartbeatToken.Valid {
+			heartbeatToken = sql.NullString{String: randomToken(16), Valid: true}
+		}
+	}
+
+	triggerToken := currentJob.TriggerToken
+	if data.TriggerEnabled != nil {
+		if !*data.TriggerEnabled {
+			triggerToken = sql.NullString{}
+		} else if !triggerToken.Valid {
+			triggerToken = sql.NullString{String: randomToken(16), Valid: true}
+		}
+	}
+
+	// the masked secret returned by the API keeps the stored one
+	triggerSecret := currentJob.TriggerSecret
+	if data.TriggerSecret != nil && *data.TriggerSecret != maskedPassword {
+		if *data.TriggerSecret != "" && len(*data.TriggerSecret) < 16 {
+			utils.WriteJsonError(w, http.StatusBadRequest, "trigger_secret must be at least 16 characters")
+			return
+		}
+		triggerSecret = nullString(*data.TriggerSecret)
+	}
+
+	alertChannels := currentJob.AlertChannels
+	if data.AlertChannels != nil {
+		alertChannels, err = js.encodeAlertChannels(r.Context(), data.AlertChannels)
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	alertFailureWindow := currentJob.AlertFailureWindow
+	if data.AlertFailureWindow != nil {
+		alertFailureWindow = *data.AlertFailureWindow
+	}
+
+	alertEscalationChannels := currentJob.AlertEscalationChannels
+	if data.AlertEscalationChannels != nil {
+		alertEscalationChannels, err = js.encodeAlertChannels(r.Context(), data.AlertEscalationChannels)
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	updatedJob, err := js.db.UpdateJob(context.Background(), db.UpdateJobParams{
 		Name:            name,
 		Url:             url,
@@ -147,24 +553,132 @@ func (js JobsResource) UpdateJob(w http.ResponseWriter, r *http.Request) {
 		Headers:         headers,
 		IntervalSeconds: intervalSeconds,
 		Active:          active,
-		ID:              jobID,
+
+		TlsClientCertFile:     clientCertFile,
+		TlsClientKeyFile:      clientKeyFile,
+		TlsCaFile:             mergeNullString(currentJob.TlsCaFile, data.TLSCAFile),
+		TlsServerName:         mergeNullString(currentJob.TlsServerName, data.TLSServerName),
+		TlsMinVersion:         mergeNullString(currentJob.TlsMinVersion, data.TLSMinVersion),
+		TlsInsecureSkipVerify: tlsInsecureSkipVerify,
+
+		ProxyUrl:        proxyURL,
+		FollowRedirects: followRedirects,
+		MaxRedirects:    maxRedirects,
+		Resolve:         resolve,
+
+		CertExpiryDays:   certExpiryDays,
+		CertExpiryAction: certExpiryAction,
+
+		Type:           jobType,
+		TimeoutSeconds: timeoutSeconds,
+		DnsRecordType:  mergeNullString(currentJob.DnsRecordType, data.DNSRecordType),
+		DnsExpected:    mergeNullString(currentJob.DnsExpected, data.DNSExpected),
+		DnsServer:      mergeNullString(currentJob.DnsServer, data.DNSServer),
+		GrpcService:    mergeNullString(currentJob.GrpcService, data.GRPCService),
+		GrpcTls:        grpcTLS,
+		Steps:          steps,
+		SendMessage:    mergeNullString(currentJob.SendMessage, data.SendMessage),
+		ExpectMessage:  mergeNullString(currentJob.ExpectMessage, data.ExpectMessage),
+		TrackChanges:   trackChanges,
+		TrackJsonPath:  trackJSONPath,
+		MaxBodyBytes:   maxBodyBytes,
+		RedactHeaders:  redactHeaders,
+
+		RetentionKeepRuns:         mergeNullInt64(currentJob.RetentionKeepRuns, data.RetentionKeepRuns),
+		RetentionKeepDays:         mergeNullInt64(currentJob.RetentionKeepDays, data.RetentionKeepDays),
+		RetentionKeepFailuresDays: mergeNullInt64(currentJob.RetentionKeepFailuresDays, data.RetentionKeepFailuresDays),
+
+		AlertChannels:            alertChannels,
+		AlertConsecutiveFailures: mergeNullInt64(currentJob.AlertConsecutiveFailures, data.AlertConsecutiveFailures),
+		AlertFailureRate:         mergeNullInt64(currentJob.AlertFailureRate, data.AlertFailureRate),
+		AlertFailureWindow:       alertFailureWindow,
+		AlertRenotifyMinutes:     mergeNullInt64(currentJob.AlertRenotifyMinutes, data.AlertRenotifyMinutes),
+		AlertEscalationChannels:  alertEscalationChannels,
+		AlertEscalationMinutes:   mergeNullInt64(currentJob.AlertEscalationMinutes, data.AlertEscalationMinutes),
+
+		Tags: tags,
+
+		HeartbeatToken:        heartbeatToken,
+		HeartbeatGraceSeconds: mergeNullInt64(currentJob.HeartbeatGraceSeconds, data.HeartbeatGraceSeconds),
+
+		TriggerToken:     triggerToken,
+		TriggerSecret:    triggerSecret,
+		TriggerRateLimit: mergeNullInt64(currentJob.TriggerRateLimit, data.TriggerRateLimit),
+
+		BreakerFailureThreshold: mergeNullInt64(currentJob.BreakerFailureThreshold, data.BreakerFailureThreshold),
+		BreakerProbeSeconds:     mergeNullInt64(currentJob.BreakerProbeSeconds, data.BreakerProbeSeconds),
+
+		Body:               mergeNullString(currentJob.Body, data.Body),
+		RetryAttempts:      mergeNullInt64(currentJob.RetryAttempts, data.RetryAttempts),
+		RetryDelaySeconds:  mergeNullInt64(currentJob.RetryDelaySeconds, data.RetryDelaySeconds),
+		RetryAfterHostWide: retryAfterHostWide,
+		FailOnErrorStatus:  failOnErrorStatus,
+
+		ID: jobID,
 	})
 	if err != nil {
-		log.Println("error updating job", err)
+		slog.ErrorContext(r.Context(), "failed to update job", "job_id", jobID, "error", err)
 		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to update job")
 		return
 	}
 
-	utils.WriteJsonResponse(w, http.StatusOK, fromDBJob(updatedJob))
+	// a job turned into a heartbeat gets a full period for its first ping
+	if jobType == scheduler.JobTypeHeartbeat && currentJob.Type != scheduler.JobTypeHeartbeat {
+		updatedJob.NextRunAt = sql.NullTime{
+			Time:  scheduler.HeartbeatDeadline(time.Now(), updatedJob.IntervalSeconds, updatedJob.HeartbeatGraceSeconds.Int64),
+			Valid: true,
+		}
+		err = js.db.UpdateJobNextRun(context.Background(), db.UpdateJobNextRunParams{
+			ID:        jobID,
+			NextRunAt: updatedJob.NextRunAt,
+		})
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusInternalServerError, "failed to update job")
+			return
+		}
+	}
+
+	// without its breaker, a tripped job goes back to its normal schedule;
+	// the next run closes the breaker
+	if !updatedJob.BreakerFailureThreshold.Valid && updatedJob.BreakerState == scheduler.BreakerOpen {
+		updatedJob.NextRunAt = sql.NullTime{
+			Time:  time.Now().Add(time.Duration(updatedJob.IntervalSeconds) * time.Second).UTC(),
+			Valid: true,
+		}
+		err = js.db.UpdateJobNextRun(context.Background(), db.UpdateJobNextRunParams{
+			ID:        jobID,
+			NextRunAt: updatedJob.NextRunAt,
+		})
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusInternalServerError, "failed to update job")
+			return
+		}
+	}
+
+	response := fromDBJob(updatedJob)
+	js.events.Publish(events.JobUpdated, updatedJob, jobEventData(response))
+
+	utils.WriteJsonResponse(w, http.StatusOK, response)
+}
+
+// jobEventData is the payload of job.created and job.updated: the job as
+// the API returns it, minus the ping and trigger tokens, which would let
+// webhook subscribers and event stream readers fire the job.
+func jobEventData(response dto.CreateJobResponse) dto.CreateJobResponse {
+	response.HeartbeatToken = nil
+	response.TriggerToken = nil
+	return response
 }
 
 func fromDBJob(dbJob db.Job) dto.CreateJobResponse {
 	response := dto.CreateJobResponse{
 		Id:              dbJob.ID,
 		Name:            dbJob.Name,
+		Type:            dbJob.Type,
 		Method:          dbJob.Method.(string),
 		Url:             dbJob.Url,
 		IntervalSeconds: dbJob.IntervalSeconds,
+		TimeoutSeconds:  dbJob.TimeoutSeconds,
 	}
 
 	if dbJob.Headers.Valid && dbJob.Headers.String != "" {
@@ -179,5 +693,264 @@ func fromDBJob(dbJob db.Job) dto.CreateJobResponse {
 		response.Active = &dbJob.Active.Bool
 	}
 
+	response.DNSRecordType = stringPtr(dbJob.DnsRecordType)
+	response.DNSExpected = stringPtr(dbJob.DnsExpected)
+	response.DNSServer = stringPtr(dbJob.DnsServer)
+	response.GRPCService = stringPtr(dbJob.GrpcService)
+	response.GRPCTLS = dbJob.GrpcTls.Valid && dbJob.GrpcTls.Bool
+
+	response.SendMessage = stringPtr(dbJob.SendMessage)
+	response.ExpectMessage = stringPtr(dbJob.ExpectMessage)
+
+	response.TrackChanges = dbJob.TrackChanges.Valid && dbJob.TrackChanges.Bool
+	response.TrackJSONPath = stringPtr(dbJob.TrackJsonPath)
+
+	response.MaxBodyBytes = dbJob.MaxBodyBytes
+
+	response.RetentionKeepRuns = int64Ptr(dbJob.RetentionKeepRuns)
+	response.RetentionKeepDays = int64Ptr(dbJob.RetentionKeepDays)
+	response.RetentionKeepFailuresDays = int64Ptr(dbJob.RetentionKeepFailuresDays)
+	if dbJob.RedactHeaders.Valid {
+		json.Unmarshal([]byte(dbJob.RedactHeaders.String), &response.RedactHeaders)
+	}
+	if dbJob.Tags.Valid {
+		json.Unmarshal([]byte(dbJob.Tags.String), &response.Tags)
+	}
+
+	response.HeartbeatToken = stringPtr(dbJob.HeartbeatToken)
+	response.HeartbeatGraceSeconds = int64Ptr(dbJob.HeartbeatGraceSeconds)
+	if dbJob.HeartbeatStartedAt.Valid {
+		response.HeartbeatStartedAt = &dbJob.HeartbeatStartedAt.Time
+	}
+
+	response.TriggerToken = stringPtr(dbJob.TriggerToken)
+	response.TriggerRateLimit = int64Ptr(dbJob.TriggerRateLimit)
+	if dbJob.TriggerSecret.Valid {
+		masked := maskedPassword
+		response.TriggerSecret = &masked
+	}
+
+	response.Body = stringPtr(dbJob.Body)
+	response.RetryAttempts = int64Ptr(dbJob.RetryAttempts)
+	response.RetryDelaySeconds = int64Ptr(dbJob.RetryDelaySeconds)
+	response.RetryAfterHostWide = dbJob.RetryAfterHostWide.Valid && dbJob.RetryAfterHostWide.Bool
+	response.FailOnErrorStatus = dbJob.FailOnErrorStatus.Valid && dbJob.FailOnErrorStatus.Bool
+
+	response.BreakerFailureThreshold = int64Ptr(dbJob.BreakerFailureThreshold)
+	response.BreakerProbeSeconds = int64Ptr(dbJob.BreakerProbeSeconds)
+	response.BreakerState = dbJob.BreakerState
+	response.BreakerFailures = dbJob.BreakerFailures
+	if dbJob.BreakerOpenedAt.Valid {
+		response.BreakerOpenedAt = &dbJob.BreakerOpenedAt.Time
+	}
+
+	if dbJob.AlertChannels.Valid {
+		json.Unmarshal([]byte(dbJob.AlertChannels.String), &response.AlertChannels)
+	}
+	response.AlertConsecutiveFailures = int64Ptr(dbJob.AlertConsecutiveFailures)
+	response.AlertFailureRate = int64Ptr(dbJob.AlertFailureRate)
+	response.AlertFailureWindow = dbJob.AlertFailureWindow
+	response.AlertRenotifyMinutes = int64Ptr(dbJob.AlertRenotifyMinutes)
+	if dbJob.AlertEscalationChannels.Valid {
+		json.Unmarshal([]byte(dbJob.AlertEscalationChannels.String), &response.AlertEscalationChannels)
+	}
+	response.AlertEscalationMinutes = int64Ptr(dbJob.AlertEscalationMinutes)
+
+	if dbJob.Steps.Valid {
+		json.Unmarshal([]byte(dbJob.Steps.String), &response.Steps)
+	}
+
+	response.TLSClientCertFile = stringPtr(dbJob.TlsClientCertFile)
+	response.TLSClientKeyFile = stringPtr(dbJob.TlsClientKeyFile)
+	response.TLSCAFile = stringPtr(dbJob.TlsCaFile)
+	response.TLSServerName = stringPtr(dbJob.TlsServerName)
+	response.TLSMinVersion = stringPtr(dbJob.TlsMinVersion)
+	response.TLSInsecureSkipVerify = dbJob.TlsInsecureSkipVerify.Valid && dbJob.TlsInsecureSkipVerify.Bool
+
+	response.ProxyURL = stringPtr(dbJob.ProxyUrl)
+	response.FollowRedirects = !dbJob.FollowRedirects.Valid || dbJob.FollowRedirects.Bool
+	response.MaxRedirects = dbJob.MaxRedirects.Int64
+	if dbJob.Resolve.Valid {
+		json.Unmarshal([]byte(dbJob.Resolve.String), &response.Resolve)
+	}
+
+	response.CertExpiryDays = int64Ptr(dbJob.CertExpiryDays)
+	response.CertExpiryAction = dbJob.CertExpiryAction
+
+	return response
+}
+
+func validateTracking(jobType string, trackChanges bool, jsonPath string) error {
+	if trackChanges && jobType != scheduler.JobTypeHTTP {
+		return fmt.Errorf("track_changes is only supported for http jobs")
+	}
+
+	if jsonPath != "" {
+		if err := scheduler.ValidateJSONPath(jsonPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateConnectionSettings(proxyURL string, resolve []string) error {
+	if proxyURL != "" {
+		if _, err := scheduler.ParseProxyURL(proxyURL); err != nil {
+			return err
+		}
+	}
+
+	if _, err := scheduler.ParseResolve(resolve); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func encodeSteps(jobType string, steps []dto.Step) (sql.NullString, error) {
+	if jobType != scheduler.JobTypeSteps {
+		if len(steps) > 0 {
+			return sql.NullString{}, fmt.Errorf("steps are only allowed on steps jobs")
+		}
+		return sql.NullString{}, nil
+	}
+
+	if len(steps) == 0 {
+		return sql.NullString{}, fmt.Errorf("steps jobs need at least one step")
+	}
+
+	encoded, err := json.Marshal(steps)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+// encodeAlertChannels checks that every channel exists and stores the IDs
+// as a JSON list.
+func (js JobsResource) encodeAlertChannels(ctx context.Context, channelIDs []int64) (sql.NullString, error) {
+	if len(channelIDs) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	channels, err := js.db.GetNotificationChannelsByIDs(ctx, channelIDs)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	found := make(map[int64]bool, len(channels))
+	for _, channel := range channels {
+		found[channel.ID] = true
+	}
+	for _, id := range channelIDs {
+		if !found[id] {
+			return sql.NullString{}, fmt.Errorf("notification channel %d does not exist", id)
+		}
+	}
+
+	encoded, err := json.Marshal(channelIDs)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+func encodeStringList(entries []string) (sql.NullString, error) {
+	if len(entries) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	encoded, err := json.Marshal(entries)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+func fromDBJobRun(dbRun db.JobRun) dto.JobRunResponse {
+	response := dto.JobRunResponse{
+		Id:     dbRun.ID,
+		JobId:  dbRun.JobID,
+		Status: dbRun.Status.String,
+		Timings: dto.RunTimings{
+			DNSLookupMs:       int64Ptr(dbRun.DnsMs),
+			TCPConnectMs:      int64Ptr(dbRun.ConnectMs),
+			TLSHandshakeMs:    int64Ptr(dbRun.TlsMs),
+			TimeToFirstByteMs: int64Ptr(dbRun.TtfbMs),
+			TotalMs:           int64Ptr(dbRun.TotalMs),
+		},
+	}
+
+	response.ResponseCode = int64Ptr(dbRun.ResponseCode)
+	response.Error = stringPtr(dbRun.Error)
+	response.BodyHash = stringPtr(dbRun.BodyHash)
+	response.Changed = dbRun.Changed.Valid && dbRun.Changed.Bool
+	response.ResponseSize = int64Ptr(dbRun.ResponseSize)
+	response.ReplayOfRunId = int64Ptr(dbRun.ReplayOfRunID)
+	response.ResponseTruncated = dbRun.ResponseTruncated.Valid && dbRun.ResponseTruncated.Bool
+
+	if dbRun.StartedAt.Valid {
+		response.StartedAt = &dbRun.StartedAt.Time
+	}
+
+	if dbRun.FinishedAt.Valid {
+		response.FinishedAt = &dbRun.FinishedAt.Time
+	}
+
 	return response
 }
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// mergeNullString applies an optional update: nil keeps the current value
+// and an empty string clears it.
+func mergeNullString(current sql.NullString, update *string) sql.NullString {
+	if update == nil {
+		return current
+	}
+	return nullString(*update)
+}
+
+func nullInt64(i *int64) sql.NullInt64 {
+	if i == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *i, Valid: true}
+}
+
+// nullPositiveInt64 stores zero and below as NULL.
+func nullPositiveInt64(i *int64) sql.NullInt64 {
+	if i == nil || *i <= 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *i, Valid: true}
+}
+
+// mergeNullInt64 applies an optional update: nil keeps the current value
+// and zero clears it.
+func mergeNullInt64(current sql.NullInt64, update *int64) sql.NullInt64 {
+	if update == nil {
+		return current
+	}
+	return nullPositiveInt64(update)
+}
+
+func int64Ptr(i sql.NullInt64) *int64 {
+	if !i.Valid {
+		return nil
+	}
+	return &i.Int64
+}
+
+func stringPtr(s sql.NullString) *string {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	return &s.String
+}
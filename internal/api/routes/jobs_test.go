@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/storage"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newTestQueries returns queries on a fresh database in a temporary
+// directory.
+func newTestQueries(t *testing.T) *db.Queries {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+	queries, database, err := storage.NewSQLiteDB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	return queries
+}
+
+// createTestJob stores an active http job from params, filling in a name,
+// method, URL, interval and timeout when they are missing.
+func createTestJob(t *testing.T, queries *db.Queries, params db.CreateJobParams) db.Job {
+	t.Helper()
+
+	if params.Name == "" {
+		params.Name = "test"
+	}
+	if params.Method == nil {
+		params.Method = "GET"
+	}
+	if params.Type == "" {
+		params.Type = "http"
+	}
+	if params.Url == "" {
+		params.Url = "https://example.com"
+	}
+	if params.IntervalSeconds == 0 {
+		params.IntervalSeconds = 60
+	}
+	if params.TimeoutSeconds == 0 {
+		params.TimeoutSeconds = 5
+	}
+	if !params.Active.Valid {
+		params.Active = sql.NullBool{Bool: true, Valid: true}
+	}
+
+	job, err := queries.CreateJob(context.Background(), params)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	return job
+}
+
+func TestUpdateJobClientCertificatePair(t *testing.T) {
+	queries := newTestQueries(t)
+	router := NewJobResource(queries, events.NewBus()).Routes()
+
+	tests := []struct {
+		name       string
+		cert, key  string
+		body       string
+		wantStatus int
+	}{
+		{"key without certificate", "", "", `{"tls_client_key_file":"/certs/client.key"}`, http.StatusBadRequest},
+		{"certificate without key", "", "", `{"tls_client_cert_file":"/certs/client.pem"}`, http.StatusBadRequest},
+		{"both", "", "", `{"tls_client_cert_file":"/certs/client.pem","tls_client_key_file":"/certs/client.key"}`, http.StatusOK},
+		{"clearing the key only", "/certs/client.pem", "/certs/client.key", `{"tls_client_key_file":""}`, http.StatusBadRequest},
+		{"clearing both", "/certs/client.pem", "/certs/client.key", `{"tls_client_cert_file":"","tls_client_key_file":""}`, http.StatusOK},
+		{"replacing the certificate", "/certs/client.pem", "/certs/client.key", `{"tls_client_cert_file":"/certs/other.pem"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := createTestJob(t, queries, db.CreateJobParams{
+				TlsClientCertFile: nullString(tt.cert),
+				TlsClientKeyFile:  nullString(tt.key),
+			})
+
+			request := httptest.NewRequest(http.MethodPatch, "/"+strconv.FormatInt(job.ID, 10), strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+
+			stored, err := queries.GetJobByID(context.Background(), job.ID)
+			if err != nil {
+				t.Fatalf("reload job: %v", err)
+			}
+			if stored.TlsClientCertFile.Valid != stored.TlsClientKeyFile.Valid {
+				t.Errorf("stored certificate %v without a matching key %v", stored.TlsClientCertFile, stored.TlsClientKeyFile)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/scheduler"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PingResource receives the unauthenticated pings of heartbeat jobs; the
+// token in the URL is the credential.
+type PingResource struct {
+	db        *db.Queries
+	scheduler *scheduler.Scheduler
+}
+
+func NewPingResource(database *db.Queries, scheduler *scheduler.Scheduler) *PingResource {
+	return &PingResource{
+		db:        database,
+		scheduler: scheduler,
+	}
+}
+
+func (pr PingResource) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodHead} {
+		r.MethodFunc(method, "/{token}", pr.ping(scheduler.PingSuccess))
+		r.MethodFunc(method, "/{token}/start", pr.ping(scheduler.PingStart))
+		r.MethodFunc(method, "/{token}/fail", pr.ping(scheduler.PingFail))
+	}
+	return r
+}
+
+func (pr PingResource) ping(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := pr.db.GetJobByHeartbeatToken(r.Context(), sql.NullString{String: chi.URLParam(r, "token"), Valid: true})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				utils.WriteJsonError(w, http.StatusNotFound, "unknown ping token")
+				return
+			}
+			utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch job")
+			return
+		}
+
+		err = pr.scheduler.RecordPing(r.Context(), job, kind)
+		switch {
+		case errors.Is(err, scheduler.ErrJobInactive):
+			utils.WriteJsonError(w, http.StatusConflict, err.Error())
+			return
+		case errors.Is(err, scheduler.ErrJobRunning):
+			// the missed-deadline run is quick; clients such as curl --retry
+			// try again on 503
+			w.Header().Set("Retry-After", "1")
+			utils.WriteJsonError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		case err != nil:
+			slog.ErrorContext(r.Context(), "failed to record ping", "job_id", job.ID, "error", err)
+			utils.WriteJsonError(w, http.StatusInternalServerError, "failed to record ping")
+			return
+		}
+
+		utils.WriteJsonResponse(w, http.StatusOK, "ok")
+	}
+}
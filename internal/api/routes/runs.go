@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/scheduler"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RunsResource struct {
+	db *db.Queries
+}
+
+func NewRunResource(database *db.Queries) *RunsResource {
+	return &RunsResource{
+		db: database,
+	}
+}
+
+func (rs RunsResource) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/{id}", rs.GetRun)
+	r.Get("/{id}/response", rs.GetRunResponse)
+	return r
+}
+
+func (rs RunsResource) GetRun(w http.ResponseWriter, r *http.Request) {
+	runIDStr := chi.URLParam(r, "id")
+	runID, err := strconv.ParseInt(runIDStr, 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid run ID")
+		return
+	}
+
+	run, err := rs.db.GetJobRunByID(context.Background(), runID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.WriteJsonError(w, http.StatusNotFound, "run not found")
+			return
+		}
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch run")
+		return
+	}
+
+	steps, err := rs.db.GetJobRunSteps(context.Background(), runID)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch run steps")
+		return
+	}
+
+	response := fromDBJobRun(run)
+	response.BodyDiff = stringPtr(run.BodyDiff)
+	if run.ResponseHeaders.Valid {
+		json.Unmarshal([]byte(run.ResponseHeaders.String), &response.ResponseHeaders)
+	}
+	for _, step := range steps {
+		response.Steps = append(response.Steps, dto.JobRunStepResponse{
+			Position:     step.Position,
+			Name:         step.Name,
+			Status:       step.Status,
+			ResponseCode: int64Ptr(step.ResponseCode),
+			DurationMs:   int64Ptr(step.DurationMs),
+			Error:        stringPtr(step.Error),
+		})
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, response)
+}
+
+// GetRunResponse writes the stored response body as it was received, with
+// the original Content-Type. Compressed bodies are passed through when the
+// client accepts gzip and decompressed otherwise.
+func (rs RunsResource) GetRunResponse(w http.ResponseWriter, r *http.Request) {
+	runIDStr := chi.URLParam(r, "id")
+	runID, err := strconv.ParseInt(runIDStr, 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid run ID")
+		return
+	}
+
+	run, err := rs.db.GetJobRunByID(context.Background(), runID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.WriteJsonError(w, http.StatusNotFound, "run not found")
+			return
+		}
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch run")
+		return
+	}
+
+	if !run.ResponseEncoding.Valid {
+		utils.WriteJsonError(w, http.StatusNotFound, "no response stored for this run")
+		return
+	}
+
+	var headers http.Header
+	if run.ResponseHeaders.Valid {
+		json.Unmarshal([]byte(run.ResponseHeaders.String), &headers)
+	}
+
+	contentType := headers.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"run-%d-response\"", run.ID))
+	if run.ResponseTruncated.Valid && run.ResponseTruncated.Bool {
+		w.Header().Set("X-Pulse-Truncated", "true")
+	}
+
+	body := run.ResponseBody
+	if run.ResponseEncoding.String == scheduler.EncodingGzip {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+		} else {
+			reader, err := gzip.NewReader(bytes.NewReader(body))
+			if err == nil {
+				body, err = io.ReadAll(reader)
+			}
+			if err != nil {
+				utils.WriteJsonError(w, http.StatusInternalServerError, "failed to decompress response")
+				return
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
@@ -0,0 +1,174 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/scheduler"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type statsWindow struct {
+	length time.Duration
+	step   time.Duration
+}
+
+var statsWindows = map[string]statsWindow{
+	"24h": {length: 24 * time.Hour, step: time.Hour},
+	"7d":  {length: 7 * 24 * time.Hour, step: 6 * time.Hour},
+	"30d": {length: 30 * 24 * time.Hour, step: 24 * time.Hour},
+}
+
+// GetJobStats summarises a job's runs over a window from the hourly
+// rollups. The window starts at the top of the hour, so it can cover up to
+// an hour more than requested.
+func (js JobsResource) GetJobStats(w http.ResponseWriter, r *http.Request) {
+	jobIDStr := chi.URLParam(r, "id")
+	jobID, err := strconv.ParseInt(jobIDStr, 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid job ID")
+		return
+	}
+
+	windowName := r.URL.Query().Get("window")
+	if windowName == "" {
+		windowName = "24h"
+	}
+	window, ok := statsWindows[windowName]
+	if !ok {
+		utils.WriteJsonError(w, http.StatusBadRequest, "window must be one of 24h, 7d, 30d")
+		return
+	}
+
+	if _, err := js.db.GetJobByID(context.Background(), jobID); err != nil {
+		if err == sql.ErrNoRows {
+			utils.WriteJsonError(w, http.StatusNotFound, "job not found")
+			return
+		}
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch job")
+		return
+	}
+
+	now := time.Now().UTC()
+	from := now.Add(-window.length).Truncate(time.Hour)
+
+	rollups, err := js.db.GetJobRollups(context.Background(), db.GetJobRollupsParams{
+		JobID:       jobID,
+		BucketStart: from.Unix(),
+	})
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch job stats")
+		return
+	}
+
+	histogram, err := js.db.GetJobLatencyHistogram(context.Background(), db.GetJobLatencyHistogramParams{
+		JobID:       jobID,
+		BucketStart: from.Unix(),
+	})
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch job stats")
+		return
+	}
+
+	response := dto.JobStatsResponse{
+		JobId:        jobID,
+		Window:       windowName,
+		From:         from,
+		To:           now,
+		StatusCounts: map[string]int64{},
+	}
+
+	step := int64(window.step.Seconds())
+	for start := from; !start.After(now); start = start.Add(window.step) {
+		response.Series = append(response.Series, dto.JobStatsBucket{
+			Start:        start,
+			StatusCounts: map[string]int64{},
+		})
+	}
+
+	var latencySum, latencyCount int64
+	seriesLatency := make([]struct{ sum, count int64 }, len(response.Series))
+
+	for _, rollup := range rollups {
+		response.TotalRuns += rollup.Runs
+		response.StatusCounts[rollup.Status] += rollup.Runs
+		latencySum += rollup.LatencySumMs
+		latencyCount += rollup.LatencyCount
+
+		index := (rollup.BucketStart - from.Unix()) / step
+		if index < 0 || index >= int64(len(response.Series)) {
+			continue
+		}
+		bucket := &response.Series[index]
+		bucket.Runs += rollup.Runs
+		bucket.StatusCounts[rollup.Status] += rollup.Runs
+		seriesLatency[index].sum += rollup.LatencySumMs
+		seriesLatency[index].count += rollup.LatencyCount
+	}
+
+	response.SuccessRate = ratio(response.StatusCounts["success"], response.TotalRuns, 1)
+	response.UptimePercent = uptime(response.StatusCounts, response.TotalRuns)
+	response.Latency.AvgMs = ratio(latencySum, latencyCount, 1)
+	response.Latency.P50Ms = percentile(histogram, 0.50)
+	response.Latency.P95Ms = percentile(histogram, 0.95)
+	response.Latency.P99Ms = percentile(histogram, 0.99)
+
+	for i := range response.Series {
+		bucket := &response.Series[i]
+		bucket.UptimePercent = uptime(bucket.StatusCounts, bucket.Runs)
+		bucket.AvgLatencyMs = ratio(seriesLatency[i].sum, seriesLatency[i].count, 1)
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, response)
+}
+
+// uptime counts warnings as up: the target answered, only a secondary
+// check such as certificate expiry tripped.
+func uptime(counts map[string]int64, total int64) *float64 {
+	return ratio(counts["success"]+counts["warning"], total, 100)
+}
+
+func ratio(part, total int64, scale float64) *float64 {
+	if total == 0 {
+		return nil
+	}
+	value := float64(part) / float64(total) * scale
+	return &value
+}
+
+// percentile estimates the p-th latency percentile by interpolating
+// linearly inside the histogram bucket that holds it.
+func percentile(histogram []db.GetJobLatencyHistogramRow, p float64) *float64 {
+	counts := make(map[int64]int64, len(histogram))
+	var total int64
+	for _, row := range histogram {
+		counts[row.LeMs] = row.Runs
+		total += row.Runs
+	}
+	if total == 0 {
+		return nil
+	}
+
+	rank := p * float64(total)
+	var cumulative int64
+	var lower int64
+	for _, upper := range scheduler.LatencyBucketsMs {
+		count := counts[upper]
+		if count > 0 && float64(cumulative+count) >= rank {
+			value := float64(lower) + float64(upper-lower)*(rank-float64(cumulative))/float64(count)
+			return &value
+		}
+		cumulative += count
+		lower = upper
+	}
+
+	// the percentile falls in the overflow bucket; report its lower bound
+	value := float64(lower)
+	return &value
+}
@@ -0,0 +1,152 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/scheduler"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name      string
+		histogram map[int64]int64
+		p         float64
+		want      float64
+	}{
+		{"inside a bucket", map[int64]int64{100: 10}, 0.50, 87.5},
+		{"top of a bucket", map[int64]int64{100: 10}, 0.99, 99.75},
+		{"first bucket", map[int64]int64{5: 10, scheduler.OverflowBucketMs: 10}, 0.50, 5},
+		{"between buckets", map[int64]int64{10: 50, 1000: 50}, 0.50, 10},
+		{"upper bucket", map[int64]int64{10: 50, 1000: 50}, 0.95, 975},
+		{"overflow", map[int64]int64{5: 10, scheduler.OverflowBucketMs: 10}, 0.99, 60000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var histogram []db.GetJobLatencyHistogramRow
+			for le, runs := range tt.histogram {
+				histogram = append(histogram, db.GetJobLatencyHistogramRow{LeMs: le, Runs: runs})
+			}
+
+			got := percentile(histogram, tt.p)
+			if got == nil || math.Abs(*got-tt.want) > 1e-9 {
+				t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+
+	if got := percentile(nil, 0.5); got != nil {
+		t.Errorf("percentile of no runs = %v, want none", *got)
+	}
+}
+
+func TestGetJobStats(t *testing.T) {
+	queries := newTestQueries(t)
+	router := NewJobResource(queries, events.NewBus()).Routes()
+	job := createTestJob(t, queries, db.CreateJobParams{})
+	ctx := context.Background()
+
+	currentHour := time.Now().UTC().Truncate(time.Hour)
+	record := func(bucket time.Time, status string, latencyMs int64) {
+		t.Helper()
+
+		params := db.UpsertJobRollupParams{JobID: job.ID, BucketStart: bucket.Unix(), Status: status}
+		if latencyMs > 0 {
+			params.LatencySumMs, params.LatencyCount = latencyMs, 1
+		}
+		if err := queries.UpsertJobRollup(ctx, params); err != nil {
+			t.Fatalf("store rollup: %v", err)
+		}
+		if latencyMs == 0 {
+			return
+		}
+		le := int64(scheduler.OverflowBucketMs)
+		for _, bound := range scheduler.LatencyBucketsMs {
+			if latencyMs <= bound {
+				le = bound
+				break
+			}
+		}
+		err := queries.UpsertJobLatencyRollup(ctx, db.UpsertJobLatencyRollupParams{JobID: job.ID, BucketStart: bucket.Unix(), LeMs: le})
+		if err != nil {
+			t.Fatalf("store latency rollup: %v", err)
+		}
+	}
+
+	previousHour := currentHour.Add(-time.Hour)
+	record(previousHour, "success", 100)
+	record(previousHour, "success", 300)
+	record(previousHour, "failed", 0)
+	record(currentHour, "warning", 50)
+	record(currentHour, "success", 1000)
+	// outside the window
+	record(currentHour.Add(-48*time.Hour), "failed", 0)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	recorder := get("/" + strconv.FormatInt(job.ID, 10) + "/stats")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body)
+	}
+	var response struct{ Data dto.JobStatsResponse }
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	stats := response.Data
+
+	if stats.Window != "24h" || stats.TotalRuns != 5 ||
+		stats.StatusCounts["success"] != 3 || stats.StatusCounts["warning"] != 1 || stats.StatusCounts["failed"] != 1 {
+		t.Errorf("stats = %s with %d runs %v, want 24h with 5 runs", stats.Window, stats.TotalRuns, stats.StatusCounts)
+	}
+	for name, got := range map[string]struct {
+		value *float64
+		want  float64
+	}{
+		"success rate":    {stats.SuccessRate, 0.6},
+		"uptime":          {stats.UptimePercent, 80},
+		"average":         {stats.Latency.AvgMs, 362.5},
+		"50th percentile": {stats.Latency.P50Ms, 100},
+	} {
+		if got.value == nil || math.Abs(*got.value-got.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, got.value, got.want)
+		}
+	}
+
+	if len(stats.Series) != 25 {
+		t.Fatalf("series has %d buckets, want 25", len(stats.Series))
+	}
+	for i, bucket := range stats.Series {
+		switch {
+		case bucket.Start.Equal(previousHour):
+			if bucket.Runs != 3 || bucket.AvgLatencyMs == nil || *bucket.AvgLatencyMs != 200 {
+				t.Errorf("previous hour = %d runs averaging %v ms, want 3 averaging 200", bucket.Runs, bucket.AvgLatencyMs)
+			}
+		case bucket.Start.Equal(currentHour):
+			if bucket.Runs != 2 || bucket.UptimePercent == nil || *bucket.UptimePercent != 100 {
+				t.Errorf("current hour = %d runs with uptime %v, want 2 with 100", bucket.Runs, bucket.UptimePercent)
+			}
+		case bucket.Runs != 0 || bucket.UptimePercent != nil:
+			t.Errorf("bucket %d = %d runs with uptime %v, want none", i, bucket.Runs, bucket.UptimePercent)
+		}
+	}
+
+	if recorder := get("/" + strconv.FormatInt(job.ID, 10) + "/stats?window=1y"); recorder.Code != http.StatusBadRequest {
+		t.Errorf("unknown window answered %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+	if recorder := get("/" + strconv.FormatInt(job.ID+1, 10) + "/stats"); recorder.Code != http.StatusNotFound {
+		t.Errorf("unknown job answered %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,217 @@
+package routes
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/scheduler"
+	"lucasbonna/pulse/internal/utils"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultTriggerRateLimit is how many triggers per minute a job accepts
+	// unless it sets trigger_rate_limit.
+	defaultTriggerRateLimit = 10
+
+	maxTriggerBodyBytes     = 64 * 1024
+	maxTriggerVariables     = 50
+	maxTriggerVariableBytes = 4096
+)
+
+var triggerVariableName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// TriggerResource lets external systems run jobs on demand. The token in
+// the URL authenticates the caller; jobs with a trigger secret also require
+// an HMAC signature of the body.
+type TriggerResource struct {
+	db        *db.Queries
+	scheduler *scheduler.Scheduler
+
+	mutex     sync.Mutex
+	limiters  map[int64]*rate.Limiter
+	lastSweep time.Time
+}
+
+func NewTriggerResource(database *db.Queries, scheduler *scheduler.Scheduler) *TriggerResource {
+	return &TriggerResource{
+		db:        database,
+		scheduler: scheduler,
+		limiters:  make(map[int64]*rate.Limiter),
+	}
+}
+
+func (tr *TriggerResource) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Post("/{token}", tr.TriggerJob)
+	return r
+}
+
+// TriggerJob starts a run of the job owning the token. The optional JSON
+// body {"variables": {"name": "value"}} fills {{name}} in the job's
+// templates.
+func (tr *TriggerResource) TriggerJob(w http.ResponseWriter, r *http.Request) {
+	job, err := tr.db.GetJobByTriggerToken(r.Context(), sql.NullString{String: chi.URLParam(r, "token"), Valid: true})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteJsonError(w, http.StatusNotFound, "unknown trigger token")
+			return
+		}
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch job")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTriggerBodyBytes+1))
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+	if len(body) > maxTriggerBodyBytes {
+		utils.WriteJsonError(w, http.StatusRequestEntityTooLarge, "body must be at most 64 KiB")
+		return
+	}
+
+	if job.TriggerSecret.Valid && !validTriggerSignature(job.TriggerSecret.String, body, r.Header) {
+		utils.WriteJsonError(w, http.StatusUnauthorized, "missing or invalid signature")
+		return
+	}
+
+	if job.Type == scheduler.JobTypeHeartbeat {
+		utils.WriteJsonError(w, http.StatusBadRequest, "heartbeat jobs cannot be triggered")
+		return
+	}
+
+	if !job.Active.Bool {
+		utils.WriteJsonError(w, http.StatusConflict, scheduler.ErrJobInactive.Error())
+		return
+	}
+
+	if !tr.allow(job) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(time.Minute.Seconds()/float64(triggerRateLimit(job))))))
+		utils.WriteJsonError(w, http.StatusTooManyRequests, "trigger rate limit exceeded")
+		return
+	}
+
+	var data dto.TriggerJobRequest
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &data); err != nil {
+			utils.WriteJsonError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+	}
+	if err := validateTriggerVariables(data.Variables); err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := tr.scheduler.Trigger(r.Context(), job, data.Variables); err != nil {
+		if errors.Is(err, scheduler.ErrJobRunning) {
+			utils.WriteJsonError(w, http.StatusConflict, err.Error())
+			return
+		}
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to trigger job")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "job triggered", "job_id", job.ID, "variables", len(data.Variables))
+	utils.WriteJsonResponse(w, http.StatusAccepted, dto.TriggerJobResponse{JobId: job.ID, Status: "triggered"})
+}
+
+// allow takes a token from the job's limiter, replacing the limiter when
+// the job's rate limit changed.
+func (tr *TriggerResource) allow(job db.Job) bool {
+	perMinute := triggerRateLimit(job)
+	limit := rate.Every(time.Minute / time.Duration(perMinute))
+
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+
+	tr.sweepLimiters()
+
+	limiter, ok := tr.limiters[job.ID]
+	if !ok || limiter.Limit() != limit {
+		limiter = rate.NewLimiter(limit, int(perMinute))
+		tr.limiters[job.ID] = limiter
+	}
+
+	return limiter.Allow()
+}
+
+// sweepLimiters drops, once a minute, the limiters that have refilled:
+// they behave like new ones, and those of deleted jobs are never used
+// again.
+func (tr *TriggerResource) sweepLimiters() {
+	now := time.Now()
+	if now.Sub(tr.lastSweep) < time.Minute {
+		return
+	}
+	tr.lastSweep = now
+
+	for jobID, limiter := range tr.limiters {
+		if limiter.TokensAt(now) >= float64(limiter.Burst()) {
+			delete(tr.limiters, jobID)
+		}
+	}
+}
+
+func triggerRateLimit(job db.Job) int64 {
+	if job.TriggerRateLimit.Valid && job.TriggerRateLimit.Int64 > 0 {
+		return job.TriggerRateLimit.Int64
+	}
+	return defaultTriggerRateLimit
+}
+
+// validTriggerSignature checks a "sha256=<hex>" HMAC of the body, sent as
+// X-Pulse-Signature or, for GitHub-style senders, X-Hub-Signature-256.
+func validTriggerSignature(secret string, body []byte, header http.Header) bool {
+	signature := header.Get("X-Pulse-Signature")
+	if signature == "" {
+		signature = header.Get("X-Hub-Signature-256")
+	}
+
+	encoded, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	received, err := hex.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(received, mac.Sum(nil))
+}
+
+func validateTriggerVariables(vars map[string]string) error {
+	if len(vars) > maxTriggerVariables {
+		return errors.New("at most 50 variables are allowed")
+	}
+	for name, value := range vars {
+		if !triggerVariableName.MatchString(name) {
+			return errors.New("variable names may only contain letters, digits, '_', '.' and '-'")
+		}
+		if len(value) > maxTriggerVariableBytes {
+			return errors.New("variable values must be at most 4096 bytes")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/config"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/scheduler"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestValidTriggerSignature(t *testing.T) {
+	body := []byte(`{"variables":{"id":"1"}}`)
+	signature := "sha256=8024fa3fa042e217c7c4ae808b266817578f0bf4779ffc35df00f8c777d1a7fe"
+
+	tests := []struct {
+		name   string
+		header http.Header
+		body   []byte
+		want   bool
+	}{
+		{"pulse header", http.Header{"X-Pulse-Signature": {signature}}, body, true},
+		{"github header", http.Header{"X-Hub-Signature-256": {signature}}, body, true},
+		{"pulse header wins", http.Header{"X-Pulse-Signature": {"sha256=00"}, "X-Hub-Signature-256": {signature}}, body, false},
+		{"other body", http.Header{"X-Pulse-Signature": {signature}}, []byte(`{}`), false},
+		{"missing", http.Header{}, body, false},
+		{"no prefix", http.Header{"X-Pulse-Signature": {strings.TrimPrefix(signature, "sha256=")}}, body, false},
+		{"not hex", http.Header{"X-Pulse-Signature": {"sha256=zz"}}, body, false},
+		{"uppercase hex", http.Header{"X-Pulse-Signature": {"sha256=" + strings.ToUpper(strings.TrimPrefix(signature, "sha256="))}}, body, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validTriggerSignature("tsec", tt.body, tt.header); got != tt.want {
+				t.Errorf("validTriggerSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTriggerVariables(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := range maxTriggerVariables + 1 {
+		tooMany[strings.Repeat("v", i+1)] = ""
+	}
+
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"valid", map[string]string{"order_id": "1", "env.name-2": "prod"}, false},
+		{"bad name", map[string]string{"order id": "1"}, true},
+		{"braces", map[string]string{"{{id}}": "1"}, true},
+		{"long value", map[string]string{"id": strings.Repeat("x", maxTriggerVariableBytes+1)}, true},
+		{"too many", tooMany, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTriggerVariables(tt.vars); (err != nil) != tt.wantErr {
+				t.Errorf("validateTriggerVariables error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTriggerRateLimit(t *testing.T) {
+	tr := NewTriggerResource(nil, nil)
+	job := db.Job{ID: 1, TriggerRateLimit: sql.NullInt64{Int64: 2, Valid: true}}
+
+	for i, want := range []bool{true, true, false} {
+		if got := tr.allow(job); got != want {
+			t.Fatalf("trigger %d allowed = %v, want %v", i+1, got, want)
+		}
+	}
+
+	job.TriggerRateLimit.Int64 = 3
+	if !tr.allow(job) {
+		t.Error("a raised rate limit did not take effect")
+	}
+	if got := tr.limiters[job.ID].Limit(); got != rate.Every(time.Minute/3) {
+		t.Errorf("limiter rate = %v after the limit changed", got)
+	}
+
+	tr.limiters[2] = rate.NewLimiter(1, 1)
+	tr.lastSweep = tr.lastSweep.AddDate(-1, 0, 0)
+	tr.allow(job)
+	if _, ok := tr.limiters[2]; ok {
+		t.Error("a refilled limiter survived the sweep")
+	}
+	if _, ok := tr.limiters[job.ID]; !ok {
+		t.Error("a limiter in use was swept")
+	}
+}
+
+func TestTriggerJob(t *testing.T) {
+	queries := newTestQueries(t)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(target.Close)
+	jobScheduler := scheduler.NewScheduler(queries, events.NewBus(), config.HostLimits{})
+	router := NewTriggerResource(queries, jobScheduler).Routes()
+
+	tests := []struct {
+		name       string
+		params     db.CreateJobParams
+		token      string
+		wantStatus int
+		wantRuns   int
+	}{
+		{"active", db.CreateJobParams{}, "active", http.StatusAccepted, 1},
+		{"paused", db.CreateJobParams{Active: sql.NullBool{Valid: true}}, "paused", http.StatusConflict, 0},
+		{"heartbeat", db.CreateJobParams{Type: scheduler.JobTypeHeartbeat}, "heartbeat", http.StatusBadRequest, 0},
+		{"unknown token", db.CreateJobParams{}, "other", http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params.Url = target.URL
+			tt.params.TriggerToken = sql.NullString{String: tt.name, Valid: true}
+			job := createTestJob(t, queries, tt.params)
+
+			request := httptest.NewRequest(http.MethodPost, "/"+tt.token, nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+
+			for deadline := time.Now().Add(5 * time.Second); jobScheduler.RunningCount() > 0; time.Sleep(10 * time.Millisecond) {
+				if time.Now().After(deadline) {
+					t.Fatal("the triggered run did not finish")
+				}
+			}
+			runs, err := queries.GetJobRuns(context.Background(), db.GetJobRunsParams{JobID: job.ID, Limit: 10})
+			if err != nil {
+				t.Fatalf("list runs: %v", err)
+			}
+			if len(runs) != tt.wantRuns {
+				t.Errorf("stored %d runs, want %d", len(runs), tt.wantRuns)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package routes
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/api/dto"
+	"lucasbonna/pulse/internal/api/middleware"
+	"lucasbonna/pulse/internal/utils"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhooksResource struct {
+	db         *db.Queries
+	validation *middleware.ValidationMiddleware
+}
+
+func NewWebhookResource(database *db.Queries) *WebhooksResource {
+	return &WebhooksResource{
+		db:         database,
+		validation: middleware.NewValidationMiddleware(),
+	}
+}
+
+func (ws WebhooksResource) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/", ws.GetWebhooks)
+	r.With(middleware.ValidateBody(ws.validation, dto.CreateWebhookRequest{})).Post("/", ws.CreateWebhook)
+	r.Get("/{id}", ws.GetWebhook)
+	r.With(middleware.ValidateBody(ws.validation, dto.UpdateWebhookRequest{})).Patch("/{id}", ws.UpdateWebhook)
+	r.Delete("/{id}", ws.DeleteWebhook)
+	r.Get("/{id}/deliveries", ws.GetWebhookDeliveries)
+	return r
+}
+
+func (ws WebhooksResource) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := ws.db.GetWebhookSubscriptions(context.Background())
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch webhooks")
+		return
+	}
+
+	responses := []dto.WebhookResponse{}
+	for _, subscription := range subscriptions {
+		responses = append(responses, fromDBWebhook(subscription))
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, responses)
+}
+
+// CreateWebhook registers a subscriber. The signing secret is returned only
+// in this response.
+func (ws WebhooksResource) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	data := middleware.GetValidatedData[dto.CreateWebhookRequest](r)
+
+	if !validWebhookURL(data.URL) {
+		utils.WriteJsonError(w, http.StatusBadRequest, "url must be an http(s) URL")
+		return
+	}
+
+	secret := data.Secret
+	if secret == "" {
+		secret = randomToken(32)
+	}
+
+	events, err := encodeStringList(data.Events)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	active := true
+	if data.Active != nil {
+		active = *data.Active
+	}
+
+	subscription, err := ws.db.CreateWebhookSubscription(context.Background(), db.CreateWebhookSubscriptionParams{
+		Url:       data.URL,
+		Secret:    secret,
+		Events:    events.String,
+		Active:    active,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+
+	response := fromDBWebhook(subscription)
+	response.Secret = subscription.Secret
+	utils.WriteJsonResponse(w, http.StatusOK, response)
+}
+
+func (ws WebhooksResource) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := ws.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, fromDBWebhook(subscription))
+}
+
+func (ws WebhooksResource) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := ws.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	data := middleware.GetValidatedData[dto.UpdateWebhookRequest](r)
+
+	params := db.UpdateWebhookSubscriptionParams{
+		Url:    subscription.Url,
+		Secret: subscription.Secret,
+		Events: subscription.Events,
+		Active: subscription.Active,
+		ID:     subscription.ID,
+	}
+
+	if data.URL != "" {
+		if !validWebhookURL(data.URL) {
+			utils.WriteJsonError(w, http.StatusBadRequest, "url must be an http(s) URL")
+			return
+		}
+		params.Url = data.URL
+	}
+	if data.Events != nil {
+		events, err := encodeStringList(data.Events)
+		if err != nil {
+			utils.WriteJsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		params.Events = events.String
+	}
+	if data.Secret != "" {
+		params.Secret = data.Secret
+	}
+	if data.Active != nil {
+		params.Active = *data.Active
+	}
+
+	updated, err := ws.db.UpdateWebhookSubscription(context.Background(), params)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to update webhook")
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, fromDBWebhook(updated))
+}
+
+// DeleteWebhook removes a subscriber together with its delivery log.
+func (ws WebhooksResource) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	subscriptionID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid webhook ID")
+		return
+	}
+
+	deleted, err := ws.db.DeleteWebhookSubscription(context.Background(), subscriptionID)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to delete webhook")
+		return
+	}
+	if deleted == 0 {
+		utils.WriteJsonError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+
+	if err := ws.db.DeleteWebhookDeliveriesBySubscription(context.Background(), subscriptionID); err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to delete webhook deliveries")
+		return
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, "webhook deleted")
+}
+
+// GetWebhookDeliveries returns the delivery log of a subscriber, newest
+// first.
+func (ws WebhooksResource) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := ws.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	limit := int64(50)
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit < 1 || limit > 500 {
+			utils.WriteJsonError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+	}
+
+	deliveries, err := ws.db.GetWebhookDeliveries(context.Background(), db.GetWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          limit,
+	})
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch webhook deliveries")
+		return
+	}
+
+	responses := []dto.WebhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		responses = append(responses, fromDBWebhookDelivery(delivery))
+	}
+
+	utils.WriteJsonResponse(w, http.StatusOK, responses)
+}
+
+func (ws WebhooksResource) loadWebhook(w http.ResponseWriter, r *http.Request) (db.WebhookSubscription, bool) {
+	subscriptionID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJsonError(w, http.StatusBadRequest, "invalid webhook ID")
+		return db.WebhookSubscription{}, false
+	}
+
+	subscription, err := ws.db.GetWebhookSubscription(context.Background(), subscriptionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteJsonError(w, http.StatusNotFound, "webhook not found")
+			return db.WebhookSubscription{}, false
+		}
+		utils.WriteJsonError(w, http.StatusInternalServerError, "failed to fetch webhook")
+		return db.WebhookSubscription{}, false
+	}
+
+	return subscription, true
+}
+
+func validWebhookURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// randomToken returns size random bytes, hex encoded.
+func randomToken(size int) string {
+	token := make([]byte, size)
+	rand.Read(token)
+	return hex.EncodeToString(token)
+}
+
+func fromDBWebhook(subscription db.WebhookSubscription) dto.WebhookResponse {
+	events := []string{}
+	json.Unmarshal([]byte(subscription.Events), &events)
+
+	return dto.WebhookResponse{
+		Id:        subscription.ID,
+		URL:       subscription.Url,
+		Events:    events,
+		Active:    subscription.Active,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+func fromDBWebhookDelivery(delivery db.WebhookDelivery) dto.WebhookDeliveryResponse {
+	response := dto.WebhookDeliveryResponse{
+		Id:        delivery.ID,
+		EventId:   delivery.EventID,
+		EventType: delivery.EventType,
+		Status:    delivery.Status,
+		Attempts:  delivery.Attempts,
+		Payload:   json.RawMessage(delivery.Payload),
+		CreatedAt: delivery.CreatedAt,
+	}
+
+	response.ResponseCode = int64Ptr(delivery.ResponseCode)
+	response.Error = stringPtr(delivery.Error)
+
+	if delivery.NextAttemptAt.Valid {
+		response.NextAttemptAt = &delivery.NextAttemptAt.Time
+	}
+	if delivery.LastAttemptAt.Valid {
+		response.LastAttemptAt = &delivery.LastAttemptAt.Time
+	}
+
+	return response
+}
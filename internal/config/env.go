@@ -1,8 +1,12 @@
 package config
 
 import (
-	"log"
+	"log/slog"
 	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -10,6 +14,46 @@ import (
 type Env struct {
 	Port  string
 	Token string
+	// MetricsPort serves /metrics without authentication; "0" disables it.
+	MetricsPort string
+	// TracesExporter is otlp, stdout or none (the default).
+	TracesExporter string
+	// LogLevel is debug, info, warn or error; LogFormat is text or json.
+	LogLevel  string
+	LogFormat string
+
+	Retention  Retention
+	HostLimits HostLimits
+}
+
+// Retention is the global run history policy. Jobs can override the three
+// limits; a zero limit is disabled.
+type Retention struct {
+	KeepRuns         int64
+	KeepDays         int64
+	KeepFailuresDays int64
+	JanitorInterval  time.Duration
+	VacuumInterval   time.Duration
+}
+
+// HostLimits throttle the requests Pulse sends to each target host. The
+// first rule whose pattern matches a host name applies to it, otherwise
+// the defaults do. Zero values mean unlimited.
+type HostLimits struct {
+	Default HostLimit
+	Rules   []HostLimitRule
+}
+
+type HostLimit struct {
+	RequestsPerSecond float64
+	MaxConcurrent     int64
+}
+
+// HostLimitRule applies its limits to host names matching Pattern, a
+// path.Match pattern such as "api.example.com" or "*.example.com".
+type HostLimitRule struct {
+	Pattern string
+	HostLimit
 }
 
 func InitEnvs() *Env {
@@ -19,14 +63,136 @@ func InitEnvs() *Env {
 	token := os.Getenv("TOKEN")
 
 	if port == "" {
-		port = ":8080" // default
+		port = "8080" // default
 	}
+	// accept both "8080" and ":8080"
+	port = strings.TrimPrefix(port, ":")
+
+	metricsPort := strings.TrimPrefix(os.Getenv("METRICS_PORT"), ":")
+	if metricsPort == "" {
+		metricsPort = "9090"
+	}
+
 	if token == "" {
-		log.Fatal("TOKEN environment variable is required")
+		fatal("TOKEN environment variable is required")
 	}
 
 	return &Env{
-		Port:  port,
-		Token: token,
+		Port:        port,
+		Token:       token,
+		MetricsPort: metricsPort,
+
+		TracesExporter: os.Getenv("OTEL_TRACES_EXPORTER"),
+
+		LogLevel:  envString("LOG_LEVEL", "info"),
+		LogFormat: envString("LOG_FORMAT", "text"),
+
+		Retention: Retention{
+			KeepRuns:         envInt("RETENTION_KEEP_RUNS", 1000),
+			KeepDays:         envInt("RETENTION_KEEP_DAYS", 30),
+			KeepFailuresDays: envInt("RETENTION_KEEP_FAILURES_DAYS", 90),
+			JanitorInterval:  envDuration("JANITOR_INTERVAL", 10*time.Minute),
+			VacuumInterval:   envDuration("JANITOR_VACUUM_INTERVAL", 24*time.Hour),
+		},
+
+		HostLimits: HostLimits{
+			Default: HostLimit{
+				RequestsPerSecond: envFloat("HOST_RATE_LIMIT", 0),
+				MaxConcurrent:     envInt("HOST_MAX_CONCURRENT", 0),
+			},
+			Rules: envHostLimitRules("HOST_LIMITS"),
+		},
+	}
+}
+
+func envString(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envInt(name string, fallback int64) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed < 0 {
+		fatal(name + " must be a non-negative integer")
+	}
+	return parsed
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		fatal(name + " must be a duration like 10m or 24h")
 	}
+	return parsed
+}
+
+func envFloat(name string, fallback float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 {
+		fatal(name + " must be a non-negative number")
+	}
+	return parsed
+}
+
+// envHostLimitRules reads comma-separated "pattern=rate/concurrent" rules,
+// e.g. "api.example.com=5/2,*.internal=0.5". The concurrency part is
+// optional and either limit may be 0 for unlimited.
+func envHostLimitRules(name string) []HostLimitRule {
+	var rules []HostLimitRule
+
+	for entry := range strings.SplitSeq(os.Getenv(name), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		invalid := name + ` entries must look like "api.example.com=5/2" (requests per second/max concurrent)`
+
+		pattern, limits, ok := strings.Cut(entry, "=")
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if _, err := path.Match(pattern, ""); !ok || pattern == "" || err != nil {
+			fatal(invalid)
+		}
+
+		rateStr, concurrentStr, _ := strings.Cut(limits, "/")
+		rule := HostLimitRule{Pattern: pattern}
+
+		var err error
+		rule.RequestsPerSecond, err = strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+		if err != nil || rule.RequestsPerSecond < 0 {
+			fatal(invalid)
+		}
+		if concurrentStr != "" {
+			rule.MaxConcurrent, err = strconv.ParseInt(strings.TrimSpace(concurrentStr), 10, 64)
+			if err != nil || rule.MaxConcurrent < 0 {
+				fatal(invalid)
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+func fatal(msg string) {
+	slog.Error(msg)
+	os.Exit(1)
 }
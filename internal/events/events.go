@@ -0,0 +1,112 @@
+package events
+
+import (
+	"encoding/json"
+	"lucasbonna/pulse/db"
+	"sync"
+	"time"
+)
+
+const (
+	RunStarted   = "run.started"
+	RunSucceeded = "run.succeeded"
+	RunFailed    = "run.failed"
+	JobCreated   = "job.created"
+	JobUpdated   = "job.updated"
+	JobDeleted   = "job.deleted"
+
+	BreakerOpened     = "job.breaker_opened"
+	BreakerHalfOpened = "job.breaker_half_opened"
+	BreakerClosed     = "job.breaker_closed"
+)
+
+// Types lists every event type that can be published.
+var Types = []string{
+	RunStarted, RunSucceeded, RunFailed,
+	JobCreated, JobUpdated, JobDeleted,
+	BreakerOpened, BreakerHalfOpened, BreakerClosed,
+}
+
+// Event is one thing that happened in Pulse. IDs increase monotonically and
+// are seeded from the clock at startup, so they keep increasing across
+// restarts.
+type Event struct {
+	ID      int64     `json:"id"`
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	JobID   int64     `json:"job_id,omitempty"`
+	JobTags []string  `json:"job_tags,omitempty"`
+	Data    any       `json:"data"`
+}
+
+// RunData is the payload of the run.* events.
+type RunData struct {
+	JobID        int64      `json:"job_id"`
+	JobName      string     `json:"job_name"`
+	RunID        int64      `json:"run_id"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	ResponseCode int        `json:"response_code,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	DurationMs   *int64     `json:"duration_ms,omitempty"`
+}
+
+// BreakerData is the payload of the job.breaker_* events.
+type BreakerData struct {
+	JobID               int64      `json:"job_id"`
+	JobName             string     `json:"job_name"`
+	State               string     `json:"state"`
+	PreviousState       string     `json:"previous_state"`
+	ConsecutiveFailures int64      `json:"consecutive_failures"`
+	NextProbeAt         *time.Time `json:"next_probe_at,omitempty"`
+}
+
+// Handler receives published events on the publisher's goroutine, while
+// the bus is locked so every handler sees events in ID order. It must hand
+// slow work off instead of blocking.
+type Handler func(Event)
+
+// Bus fans events out to every subscribed handler.
+type Bus struct {
+	mutex    sync.Mutex
+	nextID   int64
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		nextID: time.Now().UnixMicro(),
+	}
+}
+
+func (b *Bus) Subscribe(handler Handler) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish stamps an event about job with its ID and time and delivers it.
+func (b *Bus) Publish(eventType string, job db.Job, data any) {
+	var tags []string
+	if job.Tags.Valid {
+		json.Unmarshal([]byte(job.Tags.String), &tags)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.nextID++
+	event := Event{
+		ID:      b.nextID,
+		Type:    eventType,
+		Time:    time.Now().UTC(),
+		JobID:   job.ID,
+		JobTags: tags,
+		Data:    data,
+	}
+
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+}
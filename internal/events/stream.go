@@ -0,0 +1,113 @@
+package events
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// subscriberBuffer is how many events a live subscriber may fall behind
+// before it is disconnected.
+const subscriberBuffer = 256
+
+// EventsMissed is sent to a resuming client when some of the events it
+// missed are no longer buffered.
+const EventsMissed = "events.missed"
+
+// Stream keeps the most recent events in a ring buffer and fans new ones out
+// to live subscribers, so clients can resume from the last event they saw.
+type Stream struct {
+	mutex  sync.Mutex
+	buffer []Event
+	next   int
+	full   bool
+	// lastID is the ID of the latest event appended, or of the last one
+	// published before the stream subscribed to the bus
+	lastID      int64
+	subscribers map[chan Event]struct{}
+}
+
+// NewStream subscribes a stream holding up to size events to bus.
+func NewStream(bus *Bus, size int) *Stream {
+	s := &Stream{
+		buffer:      make([]Event, size),
+		subscribers: make(map[chan Event]struct{}),
+	}
+
+	bus.mutex.Lock()
+	s.lastID = bus.nextID
+	bus.mutex.Unlock()
+
+	bus.Subscribe(s.append)
+	return s
+}
+
+// Subscribe returns the buffered events newer than afterID, and a channel
+// that receives every later event. missed reports that events after
+// afterID were dropped from the buffer, or published before a restart, and
+// are not in backlog. The channel is closed when the subscriber falls too
+// far behind or unsubscribe is called.
+func (s *Stream) Subscribe(afterID int64) (backlog []Event, missed bool, live <-chan Event, unsubscribe func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if afterID > 0 {
+		buffered := s.buffered()
+		for _, event := range buffered {
+			if event.ID > afterID {
+				backlog = append(backlog, event)
+			}
+		}
+
+		// event IDs are consecutive, so a gap before the oldest event
+		// still available was lost
+		oldest := s.lastID + 1
+		if len(buffered) > 0 {
+			oldest = buffered[0].ID
+		}
+		missed = afterID+1 < oldest || afterID > s.lastID
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	s.subscribers[ch] = struct{}{}
+
+	unsubscribe = func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return backlog, missed, ch, unsubscribe
+}
+
+func (s *Stream) append(event Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.buffer[s.next] = event
+	s.lastID = event.ID
+	s.next = (s.next + 1) % len(s.buffer)
+	if s.next == 0 {
+		s.full = true
+	}
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			slog.Warn("event stream subscriber too slow, disconnecting", "event_id", event.ID)
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// buffered returns the buffered events, oldest first.
+func (s *Stream) buffered() []Event {
+	if !s.full {
+		return s.buffer[:s.next]
+	}
+	return append(append([]Event{}, s.buffer[s.next:]...), s.buffer[:s.next]...)
+}
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"lucasbonna/pulse/db"
+	"slices"
+	"testing"
+)
+
+// publish publishes n events and returns their IDs.
+func publish(bus *Bus, n int) []int64 {
+	var ids []int64
+	bus.Subscribe(func(event Event) { ids = append(ids, event.ID) })
+	for range n {
+		bus.Publish(RunSucceeded, db.Job{ID: 1}, nil)
+	}
+	return ids
+}
+
+func eventIDs(events []Event) []int64 {
+	var ids []int64
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestStreamResume(t *testing.T) {
+	bus := NewBus()
+	stream := NewStream(bus, 3)
+	ids := publish(bus, 5)
+
+	tests := []struct {
+		name        string
+		afterID     int64
+		wantBacklog []int64
+		wantMissed  bool
+	}{
+		{"new client", 0, nil, false},
+		{"one behind", ids[3], ids[4:], false},
+		{"as far back as the buffer goes", ids[1], ids[2:], false},
+		{"past the buffer", ids[0], ids[2:], true},
+		{"before a restart", 1, ids[2:], true},
+		{"up to date", ids[4], nil, false},
+		{"from another run", ids[4] + 1000, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backlog, missed, _, unsubscribe := stream.Subscribe(tt.afterID)
+			defer unsubscribe()
+
+			if got := eventIDs(backlog); !slices.Equal(got, tt.wantBacklog) {
+				t.Errorf("backlog = %v, want %v", got, tt.wantBacklog)
+			}
+			if missed != tt.wantMissed {
+				t.Errorf("missed = %v, want %v", missed, tt.wantMissed)
+			}
+		})
+	}
+}
+
+func TestStreamResumeBeforeAnyEvent(t *testing.T) {
+	bus := NewBus()
+	before := publish(bus, 1)
+	stream := NewStream(bus, 3)
+
+	backlog, missed, _, unsubscribe := stream.Subscribe(before[0])
+	unsubscribe()
+	if len(backlog) != 0 || missed {
+		t.Errorf("resuming at the latest event: backlog %v, missed %v", eventIDs(backlog), missed)
+	}
+
+	_, missed, _, unsubscribe = stream.Subscribe(before[0] - 1)
+	unsubscribe()
+	if !missed {
+		t.Error("an event published before the stream started was not reported missed")
+	}
+}
+
+func TestStreamLive(t *testing.T) {
+	bus := NewBus()
+	stream := NewStream(bus, 3)
+
+	_, _, live, unsubscribe := stream.Subscribe(0)
+	ids := publish(bus, 2)
+	for _, id := range ids {
+		if event := <-live; event.ID != id {
+			t.Fatalf("received event %d, want %d", event.ID, id)
+		}
+	}
+	unsubscribe()
+	if _, ok := <-live; ok {
+		t.Error("channel still open after unsubscribe")
+	}
+	unsubscribe()
+
+	_, _, slow, unsubscribe := stream.Subscribe(0)
+	defer unsubscribe()
+	publish(bus, subscriberBuffer+1)
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("slow subscriber received %d events before being disconnected, want %d", received, subscriberBuffer)
+	}
+}
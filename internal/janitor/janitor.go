@@ -0,0 +1,340 @@
+package janitor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/config"
+	"sync"
+	"time"
+)
+
+const (
+	batchSize = 500
+	// batchPause gives the scheduler a chance to write between deletes
+	batchPause = 50 * time.Millisecond
+	// incrementalVacuumPages is how many free pages each pass returns to
+	// the file system
+	incrementalVacuumPages = 1000
+	// rollupRetention covers the longest stats window plus a day
+	rollupRetention = 31 * 24 * time.Hour
+	// webhookDeliveryRetention keeps finished webhook deliveries for a week
+	webhookDeliveryRetention = 7 * 24 * time.Hour
+	// deadLetterRetention keeps replayed dead letters for a month; pending
+	// ones stay until they are replayed or deleted
+	deadLetterRetention = 30 * 24 * time.Hour
+)
+
+// Status describes the janitor's most recent pass.
+type Status struct {
+	LastRunAt        *time.Time
+	LastDuration     time.Duration
+	LastDeletedRuns  int64
+	TotalDeletedRuns int64
+	LastVacuumAt     *time.Time
+	LastError        string
+}
+
+// Janitor prunes run history according to the retention policy: the global
+// settings from the environment, overridden per job.
+type Janitor struct {
+	db     *db.Queries
+	conn   *sql.DB
+	policy config.Retention
+	status Status
+	mutex  sync.RWMutex
+	ticker *time.Ticker
+	done   chan bool
+	// lastVacuum starts at Start so the first VACUUM waits one interval
+	lastVacuum time.Time
+}
+
+func NewJanitor(database *db.Queries, conn *sql.DB, policy config.Retention) *Janitor {
+	return &Janitor{
+		db:     database,
+		conn:   conn,
+		policy: policy,
+		done:   make(chan bool),
+	}
+}
+
+func (j *Janitor) Start(ctx context.Context) {
+	if j.policy.JanitorInterval <= 0 {
+		slog.Info("janitor disabled", "reason", "JANITOR_INTERVAL=0")
+		return
+	}
+
+	slog.Info("starting janitor", "interval", j.policy.JanitorInterval.String())
+
+	j.ticker = time.NewTicker(j.policy.JanitorInterval)
+	j.lastVacuum = time.Now()
+
+	go j.run(ctx)
+}
+
+func (j *Janitor) Stop() {
+	if j.ticker == nil {
+		return
+	}
+
+	slog.Info("stopping janitor")
+	j.ticker.Stop()
+	j.done <- true
+}
+
+// Status returns a copy of the janitor's latest status.
+func (j *Janitor) Status() Status {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+	return j.status
+}
+
+// Policy returns the global retention settings the janitor enforces.
+func (j *Janitor) Policy() config.Retention {
+	return j.policy
+}
+
+func (j *Janitor) run(ctx context.Context) {
+	j.prune(ctx)
+
+	for {
+		select {
+		case <-j.done:
+			slog.Info("janitor stopped")
+			return
+		case <-j.ticker.C:
+			j.prune(ctx)
+		}
+	}
+}
+
+func (j *Janitor) prune(ctx context.Context) {
+	start := time.Now()
+
+	deleted, err := j.pruneRuns(ctx, start)
+	if err == nil {
+		var orphans int64
+		orphans, err = j.pruneOrphans(ctx)
+		deleted += orphans
+	}
+	if err == nil {
+		err = j.pruneRollups(ctx, start)
+	}
+	if err == nil {
+		err = j.pruneWebhookDeliveries(ctx, start)
+	}
+	if err == nil {
+		err = j.pruneDeadLetters(ctx, start)
+	}
+	if err == nil && deleted > 0 {
+		_, err = j.conn.ExecContext(ctx, fmt.Sprintf("PRAGMA incremental_vacuum(%d)", incrementalVacuumPages))
+	}
+
+	var vacuumedAt *time.Time
+	if err == nil && j.policy.VacuumInterval > 0 && start.Sub(j.lastVacuum) >= j.policy.VacuumInterval {
+		slog.Info("janitor running VACUUM")
+		if _, err = j.conn.ExecContext(ctx, "VACUUM"); err == nil {
+			j.lastVacuum = time.Now()
+			finished := j.lastVacuum
+			vacuumedAt = &finished
+		}
+	}
+
+	if err != nil {
+		slog.Error("janitor pass failed", "error", err)
+	}
+	if deleted > 0 {
+		slog.Info("janitor pruned runs", "deleted", deleted, "duration_ms", time.Since(start).Milliseconds())
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.status.LastRunAt = &start
+	j.status.LastDuration = time.Since(start)
+	j.status.LastDeletedRuns = deleted
+	j.status.TotalDeletedRuns += deleted
+	j.status.LastError = ""
+	if err != nil {
+		j.status.LastError = err.Error()
+	}
+	if vacuumedAt != nil {
+		j.status.LastVacuumAt = vacuumedAt
+	}
+}
+
+func (j *Janitor) pruneRuns(ctx context.Context, now time.Time) (int64, error) {
+	jobs, err := j.db.GetAllJobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, job := range jobs {
+		count, err := j.pruneJob(ctx, job, now)
+		deleted += count
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+func (j *Janitor) pruneJob(ctx context.Context, job db.Job, now time.Time) (int64, error) {
+	keepRuns := override(job.RetentionKeepRuns, j.policy.KeepRuns)
+	keepDays := override(job.RetentionKeepDays, j.policy.KeepDays)
+	keepFailuresDays := override(job.RetentionKeepFailuresDays, j.policy.KeepFailuresDays)
+
+	var minKeptID int64
+	if keepRuns > 0 {
+		id, err := j.db.GetNthLatestJobRunID(ctx, db.GetNthLatestJobRunIDParams{
+			JobID:  job.ID,
+			Offset: keepRuns - 1,
+		})
+		if err != nil && err != sql.ErrNoRows {
+			return 0, err
+		}
+		minKeptID = id
+	}
+
+	params := db.GetExpiredJobRunIDsParams{
+		JobID:        job.ID,
+		FailedBefore: cutoff(now, keepFailuresDays),
+		Before:       cutoff(now, keepDays),
+		MinKeptID:    minKeptID,
+		BatchSize:    batchSize,
+	}
+
+	var deleted int64
+	for {
+		ids, err := j.db.GetExpiredJobRunIDs(ctx, params)
+		if err != nil || len(ids) == 0 {
+			return deleted, err
+		}
+
+		count, err := j.deleteRuns(ctx, ids)
+		deleted += count
+		if err != nil || len(ids) < batchSize {
+			return deleted, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return deleted, ctx.Err()
+		case <-time.After(batchPause):
+		}
+	}
+}
+
+// pruneOrphans removes what deleted jobs left behind: their runs, in
+// batches like expired ones, tracked content, alert state, incidents and
+// dead letters.
+func (j *Janitor) pruneOrphans(ctx context.Context) (int64, error) {
+	var deleted int64
+	for {
+		ids, err := j.db.GetOrphanedJobRunIDs(ctx, batchSize)
+		if err != nil {
+			return deleted, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		count, err := j.deleteRuns(ctx, ids)
+		deleted += count
+		if err != nil {
+			return deleted, err
+		}
+		if len(ids) < batchSize {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return deleted, ctx.Err()
+		case <-time.After(batchPause):
+		}
+	}
+
+	for _, deleteOrphans := range []func(context.Context) error{
+		j.db.DeleteOrphanedTrackedContent,
+		j.db.DeleteOrphanedAlertStates,
+		j.db.DeleteOrphanedIncidents,
+		j.db.DeleteOrphanedDeadLetters,
+	} {
+		if err := deleteOrphans(ctx); err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+func (j *Janitor) pruneRollups(ctx context.Context, now time.Time) error {
+	before := now.Add(-rollupRetention).Unix()
+
+	if _, err := j.db.DeleteJobRollupsBefore(ctx, before); err != nil {
+		return err
+	}
+	_, err := j.db.DeleteJobLatencyRollupsBefore(ctx, before)
+	return err
+}
+
+// pruneWebhookDeliveries drops old delivery log entries; pending ones stay
+// until they are delivered or fail.
+func (j *Janitor) pruneWebhookDeliveries(ctx context.Context, now time.Time) error {
+	_, err := j.db.DeleteWebhookDeliveriesBefore(ctx, now.UTC().Add(-webhookDeliveryRetention))
+	return err
+}
+
+// pruneDeadLetters drops dead letters replayed a while ago.
+func (j *Janitor) pruneDeadLetters(ctx context.Context, now time.Time) error {
+	_, err := j.db.DeleteDeadLettersReplayedBefore(ctx, sql.NullTime{Time: now.UTC().Add(-deadLetterRetention), Valid: true})
+	return err
+}
+
+// deleteRuns removes a batch of runs together with their certificates and
+// steps in one transaction.
+func (j *Janitor) deleteRuns(ctx context.Context, ids []int64) (int64, error) {
+	tx, err := j.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	queries := j.db.WithTx(tx)
+
+	if err := queries.DeleteRunCertificatesByRunIDs(ctx, ids); err != nil {
+		return 0, err
+	}
+	if err := queries.DeleteJobRunStepsByRunIDs(ctx, ids); err != nil {
+		return 0, err
+	}
+
+	deleted, err := queries.DeleteJobRunsByIDs(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, tx.Commit()
+}
+
+// override returns the job's setting when it has one, else the global one.
+func override(value sql.NullInt64, global int64) int64 {
+	if value.Valid && value.Int64 > 0 {
+		return value.Int64
+	}
+	return global
+}
+
+// cutoff is the start time before which runs expire, or NULL (nothing
+// expires) when days is zero.
+func cutoff(now time.Time, days int64) sql.NullTime {
+	if days <= 0 {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: now.AddDate(0, 0, -int(days)), Valid: true}
+}
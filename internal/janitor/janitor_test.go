@@ -0,0 +1,166 @@
+package janitor
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/config"
+	"lucasbonna/pulse/internal/storage"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestJanitor returns a janitor, not started, enforcing policy on a
+// fresh database in a temporary directory.
+func newTestJanitor(t *testing.T, policy config.Retention) *Janitor {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+	queries, database, err := storage.NewSQLiteDB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	return NewJanitor(queries, database, policy)
+}
+
+// testRun is a run to store: its status and how many days before now it
+// started.
+type testRun struct {
+	status  string
+	daysAgo int
+}
+
+// createRuns stores a job with the given per-job retention and its runs,
+// oldest first, and returns the run IDs in the same order.
+func createRuns(t *testing.T, j *Janitor, retention db.CreateJobParams, now time.Time, runs []testRun) (db.Job, []int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	retention.Name = "test"
+	retention.Method = "GET"
+	retention.Type = "http"
+	retention.Url = "https://example.com"
+	retention.IntervalSeconds = 60
+	retention.TimeoutSeconds = 5
+	job, err := j.db.CreateJob(ctx, retention)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	var ids []int64
+	for _, run := range runs {
+		stored, err := j.db.CreateJobRun(ctx, db.CreateJobRunParams{
+			JobID:     job.ID,
+			Status:    sql.NullString{String: run.status, Valid: true},
+			StartedAt: sql.NullTime{Time: now.AddDate(0, 0, -run.daysAgo), Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("create run: %v", err)
+		}
+		ids = append(ids, stored.ID)
+	}
+	return job, ids
+}
+
+func TestPruneRuns(t *testing.T) {
+	runs := []testRun{
+		{"failed", 40},
+		{"success", 40},
+		{"failed", 10},
+		{"running", 10},
+		{"success", 10},
+		{"warning", 5},
+		{"success", 2},
+		{"failed", 1},
+		{"success", 0},
+	}
+
+	tests := []struct {
+		name      string
+		policy    config.Retention
+		retention db.CreateJobParams
+		wantKept  []int // indexes into runs
+	}{
+		{"keep everything", config.Retention{}, db.CreateJobParams{}, []int{0, 1, 2, 3, 4, 5, 6, 7, 8}},
+		{"keep runs", config.Retention{KeepRuns: 3}, db.CreateJobParams{}, []int{0, 2, 3, 6, 7, 8}},
+		{"keep days", config.Retention{KeepDays: 7}, db.CreateJobParams{}, []int{0, 2, 3, 5, 6, 7, 8}},
+		{"keep failures", config.Retention{KeepDays: 7, KeepFailuresDays: 30}, db.CreateJobParams{}, []int{2, 3, 5, 6, 7, 8}},
+		{"keep runs and days", config.Retention{KeepRuns: 5, KeepDays: 3, KeepFailuresDays: 3}, db.CreateJobParams{}, []int{6, 7, 8}},
+		{"job overrides", config.Retention{KeepRuns: 100, KeepDays: 100, KeepFailuresDays: 100}, db.CreateJobParams{
+			RetentionKeepRuns:         sql.NullInt64{Int64: 1, Valid: true},
+			RetentionKeepDays:         sql.NullInt64{Int64: 100, Valid: true},
+			RetentionKeepFailuresDays: sql.NullInt64{Int64: 7, Valid: true},
+		}, []int{7, 8}},
+		{"zero job settings use the global ones", config.Retention{KeepDays: 7}, db.CreateJobParams{
+			RetentionKeepDays: sql.NullInt64{Int64: 0, Valid: true},
+		}, []int{0, 2, 3, 5, 6, 7, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := newTestJanitor(t, tt.policy)
+			ctx := context.Background()
+			now := time.Now().UTC()
+			job, ids := createRuns(t, j, tt.retention, now, runs)
+
+			deleted, err := j.pruneRuns(ctx, now)
+			if err != nil {
+				t.Fatalf("pruneRuns: %v", err)
+			}
+
+			stored, err := j.db.GetJobRuns(ctx, db.GetJobRunsParams{JobID: job.ID, Limit: 100})
+			if err != nil {
+				t.Fatalf("list runs: %v", err)
+			}
+			var kept []int
+			for _, run := range stored {
+				kept = append(kept, slices.Index(ids, run.ID))
+			}
+			slices.Sort(kept)
+
+			if !slices.Equal(kept, tt.wantKept) {
+				t.Errorf("kept runs %v, want %v", kept, tt.wantKept)
+			}
+			if deleted != int64(len(runs)-len(tt.wantKept)) {
+				t.Errorf("deleted %d runs, want %d", deleted, len(runs)-len(tt.wantKept))
+			}
+		})
+	}
+}
+
+func TestPruneOrphans(t *testing.T) {
+	j := newTestJanitor(t, config.Retention{})
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	deletedJob, _ := createRuns(t, j, db.CreateJobParams{}, now, []testRun{{"success", 0}, {"failed", 0}})
+	job, ids := createRuns(t, j, db.CreateJobParams{}, now, []testRun{{"success", 0}})
+	if err := j.db.DeleteJob(ctx, deletedJob.ID); err != nil {
+		t.Fatalf("delete job: %v", err)
+	}
+
+	j.prune(ctx)
+
+	status := j.Status()
+	if status.LastError != "" || status.LastDeletedRuns != 2 || status.TotalDeletedRuns != 2 {
+		t.Errorf("status = %+v, want 2 deleted runs", status)
+	}
+
+	orphans, err := j.db.GetOrphanedJobRunIDs(ctx, batchSize)
+	if err != nil {
+		t.Fatalf("list orphans: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("%d orphaned runs survived", len(orphans))
+	}
+	stored, err := j.db.GetJobRuns(ctx, db.GetJobRunsParams{JobID: job.ID, Limit: 100})
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(stored) != 1 || stored[0].ID != ids[0] {
+		t.Errorf("runs of a live job = %v, want them kept", stored)
+	}
+}
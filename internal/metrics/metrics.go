@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	jobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pulse_job_runs_total",
+		Help: "Finished job runs by job and status.",
+	}, []string{"job_id", "job_name", "type", "status"})
+
+	jobRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pulse_job_run_duration_seconds",
+		Help:    "Duration of the check performed by a job run.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"job_id", "job_name", "type"})
+
+	schedulerLag = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "pulse_scheduler_lag_seconds",
+		Help:    "Delay between a job's scheduled next_run_at and the actual start of the run.",
+		Buckets: []float64{.1, .25, .5, 1, 2, 5, 10, 30, 60, 300},
+	})
+
+	queueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "pulse_scheduler_queue_depth",
+		Help: "Due jobs found on the last scheduler tick, including those still running.",
+	})
+
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pulse_http_requests_total",
+		Help: "API requests by method, route pattern and status code.",
+	}, []string{"method", "route", "code"})
+
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pulse_http_request_duration_seconds",
+		Help:    "API request latency by method and route pattern.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		jobRuns,
+		jobRunDuration,
+		schedulerLag,
+		queueDepth,
+		apiRequests,
+		apiRequestDuration,
+	)
+}
+
+// RegisterRunningJobs exposes the number of jobs currently executing,
+// read from count on every scrape.
+func RegisterRunningJobs(count func() int) {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "pulse_jobs_running",
+		Help: "Jobs currently executing.",
+	}, func() float64 { return float64(count()) }))
+}
+
+// ObserveRun records a finished run.
+func ObserveRun(jobID int64, jobName, jobType, status string, duration time.Duration) {
+	id := strconv.FormatInt(jobID, 10)
+	jobRuns.WithLabelValues(id, jobName, jobType, status).Inc()
+	jobRunDuration.WithLabelValues(id, jobName, jobType).Observe(duration.Seconds())
+}
+
+// ObserveSchedulerLag records how late a run started compared to its
+// scheduled time.
+func ObserveSchedulerLag(lag time.Duration) {
+	if lag < 0 {
+		lag = 0
+	}
+	schedulerLag.Observe(lag.Seconds())
+}
+
+func SetQueueDepth(depth int) {
+	queueDepth.Set(float64(depth))
+}
+
+// Middleware records API request metrics. It labels requests with the chi
+// route pattern rather than the raw path to keep cardinality bounded.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if routeContext := chi.RouteContext(r.Context()); routeContext != nil {
+			if pattern := routeContext.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		apiRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		apiRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ListenAndServe serves /metrics on its own port, outside the API's
+// bearer token authentication.
+func ListenAndServe(port string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}))
+
+	slog.Info("starting metrics server", "port", port)
+	return http.ListenAndServe(":"+port, mux)
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetryAfter caps how far a target's Retry-After can push runs out.
+const maxRetryAfter = 24 * time.Hour
+
+// parseRetryAfter reads the Retry-After header of a 429 or 503 answer,
+// given as seconds or as an HTTP date. It returns 0 for other answers and
+// for missing or unreadable headers.
+func parseRetryAfter(statusCode int, header http.Header, now time.Time) time.Duration {
+	if statusCode != http.StatusTooManyRequests && statusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		delay = time.Duration(min(seconds, int64(maxRetryAfter/time.Second))) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = date.Sub(now)
+	}
+
+	return min(max(delay, 0), maxRetryAfter)
+}
+
+// targetHost is the host name a job's requests go to, or "" for jobs that
+// do not send HTTP requests to their url.
+func targetHost(job db.Job) string {
+	switch job.Type {
+	case JobTypeHTTP, JobTypeSSE, JobTypeWebSocket:
+		parsed, err := url.Parse(job.Url)
+		if err != nil {
+			return ""
+		}
+		return strings.ToLower(parsed.Hostname())
+	}
+	return ""
+}
+
+// honourBackpressure moves nextRun past the time the job's target asked to
+// be left alone: its own Retry-After, or one received for the same host by
+// a job with retry_after_host_wide.
+func (s *Scheduler) honourBackpressure(ctx context.Context, job db.Job, result Result, now time.Time, nextRun time.Time) time.Time {
+	host := targetHost(job)
+
+	if result.RetryAfter > 0 {
+		until := now.Add(result.RetryAfter).UTC()
+		slog.InfoContext(ctx, "target asked to back off", "job_id", job.ID, "retry_after", result.RetryAfter,
+			"host_wide", job.RetryAfterHostWide.Bool)
+
+		if job.RetryAfterHostWide.Bool && host != "" {
+			s.hosts.pause(host, until)
+		}
+		if until.After(nextRun) {
+			nextRun = until
+		}
+	}
+
+	if host != "" {
+		if resumeAt := s.hosts.resumeAt(host); resumeAt.After(nextRun) {
+			nextRun = resumeAt
+		}
+	}
+
+	return nextRun
+}
+
+// deferPausedJob pushes a due job whose host asked every job to back off to
+// the end of that pause. It reports whether the job was deferred.
+func (s *Scheduler) deferPausedJob(ctx context.Context, job db.Job) bool {
+	host := targetHost(job)
+	if host == "" {
+		return false
+	}
+
+	resumeAt := s.hosts.resumeAt(host)
+	if !resumeAt.After(time.Now()) {
+		return false
+	}
+
+	err := s.db.UpdateJobNextRun(ctx, db.UpdateJobNextRunParams{
+		ID:        job.ID,
+		NextRunAt: sql.NullTime{Time: resumeAt, Valid: true},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to defer job", "job_id", job.ID, "error", err)
+		return false
+	}
+
+	slog.InfoContext(ctx, "job deferred while its host backs off", "job_id", job.ID, "host", host, "next_run_at", resumeAt)
+	return true
+}
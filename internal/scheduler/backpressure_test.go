@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		statusCode int
+		retryAfter string
+		want       time.Duration
+	}{
+		{"seconds on 429", http.StatusTooManyRequests, "120", 2 * time.Minute},
+		{"seconds on 503", http.StatusServiceUnavailable, "30", 30 * time.Second},
+		{"surrounding spaces", http.StatusTooManyRequests, " 5 ", 5 * time.Second},
+		{"zero", http.StatusTooManyRequests, "0", 0},
+		{"negative", http.StatusTooManyRequests, "-10", 0},
+		{"capped", http.StatusTooManyRequests, "999999", maxRetryAfter},
+		{"huge", http.StatusTooManyRequests, "99999999999999999", maxRetryAfter},
+		{"overflowing", http.StatusTooManyRequests, "99999999999999999999", 0},
+		{"http date", http.StatusServiceUnavailable, now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"past http date", http.StatusServiceUnavailable, now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"distant http date", http.StatusServiceUnavailable, now.Add(72 * time.Hour).Format(http.TimeFormat), maxRetryAfter},
+		{"unreadable", http.StatusTooManyRequests, "soon", 0},
+		{"missing", http.StatusTooManyRequests, "", 0},
+		{"other status", http.StatusInternalServerError, "120", 0},
+		{"success", http.StatusOK, "120", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.retryAfter != "" {
+				header.Set("Retry-After", tt.retryAfter)
+			}
+			if got := parseRetryAfter(tt.statusCode, header, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%d, %q) = %s, want %s", tt.statusCode, tt.retryAfter, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/events"
+	"time"
+)
+
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// DefaultBreakerProbeSeconds is how long an open breaker waits between
+// probe runs unless the job sets breaker_probe_seconds.
+const DefaultBreakerProbeSeconds = 300
+
+// breakerEnabled reports whether job has a circuit breaker. Heartbeat jobs
+// never do: they call nothing that could be spared.
+func breakerEnabled(job db.Job) bool {
+	return job.BreakerFailureThreshold.Valid && job.BreakerFailureThreshold.Int64 > 0 &&
+		job.Type != JobTypeHeartbeat
+}
+
+// BreakerProbeInterval is the time between runs while job's breaker is open.
+// It is never shorter than the job's own interval.
+func BreakerProbeInterval(job db.Job) time.Duration {
+	seconds := int64(DefaultBreakerProbeSeconds)
+	if job.BreakerProbeSeconds.Valid && job.BreakerProbeSeconds.Int64 > 0 {
+		seconds = job.BreakerProbeSeconds.Int64
+	}
+	return time.Duration(max(seconds, job.IntervalSeconds)) * time.Second
+}
+
+// halfOpenBreaker lets a run through an open breaker as a probe: its outcome
+// alone decides whether the breaker closes or opens again.
+func (s *Scheduler) halfOpenBreaker(ctx context.Context, job *db.Job) {
+	if !breakerEnabled(*job) || job.BreakerState != BreakerOpen {
+		return
+	}
+	s.setBreaker(ctx, job, BreakerHalfOpen, job.BreakerFailures, job.BreakerOpenedAt)
+}
+
+// updateBreaker counts a finished run against job's breaker: a failure opens
+// it once the threshold is reached, or straight away after a failed probe,
+// and a success closes it. It returns the time until the next run.
+func (s *Scheduler) updateBreaker(ctx context.Context, job *db.Job, status string, now time.Time) time.Duration {
+	interval := time.Duration(job.IntervalSeconds) * time.Second
+
+	if !breakerEnabled(*job) {
+		// the breaker was removed while tripped
+		if job.BreakerState != BreakerClosed || job.BreakerFailures != 0 {
+			s.setBreaker(ctx, job, BreakerClosed, 0, sql.NullTime{})
+		}
+		return interval
+	}
+
+	if status != "failed" {
+		if job.BreakerState != BreakerClosed || job.BreakerFailures != 0 {
+			s.setBreaker(ctx, job, BreakerClosed, 0, sql.NullTime{})
+		}
+		return interval
+	}
+
+	failures := job.BreakerFailures + 1
+	switch {
+	case job.BreakerState == BreakerHalfOpen:
+		s.setBreaker(ctx, job, BreakerOpen, failures, job.BreakerOpenedAt)
+	case job.BreakerState == BreakerClosed && failures >= job.BreakerFailureThreshold.Int64:
+		s.setBreaker(ctx, job, BreakerOpen, failures, sql.NullTime{Time: now.UTC(), Valid: true})
+	default:
+		s.setBreaker(ctx, job, job.BreakerState, failures, job.BreakerOpenedAt)
+	}
+
+	if job.BreakerState == BreakerOpen {
+		return BreakerProbeInterval(*job)
+	}
+	return interval
+}
+
+// setBreaker stores the breaker's state on job and in the database and
+// publishes an event when the state changed.
+func (s *Scheduler) setBreaker(ctx context.Context, job *db.Job, state string, failures int64, openedAt sql.NullTime) {
+	previous := job.BreakerState
+
+	err := s.db.UpdateJobBreaker(ctx, db.UpdateJobBreakerParams{
+		ID:              job.ID,
+		BreakerState:    state,
+		BreakerFailures: failures,
+		BreakerOpenedAt: openedAt,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update circuit breaker", "job_id", job.ID, "error", err)
+		return
+	}
+
+	job.BreakerState = state
+	job.BreakerFailures = failures
+	job.BreakerOpenedAt = openedAt
+
+	if state == previous {
+		return
+	}
+
+	slog.InfoContext(ctx, "circuit breaker changed", "job_id", job.ID, "from", previous, "to", state, "failures", failures)
+
+	data := events.BreakerData{
+		JobID:               job.ID,
+		JobName:             job.Name,
+		State:               state,
+		PreviousState:       previous,
+		ConsecutiveFailures: failures,
+	}
+	if state == BreakerOpen {
+		nextProbe := time.Now().Add(BreakerProbeInterval(*job)).UTC()
+		data.NextProbeAt = &nextProbe
+	}
+
+	eventType := events.BreakerClosed
+	switch state {
+	case BreakerOpen:
+		eventType = events.BreakerOpened
+	case BreakerHalfOpen:
+		eventType = events.BreakerHalfOpened
+	}
+	s.events.Publish(eventType, *job, data)
+}
@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/events"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBreakerProbeInterval(t *testing.T) {
+	tests := []struct {
+		name         string
+		interval     int64
+		probeSeconds sql.NullInt64
+		want         time.Duration
+	}{
+		{"default", 60, sql.NullInt64{}, DefaultBreakerProbeSeconds * time.Second},
+		{"configured", 60, sql.NullInt64{Int64: 120, Valid: true}, 120 * time.Second},
+		{"zero falls back to the default", 60, sql.NullInt64{Int64: 0, Valid: true}, DefaultBreakerProbeSeconds * time.Second},
+		{"never shorter than the interval", 900, sql.NullInt64{Int64: 120, Valid: true}, 900 * time.Second},
+		{"default below the interval", 3600, sql.NullInt64{}, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := db.Job{IntervalSeconds: tt.interval, BreakerProbeSeconds: tt.probeSeconds}
+			if got := BreakerProbeInterval(job); got != tt.want {
+				t.Errorf("BreakerProbeInterval = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// newBreakerScheduler returns a scheduler on a fresh database holding one
+// http job with a breaker that opens after three failures, and the event
+// types it publishes.
+func newBreakerScheduler(t *testing.T) (*Scheduler, db.Job, *[]string) {
+	t.Helper()
+
+	s := newTestScheduler(t)
+	job := createTestJob(t, s, db.CreateJobParams{
+		Url:                     "https://example.com",
+		BreakerFailureThreshold: sql.NullInt64{Int64: 3, Valid: true},
+		BreakerProbeSeconds:     sql.NullInt64{Int64: 600, Valid: true},
+	})
+
+	var published []string
+	s.events.Subscribe(func(event events.Event) { published = append(published, event.Type) })
+
+	return s, job, &published
+}
+
+func TestBreakerStateMachine(t *testing.T) {
+	s, job, published := newBreakerScheduler(t)
+	ctx := context.Background()
+	interval, probe := time.Minute, 10*time.Minute
+
+	steps := []struct {
+		name         string
+		probe        bool
+		status       string
+		wantState    string
+		wantFailures int64
+		wantNext     time.Duration
+	}{
+		{"first failure", false, "failed", BreakerClosed, 1, interval},
+		{"second failure", false, "failed", BreakerClosed, 2, interval},
+		{"success resets", false, "success", BreakerClosed, 0, interval},
+		{"failure", false, "failed", BreakerClosed, 1, interval},
+		{"failure", false, "failed", BreakerClosed, 2, interval},
+		{"threshold opens", false, "failed", BreakerOpen, 3, probe},
+		{"failed probe reopens", true, "failed", BreakerOpen, 4, probe},
+		{"warning probe closes", true, "warning", BreakerClosed, 0, interval},
+	}
+
+	var openedAt sql.NullTime
+	for _, step := range steps {
+		if step.probe {
+			s.halfOpenBreaker(ctx, &job)
+			if job.BreakerState != BreakerHalfOpen {
+				t.Fatalf("%s: probe state = %s, want %s", step.name, job.BreakerState, BreakerHalfOpen)
+			}
+		}
+
+		next := s.updateBreaker(ctx, &job, step.status, time.Now())
+		if job.BreakerState != step.wantState || job.BreakerFailures != step.wantFailures {
+			t.Fatalf("%s: breaker = %s/%d, want %s/%d", step.name, job.BreakerState, job.BreakerFailures, step.wantState, step.wantFailures)
+		}
+		if next != step.wantNext {
+			t.Errorf("%s: next run in %s, want %s", step.name, next, step.wantNext)
+		}
+
+		if step.name == "threshold opens" {
+			openedAt = job.BreakerOpenedAt
+		}
+		if step.name == "failed probe reopens" && job.BreakerOpenedAt != openedAt {
+			t.Errorf("%s: opened_at moved from %v to %v", step.name, openedAt, job.BreakerOpenedAt)
+		}
+
+		stored, err := s.db.GetJobByID(ctx, job.ID)
+		if err != nil {
+			t.Fatalf("reload job: %v", err)
+		}
+		if stored.BreakerState != job.BreakerState || stored.BreakerFailures != job.BreakerFailures {
+			t.Errorf("%s: stored breaker = %s/%d, want %s/%d", step.name, stored.BreakerState, stored.BreakerFailures, job.BreakerState, job.BreakerFailures)
+		}
+	}
+
+	want := []string{
+		events.BreakerOpened,
+		events.BreakerHalfOpened, events.BreakerOpened,
+		events.BreakerHalfOpened, events.BreakerClosed,
+	}
+	if !slices.Equal(*published, want) {
+		t.Errorf("published %v, want %v", *published, want)
+	}
+}
+
+func TestBreakerResetsWhenRemoved(t *testing.T) {
+	s, job, _ := newBreakerScheduler(t)
+	ctx := context.Background()
+
+	for range 3 {
+		s.updateBreaker(ctx, &job, "failed", time.Now())
+	}
+	if job.BreakerState != BreakerOpen {
+		t.Fatalf("breaker = %s, want %s", job.BreakerState, BreakerOpen)
+	}
+
+	job.BreakerFailureThreshold = sql.NullInt64{}
+	if next := s.updateBreaker(ctx, &job, "failed", time.Now()); next != time.Minute {
+		t.Errorf("next run in %s, want the job's interval", next)
+	}
+	if job.BreakerState != BreakerClosed || job.BreakerFailures != 0 || job.BreakerOpenedAt.Valid {
+		t.Errorf("breaker = %s/%d opened %v, want it reset", job.BreakerState, job.BreakerFailures, job.BreakerOpenedAt)
+	}
+}
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"lucasbonna/pulse/db"
+	"net/http"
+)
+
+const (
+	// bodies above this size are stored gzip-compressed
+	gzipThresholdBytes = 1024
+
+	EncodingIdentity = "identity"
+	EncodingGzip     = "gzip"
+
+	redactedValue = "[REDACTED]"
+)
+
+var (
+	// defaultRedactedHeaders are never stored, whatever the job configures.
+	defaultRedactedHeaders = []string{"Set-Cookie"}
+	// defaultRedactedRequestHeaders carry credentials of the requests kept
+	// in dead letters and are never shown.
+	defaultRedactedRequestHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+)
+
+// capturedResponse is the stored form of a response: headers as JSON with
+// redacted values replaced, and the body cut at max_body_bytes. Size is
+// the size of the whole body as received.
+type capturedResponse struct {
+	Headers   sql.NullString
+	Body      []byte
+	Encoding  sql.NullString
+	Size      sql.NullInt64
+	Truncated bool
+}
+
+func captureResponse(job db.Job, result Result) (capturedResponse, error) {
+	var captured capturedResponse
+	if result.Headers == nil {
+		return captured, nil
+	}
+
+	headers, err := redactHeaders(job, result.Headers)
+	if err != nil {
+		return captured, err
+	}
+	captured.Headers = sql.NullString{String: headers, Valid: true}
+	captured.Size = sql.NullInt64{Int64: result.BodySize, Valid: true}
+
+	if job.MaxBodyBytes <= 0 {
+		return captured, nil
+	}
+
+	body := result.Body
+	if int64(len(body)) > job.MaxBodyBytes {
+		body = body[:job.MaxBodyBytes]
+	}
+	captured.Truncated = int64(len(body)) < result.BodySize
+
+	if len(body) <= gzipThresholdBytes {
+		captured.Body = body
+		captured.Encoding = sql.NullString{String: EncodingIdentity, Valid: true}
+		return captured, nil
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		return captured, err
+	}
+	if err := writer.Close(); err != nil {
+		return captured, err
+	}
+
+	captured.Body = compressed.Bytes()
+	captured.Encoding = sql.NullString{String: EncodingGzip, Valid: true}
+	return captured, nil
+}
+
+func redactHeaders(job db.Job, headers http.Header) (string, error) {
+	stored, err := redact(job, headers, defaultRedactedHeaders)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(stored)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// RedactRequestHeaders returns a copy of the headers of a request sent for
+// job with credentials and the job's redact_headers replaced.
+func RedactRequestHeaders(job db.Job, headers http.Header) (http.Header, error) {
+	return redact(job, headers, defaultRedactedRequestHeaders)
+}
+
+func redact(job db.Job, headers http.Header, defaults []string) (http.Header, error) {
+	redacted := append([]string{}, defaults...)
+	if job.RedactHeaders.Valid {
+		var names []string
+		if err := json.Unmarshal([]byte(job.RedactHeaders.String), &names); err != nil {
+			return nil, err
+		}
+		redacted = append(redacted, names...)
+	}
+
+	stored := headers.Clone()
+	for _, name := range redacted {
+		values := stored.Values(name)
+		for i := range values {
+			values[i] = redactedValue
+		}
+	}
+	return stored, nil
+}
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"lucasbonna/pulse/db"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCaptureResponse(t *testing.T) {
+	small := []byte("order 17 shipped")
+	large := bytes.Repeat([]byte("0123456789"), 300)
+	headers := http.Header{
+		"Content-Type": {"text/plain"},
+		"Set-Cookie":   {"session=s1", "theme=dark"},
+		"X-Api-Token":  {"t0k"},
+	}
+
+	tests := []struct {
+		name          string
+		maxBodyBytes  int64
+		redact        string
+		headers       http.Header
+		body          []byte
+		wantBody      []byte
+		wantEncoding  string
+		wantTruncated bool
+		wantHeaders   string
+	}{
+		{"no response", 1024, "", nil, nil, nil, "", false, ""},
+		{"bodies not stored", 0, "", headers, small, nil, "", false,
+			`{"Content-Type":["text/plain"],"Set-Cookie":["[REDACTED]","[REDACTED]"],"X-Api-Token":["t0k"]}`},
+		{"small body", 1024, "", headers, small, small, EncodingIdentity, false,
+			`{"Content-Type":["text/plain"],"Set-Cookie":["[REDACTED]","[REDACTED]"],"X-Api-Token":["t0k"]}`},
+		{"cut at the cap", 5, "", headers, small, small[:5], EncodingIdentity, true,
+			`{"Content-Type":["text/plain"],"Set-Cookie":["[REDACTED]","[REDACTED]"],"X-Api-Token":["t0k"]}`},
+		{"large body", 4096, "", headers, large, large, EncodingGzip, false,
+			`{"Content-Type":["text/plain"],"Set-Cookie":["[REDACTED]","[REDACTED]"],"X-Api-Token":["t0k"]}`},
+		{"large body cut", 2048, "", headers, large, large[:2048], EncodingGzip, true,
+			`{"Content-Type":["text/plain"],"Set-Cookie":["[REDACTED]","[REDACTED]"],"X-Api-Token":["t0k"]}`},
+		{"job's redacted headers", 1024, `["x-api-token"]`, headers, small, small, EncodingIdentity, false,
+			`{"Content-Type":["text/plain"],"Set-Cookie":["[REDACTED]","[REDACTED]"],"X-Api-Token":["[REDACTED]"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := db.Job{MaxBodyBytes: tt.maxBodyBytes, RedactHeaders: sql.NullString{String: tt.redact, Valid: tt.redact != ""}}
+			result := Result{Headers: tt.headers, Body: tt.body, BodySize: int64(len(tt.body))}
+
+			captured, err := captureResponse(job, result)
+			if err != nil {
+				t.Fatalf("captureResponse: %v", err)
+			}
+
+			if captured.Headers.String != tt.wantHeaders {
+				t.Errorf("Headers = %s, want %s", captured.Headers.String, tt.wantHeaders)
+			}
+			if captured.Encoding.String != tt.wantEncoding || captured.Truncated != tt.wantTruncated {
+				t.Errorf("encoding %q truncated %v, want %q truncated %v", captured.Encoding.String, captured.Truncated, tt.wantEncoding, tt.wantTruncated)
+			}
+			if tt.headers != nil && captured.Size.Int64 != int64(len(tt.body)) {
+				t.Errorf("Size = %d, want the whole body's %d", captured.Size.Int64, len(tt.body))
+			}
+
+			body := captured.Body
+			if tt.wantEncoding == EncodingGzip {
+				body = gunzip(t, body)
+			}
+			if !bytes.Equal(body, tt.wantBody) {
+				t.Errorf("stored %d bytes, want %d", len(body), len(tt.wantBody))
+			}
+			if tt.headers != nil && tt.headers.Get("Set-Cookie") != "session=s1" {
+				t.Error("captureResponse changed the response's headers")
+			}
+		})
+	}
+
+	if _, err := captureResponse(db.Job{RedactHeaders: sql.NullString{String: "X-Api-Token", Valid: true}}, Result{Headers: headers}); err == nil {
+		t.Error("captureResponse accepted an unreadable redact_headers")
+	}
+}
+
+func gunzip(t *testing.T, body []byte) []byte {
+	t.Helper()
+
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	return decompressed
+}
+
+func TestRunStoresCapturedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=s1")
+		w.Header().Set("X-Api-Token", "t0k")
+		io.WriteString(w, strings.Repeat("x", 100))
+	}))
+	t.Cleanup(server.Close)
+
+	s := newTestScheduler(t)
+	job := createTestJob(t, s, db.CreateJobParams{
+		Url:           server.URL,
+		MaxBodyBytes:  10,
+		RedactHeaders: sql.NullString{String: `["X-Api-Token"]`, Valid: true},
+	})
+
+	s.runJob(context.Background(), job)
+
+	run := jobRuns(t, s, job)[0]
+	if string(run.ResponseBody) != strings.Repeat("x", 10) || run.ResponseEncoding.String != EncodingIdentity {
+		t.Errorf("stored body %q (%s), want the first 10 bytes", run.ResponseBody, run.ResponseEncoding.String)
+	}
+	if run.ResponseSize.Int64 != 100 || !run.ResponseTruncated.Bool {
+		t.Errorf("size %d truncated %v, want 100 truncated", run.ResponseSize.Int64, run.ResponseTruncated.Bool)
+	}
+
+	var stored http.Header
+	if err := json.Unmarshal([]byte(run.ResponseHeaders.String), &stored); err != nil {
+		t.Fatalf("stored headers: %v", err)
+	}
+	if stored.Get("Set-Cookie") != redactedValue || stored.Get("X-Api-Token") != redactedValue {
+		t.Errorf("stored headers = %v, want credentials redacted", stored)
+	}
+}
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"time"
+)
+
+func (s *Scheduler) saveCertificates(ctx context.Context, job db.Job, runID int64, host string, certs []*x509.Certificate) {
+	for position, cert := range certs {
+		err := s.db.CreateRunCertificate(ctx, db.CreateRunCertificateParams{
+			JobRunID: runID,
+			JobID:    job.ID,
+			Host:     host,
+			Position: int64(position),
+			Subject:  cert.Subject.String(),
+			Issuer:   cert.Issuer.String(),
+			NotAfter: cert.NotAfter.UTC(),
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to store certificate", "job_id", job.ID, "run_id", runID, "error", err)
+			return
+		}
+	}
+}
+
+// checkCertificateExpiry returns an error describing the first certificate
+// in the chain that expires within the job's cert_expiry_days window.
+func checkCertificateExpiry(job db.Job, certs []*x509.Certificate, now time.Time) error {
+	if !job.CertExpiryDays.Valid || len(certs) == 0 {
+		return nil
+	}
+
+	deadline := now.AddDate(0, 0, int(job.CertExpiryDays.Int64))
+
+	for _, cert := range certs {
+		if cert.NotAfter.Before(deadline) {
+			daysLeft := int(cert.NotAfter.Sub(now).Hours() / 24)
+			return fmt.Errorf("certificate %q expires in %d days (%s)",
+				cert.Subject.CommonName, daysLeft, cert.NotAfter.UTC().Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
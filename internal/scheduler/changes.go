@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"lucasbonna/pulse/db"
+	"time"
+	"unicode/utf8"
+)
+
+const maxTrackedBodyBytes = 1 << 20
+
+// errTrackedContent marks a response that track_json_path cannot select
+// from; such runs fail like any other broken expectation.
+var errTrackedContent = errors.New("failed to select tracked content")
+
+// contentChange is what trackContent records on a run.
+type contentChange struct {
+	Hash    sql.NullString
+	Changed bool
+	Diff    sql.NullString
+}
+
+// trackContent hashes the tracked part of a response body (the whole body,
+// or the value at track_json_path) and compares it with the content seen on
+// the previous run. The first run of a job only stores a baseline.
+func (s *Scheduler) trackContent(ctx context.Context, job db.Job, body []byte) (contentChange, error) {
+	var change contentChange
+
+	content := string(body)
+	if job.TrackJsonPath.Valid && job.TrackJsonPath.String != "" {
+		selected, err := selectJSONPath(body, job.TrackJsonPath.String)
+		if err != nil {
+			return change, fmt.Errorf("%w: %w", errTrackedContent, err)
+		}
+		content = selected
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+	change.Hash = sql.NullString{String: hash, Valid: true}
+
+	previous, err := s.db.GetTrackedContent(ctx, job.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return change, err
+	}
+
+	if err == nil && previous.BodyHash == hash {
+		return change, nil
+	}
+
+	if err == nil {
+		change.Changed = true
+		if utf8.ValidString(previous.Content) && utf8.ValidString(content) {
+			change.Diff = sql.NullString{String: unifiedDiff("previous", "current", previous.Content, content), Valid: true}
+		} else {
+			change.Diff = sql.NullString{String: "binary content changed\n", Valid: true}
+		}
+	}
+
+	err = s.db.UpsertTrackedContent(ctx, db.UpsertTrackedContentParams{
+		JobID:     job.ID,
+		BodyHash:  hash,
+		Content:   content,
+		UpdatedAt: time.Now(),
+	})
+	return change, err
+}
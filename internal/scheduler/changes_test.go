@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{"equal", "a\nb\n", "a\nb", ""},
+		{"changed line", "a\nb\nc\n", "a\nB\nc\n", "--- previous\n+++ current\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"appended line", "a\nb\n", "a\nb\nc\n", "--- previous\n+++ current\n@@ -1,2 +1,3 @@\n a\n b\n+c\n"},
+		{"from nothing", "", "a\n", "--- previous\n+++ current\n@@ -0,0 +1,1 @@\n+a\n"},
+		{
+			"distant changes",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"1\nX\n3\n4\n5\n6\n7\n8\n9\n10\n11\nY\n",
+			"--- previous\n+++ current\n@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+Y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("previous", "current", tt.from, tt.to); got != tt.want {
+				t.Errorf("unifiedDiff =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// newBodyServer answers each request with the next of bodies, repeating
+// the last one.
+func newBodyServer(t *testing.T, bodies ...string) *httptest.Server {
+	t.Helper()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		w.Write([]byte(bodies[min(n, len(bodies))-1]))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTrackContent(t *testing.T) {
+	type run struct {
+		status  string
+		changed bool
+		diff    string
+	}
+
+	tests := []struct {
+		name     string
+		jsonPath string
+		bodies   []string
+		want     []run
+	}{
+		{
+			"whole body",
+			"",
+			[]string{"a\nb\n", "a\nb\n", "a\nc\n"},
+			[]run{
+				{"success", false, ""},
+				{"success", false, ""},
+				{"success", true, "--- previous\n+++ current\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n"},
+			},
+		},
+		{
+			"json path",
+			"$.price",
+			[]string{`{"price": 10, "at": 1}`, `{"price": 10, "at": 2}`, `{"price": 12, "at": 3}`},
+			[]run{
+				{"success", false, ""},
+				{"success", false, ""},
+				{"success", true, "--- previous\n+++ current\n@@ -1,1 +1,1 @@\n-10\n+12\n"},
+			},
+		},
+		{
+			"json path not found",
+			"$.price",
+			[]string{`{"price": 10}`, `{"error": "down"}`, `{"price": 10}`},
+			[]run{
+				{"success", false, ""},
+				{"failed", false, ""},
+				{"success", false, ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newBodyServer(t, tt.bodies...)
+			s := newTestScheduler(t)
+			job := createTestJob(t, s, db.CreateJobParams{
+				Url:           server.URL,
+				TrackChanges:  sql.NullBool{Bool: true, Valid: true},
+				TrackJsonPath: sql.NullString{String: tt.jsonPath, Valid: tt.jsonPath != ""},
+			})
+
+			for i, want := range tt.want {
+				s.runJob(context.Background(), job)
+
+				got := jobRuns(t, s, job)[0]
+				if got.Status.String != want.status || got.Changed.Bool != want.changed || got.BodyDiff.String != want.diff {
+					t.Errorf("run %d = %s changed %v diff %q, want %s changed %v diff %q",
+						i+1, got.Status.String, got.Changed.Bool, got.BodyDiff.String, want.status, want.changed, want.diff)
+				}
+				if want.status == "failed" && !strings.HasPrefix(got.Error.String, errTrackedContent.Error()) {
+					t.Errorf("run %d error = %q, want %q", i+1, got.Error.String, errTrackedContent)
+				}
+				if want.status == "success" && !got.BodyHash.Valid {
+					t.Errorf("run %d stored no content hash", i+1)
+				}
+			}
+		})
+	}
+}
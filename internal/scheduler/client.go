@@ -0,0 +1,286 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/telemetry"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clientConfig holds every job setting that changes how connections are
+// made. Jobs with identical settings share one client and its pool.
+type clientConfig struct {
+	ClientCertFile     string
+	ClientKeyFile      string
+	CAFile             string
+	ServerName         string
+	MinVersion         string
+	InsecureSkipVerify bool
+
+	ProxyURL        string
+	FollowRedirects bool
+	MaxRedirects    int64
+	Resolve         string
+}
+
+const defaultMaxRedirects = 10
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func clientConfigFromJob(job db.Job) clientConfig {
+	return clientConfig{
+		ClientCertFile:     job.TlsClientCertFile.String,
+		ClientKeyFile:      job.TlsClientKeyFile.String,
+		CAFile:             job.TlsCaFile.String,
+		ServerName:         job.TlsServerName.String,
+		MinVersion:         job.TlsMinVersion.String,
+		InsecureSkipVerify: job.TlsInsecureSkipVerify.Valid && job.TlsInsecureSkipVerify.Bool,
+
+		ProxyURL:        job.ProxyUrl.String,
+		FollowRedirects: !job.FollowRedirects.Valid || job.FollowRedirects.Bool,
+		MaxRedirects:    job.MaxRedirects.Int64,
+		Resolve:         job.Resolve.String,
+	}
+}
+
+// clientCache hands out one *http.Client per distinct clientConfig.
+type clientCache struct {
+	mutex   sync.Mutex
+	clients map[clientConfig]cachedClient
+}
+
+// cachedClient remembers the TLS files a client was built from, so
+// rotated certificates are picked up by the next run.
+type cachedClient struct {
+	client *http.Client
+	files  string
+}
+
+func newClientCache() *clientCache {
+	return &clientCache{
+		clients: make(map[clientConfig]cachedClient),
+	}
+}
+
+func (c *clientCache) clientFor(job db.Job) (*http.Client, error) {
+	cfg := clientConfigFromJob(job)
+	files := cfg.fileStamp()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, ok := c.clients[cfg]
+	if ok && cached.files == files {
+		return cached.client, nil
+	}
+
+	client, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		cached.client.CloseIdleConnections()
+	}
+	c.clients[cfg] = cachedClient{client: client, files: files}
+	return client, nil
+}
+
+// fileStamp identifies the current contents of the certificate, key and CA
+// files by their size and modification time.
+func (cfg clientConfig) fileStamp() string {
+	var stamp strings.Builder
+	for _, name := range []string{cfg.ClientCertFile, cfg.ClientKeyFile, cfg.CAFile} {
+		if name == "" {
+			continue
+		}
+		if info, err := os.Stat(name); err == nil {
+			fmt.Fprintf(&stamp, "%s:%d:%d;", name, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return stamp.String()
+}
+
+func newHTTPClient(cfg clientConfig) (*http.Client, error) {
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+		TLSClientConfig:     tlsConfig,
+	}
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := ParseProxyURL(cfg.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	overrides, err := cfg.resolveOverrides()
+	if err != nil {
+		return nil, err
+	}
+	if overrides != nil {
+		transport.DialContext = overrideDialer(overrides)
+	}
+
+	return &http.Client{
+		Transport:     telemetry.Transport(transport),
+		CheckRedirect: cfg.checkRedirect,
+	}, nil
+}
+
+// resolveOverrides returns the job's resolve entries keyed by "host:port",
+// or nil when it has none.
+func (cfg clientConfig) resolveOverrides() (map[string]string, error) {
+	if cfg.Resolve == "" {
+		return nil, nil
+	}
+
+	var entries []string
+	if err := json.Unmarshal([]byte(cfg.Resolve), &entries); err != nil {
+		return nil, fmt.Errorf("invalid resolve overrides: %w", err)
+	}
+	return ParseResolve(entries)
+}
+
+// overrideDialer dials the overridden address of any "host:port" found in
+// overrides and every other address as given.
+func overrideDialer(overrides map[string]string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if override, ok := overrides[addr]; ok {
+			addr = override
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+func (cfg clientConfig) checkRedirect(req *http.Request, via []*http.Request) error {
+	if !cfg.FollowRedirects {
+		return http.ErrUseLastResponse
+	}
+
+	maxRedirects := cfg.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	if int64(len(via)) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	return nil
+}
+
+func (cfg clientConfig) tlsConfig() (*tls.Config, error) {
+	if cfg.ClientCertFile == "" && cfg.ClientKeyFile == "" && cfg.CAFile == "" &&
+		cfg.ServerName == "" && cfg.MinVersion == "" && !cfg.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.MinVersion != "" {
+		version, ok := tlsVersions[cfg.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS version %q", cfg.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// ParseProxyURL validates a job proxy. HTTP, HTTPS and SOCKS5 proxies are
+// supported by net/http directly.
+func ParseProxyURL(raw string) (*url.URL, error) {
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", raw)
+	}
+
+	return proxyURL, nil
+}
+
+// ParseResolve turns curl-style "host:port:address" entries into a map of
+// dial address overrides keyed by "host:port".
+func ParseResolve(entries []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(entries))
+
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid resolve entry %q, expected host:port:address", entry)
+		}
+
+		host, port := parts[0], parts[1]
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return nil, fmt.Errorf("invalid port in resolve entry %q", entry)
+		}
+
+		address := strings.TrimSuffix(strings.TrimPrefix(parts[2], "["), "]")
+		if net.ParseIP(address) == nil {
+			return nil, fmt.Errorf("invalid address in resolve entry %q", entry)
+		}
+
+		overrides[net.JoinHostPort(host, port)] = net.JoinHostPort(address, port)
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"time"
+)
+
+// replay is a dead letter being re-sent.
+type replay struct {
+	deadLetterID int64
+	runID        int64
+	request      *SentRequest
+}
+
+type replayKey struct{}
+
+func withReplay(ctx context.Context, r replay) context.Context {
+	return context.WithValue(ctx, replayKey{}, r)
+}
+
+// replayFrom returns the stored request when the run executed with ctx
+// replays a dead letter.
+func replayFrom(ctx context.Context) (*SentRequest, bool) {
+	r, ok := ctx.Value(replayKey{}).(replay)
+	if !ok {
+		return nil, false
+	}
+	return r.request, true
+}
+
+// deadLettered reports whether a failed run of job keeps its request as a
+// dead letter: http jobs opt in by retrying failed deliveries.
+func deadLettered(job db.Job) bool {
+	return job.Type == JobTypeHTTP && job.RetryAttempts.Int64 > 0
+}
+
+// saveDeadLetter stores the request of a run whose delivery failed for good.
+func (s *Scheduler) saveDeadLetter(ctx context.Context, job db.Job, runID int64, result Result, runErr error) {
+	header, err := json.Marshal(result.Request.Header)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to encode dead letter headers", "job_id", job.ID, "run_id", runID, "error", err)
+		return
+	}
+
+	letter, err := s.db.CreateDeadLetter(ctx, db.CreateDeadLetterParams{
+		JobID:        job.ID,
+		JobRunID:     runID,
+		Method:       result.Request.Method,
+		Url:          result.Request.URL,
+		Headers:      string(header),
+		Body:         sql.NullString{String: result.Request.Body, Valid: result.Request.Body != ""},
+		Attempts:     int64(result.Attempts),
+		ResponseCode: sql.NullInt64{Int64: int64(result.StatusCode), Valid: result.StatusCode != 0},
+		Error:        runErr.Error(),
+		CreatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to store dead letter", "job_id", job.ID, "run_id", runID, "error", err)
+		return
+	}
+
+	slog.InfoContext(ctx, "delivery dead-lettered", "job_id", job.ID, "run_id", runID, "dead_letter_id", letter.ID)
+}
+
+// linkReplay records runID as the latest replay of the dead letter carried
+// by ctx.
+func (s *Scheduler) linkReplay(ctx context.Context, runID int64) {
+	r, ok := ctx.Value(replayKey{}).(replay)
+	if !ok {
+		return
+	}
+
+	err := s.db.LinkDeadLetterReplay(ctx, db.LinkDeadLetterReplayParams{
+		ID:          r.deadLetterID,
+		ReplayRunID: sql.NullInt64{Int64: runID, Valid: true},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to link replay", "dead_letter_id", r.deadLetterID, "run_id", runID, "error", err)
+	}
+}
+
+// markReplayed records that the dead letter carried by ctx was delivered.
+func (s *Scheduler) markReplayed(ctx context.Context) {
+	r, ok := ctx.Value(replayKey{}).(replay)
+	if !ok {
+		return
+	}
+
+	err := s.db.MarkDeadLetterReplayed(ctx, db.MarkDeadLetterReplayedParams{
+		ID:         r.deadLetterID,
+		ReplayedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to mark dead letter replayed", "dead_letter_id", r.deadLetterID, "error", err)
+	}
+}
+
+// Replay re-sends the stored requests of letters, all dead letters of job,
+// one after another in the background. Each replay is a regular run of the
+// job, linked to the run whose delivery failed. It returns ErrJobRunning
+// when a run of the job is in progress.
+func (s *Scheduler) Replay(ctx context.Context, job db.Job, letters []db.DeadLetter) error {
+	replays := make([]replay, 0, len(letters))
+	for _, letter := range letters {
+		r := replay{
+			deadLetterID: letter.ID,
+			runID:        letter.JobRunID,
+			request: &SentRequest{
+				Method: letter.Method,
+				URL:    letter.Url,
+				Body:   letter.Body.String,
+			},
+		}
+		if err := json.Unmarshal([]byte(letter.Headers), &r.request.Header); err != nil {
+			return err
+		}
+		replays = append(replays, r)
+	}
+
+	if !s.claimJob(job.ID) {
+		return ErrJobRunning
+	}
+
+	// the replays outlive the request that started them but keep its trace
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer s.markJobAsRunning(job.ID, false)
+
+		for i, r := range replays {
+			if i > 0 {
+				// the previous replay moved the job's schedule and breaker
+				current, err := s.db.GetJobByID(ctx, job.ID)
+				if err != nil {
+					slog.ErrorContext(ctx, "failed to reload job for replay", "job_id", job.ID, "error", err)
+					return
+				}
+				job = current
+			}
+			s.runJob(withReplay(ctx, r), job)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// listDeadLetters returns the dead letters of job, newest first.
+func listDeadLetters(t *testing.T, s *Scheduler, job db.Job) []db.DeadLetter {
+	t.Helper()
+
+	letters, err := s.db.ListDeadLetters(context.Background(), db.ListDeadLettersParams{JobID: job.ID, Limit: 100})
+	if err != nil {
+		t.Fatalf("list dead letters: %v", err)
+	}
+	return letters
+}
+
+// replayAndWait replays letters and waits for the replays to finish.
+func replayAndWait(t *testing.T, s *Scheduler, job db.Job, letters ...db.DeadLetter) {
+	t.Helper()
+
+	if err := s.Replay(context.Background(), job, letters); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	for deadline := time.Now().Add(10 * time.Second); s.isJobRunning(job.ID); {
+		if time.Now().After(deadline) {
+			t.Fatal("replay did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDeadLetterReplay(t *testing.T) {
+	var status atomic.Int64
+	status.Store(http.StatusServiceUnavailable)
+	var authorization atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(server.Close)
+
+	s := newTestScheduler(t)
+	job := createTestJob(t, s, db.CreateJobParams{
+		Method:        "POST",
+		Url:           server.URL,
+		Headers:       sql.NullString{String: "Authorization: Bearer s3cret", Valid: true},
+		Body:          sql.NullString{String: `{"order": 17}`, Valid: true},
+		RetryAttempts: sql.NullInt64{Int64: 1, Valid: true},
+	})
+	ctx := context.Background()
+
+	s.runJob(ctx, job)
+
+	letters := listDeadLetters(t, s, job)
+	if len(letters) != 1 {
+		t.Fatalf("stored %d dead letters, want 1", len(letters))
+	}
+	letter := letters[0]
+	failedRun := jobRuns(t, s, job)[0]
+	if letter.JobRunID != failedRun.ID || letter.Attempts != 2 || letter.ResponseCode.Int64 != 503 ||
+		letter.Body.String != `{"order": 17}` {
+		t.Errorf("dead letter = %+v, want run %d's request after 2 attempts", letter, failedRun.ID)
+	}
+
+	// a replay that fails again keeps the dead letter pending
+	replayAndWait(t, s, job, letter)
+
+	replay := jobRuns(t, s, job)[0]
+	if replay.ReplayOfRunID.Int64 != failedRun.ID || replay.Status.String != "failed" {
+		t.Errorf("replay run = %+v, want a failed replay of run %d", replay, failedRun.ID)
+	}
+	letters = listDeadLetters(t, s, job)
+	if len(letters) != 1 {
+		t.Fatalf("a failed replay left %d dead letters, want 1", len(letters))
+	}
+	if letters[0].ReplayRunID.Int64 != replay.ID || letters[0].ReplayedAt.Valid {
+		t.Errorf("dead letter after a failed replay = %+v, want pending and linked to run %d", letters[0], replay.ID)
+	}
+
+	// a delivered replay marks it replayed
+	status.Store(http.StatusOK)
+	replayAndWait(t, s, job, letters[0])
+
+	replay = jobRuns(t, s, job)[0]
+	letter = listDeadLetters(t, s, job)[0]
+	if replay.ReplayOfRunID.Int64 != failedRun.ID || replay.Status.String != "success" {
+		t.Errorf("replay run = %+v, want a successful replay of run %d", replay, failedRun.ID)
+	}
+	if letter.ReplayRunID.Int64 != replay.ID || !letter.ReplayedAt.Valid {
+		t.Errorf("dead letter after a delivered replay = %+v, want it replayed by run %d", letter, replay.ID)
+	}
+	if got := authorization.Load(); got != "Bearer s3cret" {
+		t.Errorf("replay sent Authorization %q, want the stored value", got)
+	}
+}
+
+func TestRedactRequestHeaders(t *testing.T) {
+	headers := http.Header{
+		"Authorization": {"Bearer s3cret"},
+		"Cookie":        {"session=1"},
+		"X-Api-Key":     {"k"},
+		"X-Plain":       {"p"},
+	}
+	job := db.Job{RedactHeaders: sql.NullString{String: `["x-api-key"]`, Valid: true}}
+
+	redacted, err := RedactRequestHeaders(job, headers)
+	if err != nil {
+		t.Fatalf("RedactRequestHeaders: %v", err)
+	}
+	for name, want := range map[string]string{
+		"Authorization": redactedValue,
+		"Cookie":        redactedValue,
+		"X-Api-Key":     redactedValue,
+		"X-Plain":       "p",
+	} {
+		if got := redacted.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if headers.Get("Authorization") != "Bearer s3cret" {
+		t.Error("RedactRequestHeaders changed the stored headers")
+	}
+}
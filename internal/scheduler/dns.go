@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"lucasbonna/pulse/db"
+	"net"
+	"strings"
+	"time"
+)
+
+// dnsExecutor resolves the job's host name and, when dns_expected is set,
+// requires that value to be among the returned records.
+type dnsExecutor struct{}
+
+func (e *dnsExecutor) Execute(ctx context.Context, job db.Job) (Result, error) {
+	result := Result{Host: job.Url}
+
+	resolver := net.DefaultResolver
+	if job.DnsServer.Valid && job.DnsServer.String != "" {
+		resolver = newResolver(job.DnsServer.String)
+	}
+
+	recordType := "A"
+	if job.DnsRecordType.Valid && job.DnsRecordType.String != "" {
+		recordType = job.DnsRecordType.String
+	}
+
+	start := time.Now()
+	records, err := lookup(ctx, resolver, recordType, job.Url)
+	result.Timings.DNSLookup = time.Since(start)
+	result.Timings.Total = result.Timings.DNSLookup
+	if err != nil {
+		return result, fmt.Errorf("%s lookup failed: %w", recordType, err)
+	}
+
+	if len(records) == 0 {
+		return result, fmt.Errorf("no %s records found for %s", recordType, job.Url)
+	}
+
+	if job.DnsExpected.Valid && job.DnsExpected.String != "" {
+		expected := normalizeRecord(job.DnsExpected.String)
+		for _, record := range records {
+			if normalizeRecord(record) == expected {
+				return result, nil
+			}
+		}
+		return result, fmt.Errorf("expected %s record %q, got %s",
+			recordType, job.DnsExpected.String, strings.Join(records, ", "))
+	}
+
+	return result, nil
+}
+
+// newResolver builds a resolver that sends every query to server
+// (host or host:port, port 53 by default).
+func newResolver(server string) *net.Resolver {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+}
+
+func lookup(ctx context.Context, resolver *net.Resolver, recordType, name string) ([]string, error) {
+	var records []string
+
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			records = append(records, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, cname)
+	case "MX":
+		mxs, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			records = append(records, mx.Host)
+		}
+	case "TXT":
+		txts, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, txts...)
+	case "NS":
+		nss, err := resolver.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			records = append(records, ns.Host)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record type %q", recordType)
+	}
+
+	return records, nil
+}
+
+func normalizeRecord(record string) string {
+	return strings.ToLower(strings.TrimSuffix(record, "."))
+}
@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// newDNSServer answers queries on a local UDP port from zone, keyed by the
+// fully qualified name, and with NXDOMAIN for other names. It returns the
+// server's address.
+func newDNSServer(t *testing.T, zone map[string][]dnsmessage.ResourceBody) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+			question := query.Questions[0]
+
+			answer := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true},
+				Questions: query.Questions,
+			}
+			bodies, ok := zone[strings.ToLower(question.Name.String())]
+			if !ok {
+				answer.RCode = dnsmessage.RCodeNameError
+			}
+			for _, body := range bodies {
+				if resourceType(body) != question.Type {
+					continue
+				}
+				answer.Answers = append(answer.Answers, dnsmessage.Resource{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Type: question.Type, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   body,
+				})
+			}
+
+			packed, err := answer.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func resourceType(body dnsmessage.ResourceBody) dnsmessage.Type {
+	switch body.(type) {
+	case *dnsmessage.AResource:
+		return dnsmessage.TypeA
+	case *dnsmessage.MXResource:
+		return dnsmessage.TypeMX
+	case *dnsmessage.TXTResource:
+		return dnsmessage.TypeTXT
+	}
+	return 0
+}
+
+func TestDNSExecutor(t *testing.T) {
+	server := newDNSServer(t, map[string][]dnsmessage.ResourceBody{
+		"pulse.test.": {
+			&dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+			&dnsmessage.AResource{A: [4]byte{192, 0, 2, 2}},
+			&dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mail.pulse.test.")},
+			&dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}},
+		},
+		"empty.pulse.test.": {},
+	})
+
+	tests := []struct {
+		name       string
+		host       string
+		recordType string
+		expected   string
+		wantErr    string
+	}{
+		{"any A record", "pulse.test", "", "", ""},
+		{"expected A record", "pulse.test", "A", "192.0.2.2", ""},
+		{"unexpected A record", "pulse.test", "A", "192.0.2.9", `expected A record "192.0.2.9", got 192.0.2.1, 192.0.2.2`},
+		{"MX without trailing dot", "pulse.test", "MX", "MAIL.pulse.test", ""},
+		{"expected TXT record", "pulse.test", "TXT", "v=spf1 -all", ""},
+		{"unexpected TXT record", "pulse.test", "TXT", "v=spf1 ~all", "expected TXT record"},
+		{"no records", "empty.pulse.test", "A", "", "A lookup failed"},
+		{"unknown name", "missing.pulse.test", "A", "", "A lookup failed"},
+		{"unsupported type", "pulse.test", "SRV", "", `unsupported record type "SRV"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			job := db.Job{
+				Url:           tt.host,
+				DnsServer:     sql.NullString{String: server, Valid: true},
+				DnsRecordType: sql.NullString{String: tt.recordType, Valid: tt.recordType != ""},
+				DnsExpected:   sql.NullString{String: tt.expected, Valid: tt.expected != ""},
+			}
+			_, err := (&dnsExecutor{}).Execute(ctx, job)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Execute: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"lucasbonna/pulse/db"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	JobTypeHTTP       = "http"
+	JobTypeTCP        = "tcp"
+	JobTypeDNS        = "dns"
+	JobTypeGRPCHealth = "grpc_health"
+	JobTypeSteps      = "steps"
+	JobTypeWebSocket  = "websocket"
+	JobTypeSSE        = "sse"
+	JobTypeHeartbeat  = "heartbeat"
+)
+
+// Result is what an executor reports for a single run. Fields that do not
+// apply to a check type are left zero.
+type Result struct {
+	StatusCode   int
+	Timings      timings
+	Host         string
+	Certificates []*x509.Certificate
+	Steps        []StepResult
+	// Headers and Body are only set by executors that capture the
+	// response; Body is cut at the job's capture limit and BodySize is the
+	// number of bytes the whole body had.
+	Headers  http.Header
+	Body     []byte
+	BodySize int64
+	// Request and Attempts are set by the http executor: the request as
+	// sent and how many times it was.
+	Request  *SentRequest
+	Attempts int
+	// RetryAfter is how long a target answering 429 or 503 asked to be
+	// left alone.
+	RetryAfter time.Duration
+}
+
+// Executor performs one check for a job. A returned error marks the run as
+// failed; the partial Result is still recorded.
+type Executor interface {
+	Execute(ctx context.Context, job db.Job) (Result, error)
+}
+
+func (s *Scheduler) execute(ctx context.Context, job db.Job) (Result, error) {
+	executor, ok := s.executors[job.Type]
+	if !ok {
+		return Result{}, fmt.Errorf("unsupported job type %q", job.Type)
+	}
+
+	return executor.Execute(ctx, job)
+}
+
+// ValidateTarget checks that a job's url field makes sense for its type:
+// a full URL for http and sse, a ws or wss URL for websocket, host:port for
+// tcp and grpc_health, a bare host name for dns and nothing for steps and
+// heartbeat.
+func ValidateTarget(jobType, target string) error {
+	switch jobType {
+	case JobTypeHTTP, JobTypeSSE:
+		if !hasScheme(target, "http", "https") {
+			return fmt.Errorf("url must be an absolute http or https URL")
+		}
+	case JobTypeWebSocket:
+		if !hasScheme(target, "ws", "wss") {
+			return fmt.Errorf("url must be an absolute ws or wss URL")
+		}
+	case JobTypeTCP, JobTypeGRPCHealth:
+		host, port, err := net.SplitHostPort(target)
+		if err != nil || host == "" {
+			return fmt.Errorf("url must be host:port for %s jobs", jobType)
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("url has an invalid port")
+		}
+	case JobTypeSteps:
+		// steps carry their own URLs; the job url is unused
+	case JobTypeHeartbeat:
+		if target != "" {
+			return fmt.Errorf("heartbeat jobs are pinged and take no url")
+		}
+	case JobTypeDNS:
+		if target == "" || len(target) > 253 || net.ParseIP(target) != nil {
+			return fmt.Errorf("url must be a host name for dns jobs")
+		}
+	default:
+		return fmt.Errorf("unsupported job type %q", jobType)
+	}
+
+	return nil
+}
+
+func hasScheme(target string, schemes ...string) bool {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+func describeTarget(job db.Job) string {
+	switch job.Type {
+	case JobTypeHTTP:
+		return fmt.Sprintf("%s %s", job.Method, job.Url)
+	case JobTypeSteps:
+		return "multi-step transaction"
+	case JobTypeHeartbeat:
+		return "heartbeat"
+	}
+	return job.Url
+}
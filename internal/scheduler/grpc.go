@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"lucasbonna/pulse/db"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// grpcHealthExecutor calls the standard grpc.health.v1.Health/Check RPC and
+// succeeds only when the service reports SERVING. An empty grpc_service
+// checks the server as a whole.
+type grpcHealthExecutor struct{}
+
+func (e *grpcHealthExecutor) Execute(ctx context.Context, job db.Job) (Result, error) {
+	result := Result{Host: job.Url}
+
+	creds := insecure.NewCredentials()
+	if job.GrpcTls.Valid && job.GrpcTls.Bool {
+		tlsConfig, err := clientConfigFromJob(job).tlsConfig()
+		if err != nil {
+			return result, err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	target := job.Url
+	options := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+
+	// a resolve override skips name resolution; the authority, and so the
+	// TLS server name, stays the job's host
+	overrides, err := clientConfigFromJob(job).resolveOverrides()
+	if err != nil {
+		return result, err
+	}
+	if override, ok := overrides[job.Url]; ok {
+		target = "passthrough:///" + override
+		options = append(options, grpc.WithAuthority(job.Url))
+	}
+
+	conn, err := grpc.NewClient(target, options...)
+	if err != nil {
+		return result, fmt.Errorf("failed to create grpc client: %w", err)
+	}
+	defer conn.Close()
+
+	// propagate the run's trace context as request metadata
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(carrier))
+
+	start := time.Now()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: job.GrpcService.String,
+	})
+	result.Timings.TimeToFirstByte = time.Since(start)
+	result.Timings.Total = result.Timings.TimeToFirstByte
+	if err != nil {
+		return result, fmt.Errorf("health check failed: %w", err)
+	}
+
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return result, fmt.Errorf("service reported %s", resp.GetStatus())
+	}
+
+	return result, nil
+}
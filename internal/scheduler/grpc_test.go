@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// newHealthServer serves grpc.health.v1 on a local port, with orders
+// SERVING and billing NOT_SERVING, and returns its address.
+func newHealthServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	status := health.NewServer()
+	status.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+	status.SetServingStatus("billing", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, status)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestGRPCHealthExecutor(t *testing.T) {
+	addr := newHealthServer(t)
+
+	tests := []struct {
+		name    string
+		url     string
+		service string
+		wantErr string
+	}{
+		{"whole server", addr, "", ""},
+		{"serving", addr, "orders", ""},
+		{"not serving", addr, "billing", "service reported NOT_SERVING"},
+		{"unknown service", addr, "shipping", "NotFound"},
+		{"unreachable", closedAddress(t), "", "health check failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			job := db.Job{
+				Url:         tt.url,
+				GrpcService: sql.NullString{String: tt.service, Valid: tt.service != ""},
+			}
+			_, err := (&grpcHealthExecutor{}).Execute(ctx, job)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Execute: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
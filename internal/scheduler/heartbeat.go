@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/metrics"
+	"time"
+)
+
+const (
+	PingSuccess = "success"
+	PingStart   = "start"
+	PingFail    = "fail"
+)
+
+var (
+	ErrJobInactive = errors.New("job is inactive")
+	ErrJobRunning  = errors.New("job is already running")
+)
+
+// heartbeatExecutor runs when a heartbeat job's deadline passes. Heartbeat
+// jobs are passive: every ping pushes next_run_at to a full period plus
+// grace ahead, so reaching it means a ping is missing.
+type heartbeatExecutor struct{}
+
+func (e *heartbeatExecutor) Execute(ctx context.Context, job db.Job) (Result, error) {
+	if job.HeartbeatStartedAt.Valid {
+		return Result{}, fmt.Errorf("started at %s but never reported finishing",
+			job.HeartbeatStartedAt.Time.UTC().Format(time.RFC3339))
+	}
+	period := time.Duration(job.IntervalSeconds+job.HeartbeatGraceSeconds.Int64) * time.Second
+	return Result{}, fmt.Errorf("no ping received within %s", period)
+}
+
+// heartbeatMissed closes the period of a heartbeat job whose deadline passed
+// at now: a start ping without its finish is forgotten, and the next ping
+// is due a full period plus grace later.
+func (s *Scheduler) heartbeatMissed(ctx context.Context, job db.Job, now time.Time) {
+	err := s.db.FinishJobHeartbeat(ctx, db.FinishJobHeartbeatParams{
+		ID:        job.ID,
+		NextRunAt: sql.NullTime{Time: HeartbeatDeadline(now, job.IntervalSeconds, job.HeartbeatGraceSeconds.Int64), Valid: true},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to move heartbeat deadline", "job_id", job.ID, "error", err)
+	}
+}
+
+// HeartbeatDeadline is when a heartbeat job pinged at from fails without
+// another ping: one period plus the grace time later.
+func HeartbeatDeadline(from time.Time, intervalSeconds int64, graceSeconds int64) time.Time {
+	return from.Add(time.Duration(intervalSeconds+graceSeconds) * time.Second).UTC()
+}
+
+// RecordPing handles a ping to a heartbeat job. A start ping only notes the
+// time; success and fail pings store a finished run, timed from the start
+// ping when there was one, and move the deadline a full period ahead. It
+// returns ErrJobInactive for paused jobs and ErrJobRunning while the run
+// recording a missed deadline is in progress.
+func (s *Scheduler) RecordPing(ctx context.Context, job db.Job, kind string) error {
+	if !s.claimJob(job.ID) {
+		return ErrJobRunning
+	}
+	defer s.markJobAsRunning(job.ID, false)
+
+	// a run that finished since the job was loaded may have changed it
+	job, err := s.db.GetJobByID(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	if !job.Active.Bool {
+		return ErrJobInactive
+	}
+
+	now := time.Now().UTC()
+
+	if kind == PingStart {
+		return s.db.StartJobHeartbeat(ctx, db.StartJobHeartbeatParams{
+			ID:                 job.ID,
+			HeartbeatStartedAt: sql.NullTime{Time: now, Valid: true},
+		})
+	}
+
+	startTime := now
+	if job.HeartbeatStartedAt.Valid {
+		startTime = job.HeartbeatStartedAt.Time
+	}
+
+	status := "success"
+	runError := sql.NullString{}
+	if kind == PingFail {
+		status = "failed"
+		runError = sql.NullString{String: "failure reported by ping", Valid: true}
+	}
+
+	jobRun, err := s.db.CreateJobRun(ctx, db.CreateJobRunParams{
+		JobID:     job.ID,
+		Status:    sql.NullString{String: status, Valid: true},
+		StartedAt: sql.NullTime{Time: startTime, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	duration := now.Sub(startTime)
+	err = s.db.UpdateJobRun(ctx, db.UpdateJobRunParams{
+		ID:         jobRun.ID,
+		Status:     sql.NullString{String: status, Valid: true},
+		FinishedAt: sql.NullTime{Time: now, Valid: true},
+		TotalMs:    durationMs(duration),
+		Error:      runError,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.db.FinishJobHeartbeat(ctx, db.FinishJobHeartbeatParams{
+		ID:        job.ID,
+		NextRunAt: sql.NullTime{Time: HeartbeatDeadline(now, job.IntervalSeconds, job.HeartbeatGraceSeconds.Int64), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recordRollup(ctx, job.ID, startTime, status, duration)
+	metrics.ObserveRun(job.ID, job.Name, job.Type, status, duration)
+
+	s.runFinished(ctx, job, FinishedRun{
+		ID:         jobRun.ID,
+		Status:     status,
+		Error:      runError.String,
+		StartedAt:  startTime,
+		FinishedAt: now,
+	})
+
+	slog.InfoContext(ctx, "heartbeat received", "job_id", job.ID, "run_id", jobRun.ID, "status", status)
+	return nil
+}
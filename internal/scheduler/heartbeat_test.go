@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"lucasbonna/pulse/db"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newHeartbeatJob(t *testing.T, s *Scheduler, active bool) db.Job {
+	t.Helper()
+
+	return createTestJob(t, s, db.CreateJobParams{
+		Type:                  JobTypeHeartbeat,
+		IntervalSeconds:       60,
+		HeartbeatGraceSeconds: sql.NullInt64{Int64: 30, Valid: true},
+		Active:                sql.NullBool{Bool: active, Valid: true},
+	})
+}
+
+// reloadJob returns job as currently stored.
+func reloadJob(t *testing.T, s *Scheduler, job db.Job) db.Job {
+	t.Helper()
+
+	job, err := s.db.GetJobByID(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("reload job: %v", err)
+	}
+	return job
+}
+
+// assertDeadline checks that job's next run is a period plus grace after
+// a moment between before and now.
+func assertDeadline(t *testing.T, job db.Job, before time.Time) {
+	t.Helper()
+
+	earliest := HeartbeatDeadline(before, job.IntervalSeconds, job.HeartbeatGraceSeconds.Int64).Truncate(time.Second)
+	latest := HeartbeatDeadline(time.Now(), job.IntervalSeconds, job.HeartbeatGraceSeconds.Int64)
+	if next := job.NextRunAt.Time; next.Before(earliest) || next.After(latest) {
+		t.Errorf("next_run_at = %s, want between %s and %s", next, earliest, latest)
+	}
+}
+
+func TestRecordPing(t *testing.T) {
+	tests := []struct {
+		name       string
+		pings      []string
+		wantStatus string
+		wantError  string
+	}{
+		{"success", []string{PingSuccess}, "success", ""},
+		{"start and success", []string{PingStart, PingSuccess}, "success", ""},
+		{"fail", []string{PingFail}, "failed", "failure reported by ping"},
+		{"start and fail", []string{PingStart, PingFail}, "failed", "failure reported by ping"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScheduler(t)
+			job := newHeartbeatJob(t, s, true)
+			ctx := context.Background()
+			before := time.Now()
+
+			for _, ping := range tt.pings {
+				if err := s.RecordPing(ctx, job, ping); err != nil {
+					t.Fatalf("RecordPing(%s): %v", ping, err)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			runs := jobRuns(t, s, job)
+			if len(runs) != 1 {
+				t.Fatalf("stored %d runs, want 1", len(runs))
+			}
+			run := runs[0]
+			if run.Status.String != tt.wantStatus || run.Error.String != tt.wantError {
+				t.Errorf("run = %s %q, want %s %q", run.Status.String, run.Error.String, tt.wantStatus, tt.wantError)
+			}
+
+			timed := tt.pings[0] == PingStart
+			if took := run.FinishedAt.Time.Sub(run.StartedAt.Time); timed != (took >= 10*time.Millisecond) {
+				t.Errorf("run took %s, want it timed from a start ping: %v", took, timed)
+			}
+
+			job = reloadJob(t, s, job)
+			if job.HeartbeatStartedAt.Valid {
+				t.Error("heartbeat_started_at was kept after the run finished")
+			}
+			assertDeadline(t, job, before)
+		})
+	}
+}
+
+func TestRecordPingRejections(t *testing.T) {
+	s := newTestScheduler(t)
+	ctx := context.Background()
+
+	paused := newHeartbeatJob(t, s, false)
+	if err := s.RecordPing(ctx, paused, PingSuccess); !errors.Is(err, ErrJobInactive) {
+		t.Errorf("ping to a paused job: error = %v, want %v", err, ErrJobInactive)
+	}
+
+	running := newHeartbeatJob(t, s, true)
+	s.claimJob(running.ID)
+	if err := s.RecordPing(ctx, running, PingSuccess); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("ping during a run: error = %v, want %v", err, ErrJobRunning)
+	}
+	s.markJobAsRunning(running.ID, false)
+
+	for _, job := range []db.Job{paused, running} {
+		if runs := jobRuns(t, s, job); len(runs) != 0 {
+			t.Errorf("job %d: rejected pings stored %d runs", job.ID, len(runs))
+		}
+	}
+}
+
+func TestHeartbeatMissedDeadline(t *testing.T) {
+	s := newTestScheduler(t)
+	job := newHeartbeatJob(t, s, true)
+	ctx := context.Background()
+
+	if err := s.RecordPing(ctx, job, PingStart); err != nil {
+		t.Fatalf("start ping: %v", err)
+	}
+
+	// the deadline passes after the start ping
+	before := time.Now()
+	s.runJob(ctx, reloadJob(t, s, job))
+
+	runs := jobRuns(t, s, job)
+	if len(runs) != 1 || runs[0].Status.String != "failed" || !strings.Contains(runs[0].Error.String, "never reported finishing") {
+		t.Fatalf("runs after the first miss = %+v, want one unfinished start", runs)
+	}
+	job = reloadJob(t, s, job)
+	if job.HeartbeatStartedAt.Valid {
+		t.Error("a missed deadline kept the start ping")
+	}
+	assertDeadline(t, job, before)
+
+	// the next miss no longer blames the forgotten start
+	s.runJob(ctx, job)
+	runs = jobRuns(t, s, job)
+	if len(runs) != 2 || runs[0].Error.String != "no ping received within 1m30s" {
+		t.Fatalf("second miss recorded %q, want a missing ping", runs[0].Error.String)
+	}
+
+	// and a plain ping is not timed from it
+	if err := s.RecordPing(ctx, job, PingSuccess); err != nil {
+		t.Fatalf("success ping: %v", err)
+	}
+	run := jobRuns(t, s, job)[0]
+	if took := run.FinishedAt.Time.Sub(run.StartedAt.Time); took != 0 {
+		t.Errorf("success ping after a miss took %s, want 0", took)
+	}
+}
@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"lucasbonna/pulse/internal/config"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// hostSweepInterval is how often idle throttles and expired pauses are
+// dropped.
+const hostSweepInterval = time.Minute
+
+// hostLimiter throttles requests per target host, across all jobs, so
+// jobs sharing an upstream do not trip its own rate limiting. It also
+// remembers hosts that asked every job to back off.
+type hostLimiter struct {
+	limits config.HostLimits
+
+	mutex     sync.Mutex
+	hosts     map[string]*hostThrottle
+	paused    map[string]time.Time
+	lastSweep time.Time
+}
+
+// hostThrottle holds one host's state; either part is nil when unlimited.
+type hostThrottle struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+	// users counts the requests waiting for or holding the throttle; it is
+	// guarded by the hostLimiter's mutex.
+	users int
+}
+
+func newHostLimiter(limits config.HostLimits) *hostLimiter {
+	return &hostLimiter{
+		limits: limits,
+		hosts:  make(map[string]*hostThrottle),
+		paused: make(map[string]time.Time),
+	}
+}
+
+// acquire waits until a request to host may start, or ctx ends. The
+// returned release must be called once the response has been read.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	throttle := l.throttleFor(strings.ToLower(host))
+	if throttle == nil {
+		return func() {}, nil
+	}
+
+	if throttle.slots != nil {
+		select {
+		case throttle.slots <- struct{}{}:
+		case <-ctx.Done():
+			l.leave(throttle)
+			return nil, fmt.Errorf("waiting for a connection slot to %s: %w", host, ctx.Err())
+		}
+	}
+	release := func() {
+		if throttle.slots != nil {
+			<-throttle.slots
+		}
+		l.leave(throttle)
+	}
+
+	if throttle.limiter != nil {
+		if err := throttle.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, fmt.Errorf("waiting for the rate limit of %s: %w", host, err)
+		}
+	}
+
+	return release, nil
+}
+
+// throttleFor returns the throttle of host, counting the caller as one of
+// its users, or nil when host is unlimited.
+func (l *hostLimiter) throttleFor(host string) *hostThrottle {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.sweep()
+
+	throttle, ok := l.hosts[host]
+	if !ok {
+		limit := l.limitFor(host)
+		if limit.RequestsPerSecond <= 0 && limit.MaxConcurrent <= 0 {
+			return nil
+		}
+
+		throttle = &hostThrottle{}
+		if limit.RequestsPerSecond > 0 {
+			throttle.limiter = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), 1)
+		}
+		if limit.MaxConcurrent > 0 {
+			throttle.slots = make(chan struct{}, limit.MaxConcurrent)
+		}
+		l.hosts[host] = throttle
+	}
+
+	throttle.users++
+	return throttle
+}
+
+func (l *hostLimiter) leave(throttle *hostThrottle) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	throttle.users--
+}
+
+// sweep drops, at most once per hostSweepInterval, the throttles nobody
+// uses whose limiter has refilled, as they behave like new ones, and the
+// pauses that ended. The caller holds the mutex.
+func (l *hostLimiter) sweep() {
+	now := time.Now()
+	if now.Sub(l.lastSweep) < hostSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for host, throttle := range l.hosts {
+		if throttle.users > 0 {
+			continue
+		}
+		if throttle.limiter != nil && throttle.limiter.TokensAt(now) < float64(throttle.limiter.Burst()) {
+			continue
+		}
+		delete(l.hosts, host)
+	}
+	for host, until := range l.paused {
+		if !until.After(now) {
+			delete(l.paused, host)
+		}
+	}
+}
+
+func (l *hostLimiter) limitFor(host string) config.HostLimit {
+	for _, rule := range l.limits.Rules {
+		if matched, _ := path.Match(rule.Pattern, host); matched {
+			return rule.HostLimit
+		}
+	}
+	return l.limits.Default
+}
+
+// pause keeps jobs away from host until the given time.
+func (l *hostLimiter) pause(host string, until time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if until.After(l.paused[host]) {
+		l.paused[host] = until
+	}
+}
+
+// resumeAt returns when a pause of host ends, or the zero time when it is
+// not paused.
+func (l *hostLimiter) resumeAt(host string) time.Time {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	until, ok := l.paused[host]
+	if ok && !until.After(time.Now()) {
+		delete(l.paused, host)
+		return time.Time{}
+	}
+	return until
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"lucasbonna/pulse/internal/config"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterLimitFor(t *testing.T) {
+	limits := config.HostLimits{
+		Default: config.HostLimit{RequestsPerSecond: 5},
+		Rules: []config.HostLimitRule{
+			{Pattern: "api.example.com", HostLimit: config.HostLimit{MaxConcurrent: 1}},
+			{Pattern: "*.example.com", HostLimit: config.HostLimit{RequestsPerSecond: 2}},
+			{Pattern: "10.0.0.?", HostLimit: config.HostLimit{MaxConcurrent: 3}},
+		},
+	}
+	limiter := newHostLimiter(limits)
+
+	tests := []struct {
+		host string
+		want config.HostLimit
+	}{
+		{"api.example.com", config.HostLimit{MaxConcurrent: 1}},
+		{"www.example.com", config.HostLimit{RequestsPerSecond: 2}},
+		{"example.com", config.HostLimit{RequestsPerSecond: 5}},
+		{"a.b.example.com", config.HostLimit{RequestsPerSecond: 2}},
+		{"10.0.0.7", config.HostLimit{MaxConcurrent: 3}},
+		{"10.0.0.17", config.HostLimit{RequestsPerSecond: 5}},
+		{"other.org", config.HostLimit{RequestsPerSecond: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := limiter.limitFor(tt.host); got != tt.want {
+				t.Errorf("limitFor(%q) = %+v, want %+v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostLimiterSkipsUnlimitedHosts(t *testing.T) {
+	limiter := newHostLimiter(config.HostLimits{
+		Rules: []config.HostLimitRule{
+			{Pattern: "api.example.com", HostLimit: config.HostLimit{MaxConcurrent: 1}},
+		},
+	})
+
+	release, err := limiter.acquire(context.Background(), "other.org")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	release()
+
+	if len(limiter.hosts) != 0 {
+		t.Errorf("kept %d throttles for an unlimited host", len(limiter.hosts))
+	}
+}
+
+func TestHostLimiterEvictsIdleThrottles(t *testing.T) {
+	limiter := newHostLimiter(config.HostLimits{
+		Default: config.HostLimit{MaxConcurrent: 1},
+	})
+	ctx := context.Background()
+
+	busy, err := limiter.acquire(ctx, "busy.example.com")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	idle, err := limiter.acquire(ctx, "idle.example.com")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	idle()
+	limiter.pause("paused.example.com", time.Now().Add(-time.Second))
+
+	limiter.lastSweep = time.Time{}
+	release, err := limiter.acquire(ctx, "new.example.com")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	release()
+
+	if _, ok := limiter.hosts["idle.example.com"]; ok {
+		t.Error("idle throttle was kept")
+	}
+	if _, ok := limiter.hosts["busy.example.com"]; !ok {
+		t.Error("throttle in use was dropped")
+	}
+	if _, ok := limiter.paused["paused.example.com"]; ok {
+		t.Error("ended pause was kept")
+	}
+	busy()
+}
@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultRetryDelaySeconds is the wait before the first retry of an http
+	// job unless it sets retry_delay_seconds; each further retry waits twice
+	// as long as the one before.
+	defaultRetryDelaySeconds = 1
+	maxRetryDelay            = time.Minute
+)
+
+// SentRequest is the fully rendered request of an http run, stored with
+// dead letters so a failed delivery can be replayed exactly.
+type SentRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   string
+}
+
+type httpExecutor struct {
+	clients *clientCache
+	hosts   *hostLimiter
+}
+
+func (e *httpExecutor) Execute(ctx context.Context, job db.Job) (Result, error) {
+	return e.makeHTTPRequest(ctx, job)
+}
+
+// makeHTTPRequest sends the job's request, retrying transport errors and
+// 408, 429 and 5xx answers up to retry_attempts times, no sooner than a
+// Retry-After asks, while the run's timeout allows. Replays send their
+// stored request instead of rendering the job's.
+func (e *httpExecutor) makeHTTPRequest(ctx context.Context, job db.Job) (Result, error) {
+	client, err := e.clients.clientFor(job)
+	if err != nil {
+		return Result{}, err
+	}
+
+	request, ok := replayFrom(ctx)
+	if !ok {
+		request, err = renderRequest(ctx, job)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	attempts := 1 + int(job.RetryAttempts.Int64)
+	for attempt := 1; ; attempt++ {
+		result, err := e.send(ctx, client, job, request)
+		result.Request = request
+		result.Attempts = attempt
+
+		if err == nil {
+			return result, nil
+		}
+		if attempt >= attempts || ctx.Err() != nil || !retryableStatus(result.StatusCode) {
+			return result, attemptsError(err, attempt)
+		}
+
+		// a target's Retry-After beyond the run's timeout is left to the
+		// next run
+		delay := max(retryDelay(job, attempt), result.RetryAfter)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return result, attemptsError(err, attempt)
+		}
+
+		slog.DebugContext(ctx, "retrying request", "job_id", job.ID, "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return result, attemptsError(err, attempt)
+		case <-time.After(delay):
+		}
+	}
+}
+
+func attemptsError(err error, attempts int) error {
+	if attempts > 1 {
+		return fmt.Errorf("%w (after %d attempts)", err, attempts)
+	}
+	return err
+}
+
+func (e *httpExecutor) send(ctx context.Context, client *http.Client, job db.Job, request *SentRequest) (Result, error) {
+	var result Result
+
+	var body io.Reader
+	if request.Body != "" {
+		body = strings.NewReader(request.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, request.Method, request.URL, body)
+	if err != nil {
+		return result, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = request.Header.Clone()
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	// time spent waiting for the host's limits is not part of the timings
+	release, err := e.hosts.acquire(ctx, req.URL.Hostname())
+	if err != nil {
+		return result, err
+	}
+	defer release()
+
+	tracer := newRequestTracer()
+	resp, err := client.Do(req.WithContext(tracer.withContext(ctx)))
+	if err != nil {
+		result.Timings = tracer.finish()
+		return result, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	result.Headers = resp.Header
+	result.Host = resp.Request.URL.Host
+	result.RetryAfter = parseRetryAfter(resp.StatusCode, resp.Header, time.Now())
+	if resp.TLS != nil {
+		result.Certificates = resp.TLS.PeerCertificates
+	}
+
+	// drain the body so the total covers the full transfer, keeping as
+	// much of it as the job captures or tracks
+	limit := job.MaxBodyBytes
+	if job.TrackChanges.Bool {
+		limit = max(limit, maxTrackedBodyBytes)
+	}
+	result.Body, err = io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err == nil {
+		var rest int64
+		rest, err = io.Copy(io.Discard, resp.Body)
+		result.BodySize = int64(len(result.Body)) + rest
+	}
+	result.Timings = tracer.finish()
+	if err != nil {
+		return result, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if failingStatus(job, resp.StatusCode) {
+		return result, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return result, nil
+}
+
+// renderRequest builds the job's request. Triggered runs fill their
+// variables, referenced as {{name}}, into the url, headers and body;
+// scheduled runs send them as they are.
+func renderRequest(ctx context.Context, job db.Job) (*SentRequest, error) {
+	header, err := ParseHeaders(job.Headers.String)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &SentRequest{
+		Method: job.Method.(string),
+		URL:    job.Url,
+		Header: header,
+		Body:   job.Body.String,
+	}
+
+	vars, triggered := triggerVariables(ctx)
+	if !triggered {
+		return request, nil
+	}
+
+	if request.URL, err = renderURL(job.Url, vars); err != nil {
+		return nil, err
+	}
+	for _, values := range header {
+		for i, value := range values {
+			if values[i], err = renderTemplate(value, vars); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if request.Body, err = renderTemplate(job.Body.String, vars); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// renderURL fills vars into a url template, escaped for the path before
+// the first "?" and for the query after it, so values cannot change the
+// url's structure.
+func renderURL(template string, vars map[string]string) (string, error) {
+	path, query, hasQuery := strings.Cut(template, "?")
+
+	rendered, err := renderEscaped(path, vars, url.PathEscape)
+	if err != nil || !hasQuery {
+		return rendered, err
+	}
+
+	renderedQuery, err := renderEscaped(query, vars, url.QueryEscape)
+	if err != nil {
+		return "", err
+	}
+	return rendered + "?" + renderedQuery, nil
+}
+
+// ParseHeaders reads an http job's headers, one "Name: value" per line.
+func ParseHeaders(text string) (http.Header, error) {
+	header := make(http.Header)
+
+	for line := range strings.Lines(text) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header line %q, expected \"Name: value\"", line)
+		}
+		header.Add(textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(value))
+	}
+
+	return header, nil
+}
+
+// failingStatus reports whether an answer fails the run: any 4xx or 5xx
+// with fail_on_error_status, and those worth retrying whenever the job
+// retries, so they end up as dead letters once the retries run out.
+func failingStatus(job db.Job, statusCode int) bool {
+	if statusCode < 400 {
+		return false
+	}
+	return job.FailOnErrorStatus.Bool || job.RetryAttempts.Int64 > 0 && retryableStatus(statusCode)
+}
+
+// retryableStatus reports whether a failed attempt with this status code,
+// 0 when no answer arrived, is worth repeating.
+func retryableStatus(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+func retryDelay(job db.Job, attempt int) time.Duration {
+	seconds := int64(defaultRetryDelaySeconds)
+	if job.RetryDelaySeconds.Valid && job.RetryDelaySeconds.Int64 > 0 {
+		seconds = job.RetryDelaySeconds.Int64
+	}
+	return min(time.Duration(seconds)*time.Second<<(attempt-1), maxRetryDelay)
+}
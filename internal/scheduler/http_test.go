@@ -0,0 +1,153 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRenderURL(t *testing.T) {
+	vars := map[string]string{
+		"id":    "42",
+		"path":  "a/../b",
+		"query": "x&y=1",
+		"space": "a b",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{"no variables", "https://example.com/orders?all=1", "https://example.com/orders?all=1", false},
+		{"path", "https://example.com/orders/{{id}}", "https://example.com/orders/42", false},
+		{"path separators", "https://example.com/{{path}}", "https://example.com/a%2F..%2Fb", false},
+		{"query", "https://example.com/search?q={{query}}", "https://example.com/search?q=x%26y%3D1", false},
+		{"path and query", "https://example.com/{{space}}?q={{space}}", "https://example.com/a%20b?q=a+b", false},
+		{"second question mark", "https://example.com/?a={{query}}?b", "https://example.com/?a=x%26y%3D1?b", false},
+		{"undefined", "https://example.com/{{missing}}", "", true},
+		{"undefined in query", "https://example.com/?q={{missing}}", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderURL(tt.template, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderURL error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("renderURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderRequest(t *testing.T) {
+	job := db.Job{
+		Method:  "POST",
+		Url:     "https://example.com/orders/{{id}}",
+		Headers: sql.NullString{String: "X-Order: {{id}}", Valid: true},
+		Body:    sql.NullString{String: `{"order": "{{id}}"}`, Valid: true},
+	}
+
+	t.Run("scheduled", func(t *testing.T) {
+		request, err := renderRequest(context.Background(), job)
+		if err != nil {
+			t.Fatalf("renderRequest: %v", err)
+		}
+		if request.URL != job.Url || request.Header.Get("X-Order") != "{{id}}" || request.Body != job.Body.String {
+			t.Errorf("scheduled run changed the request: %+v", request)
+		}
+	})
+
+	t.Run("triggered", func(t *testing.T) {
+		ctx := withVariables(context.Background(), map[string]string{"id": "7/8"})
+		request, err := renderRequest(ctx, job)
+		if err != nil {
+			t.Fatalf("renderRequest: %v", err)
+		}
+		if request.URL != "https://example.com/orders/7%2F8" {
+			t.Errorf("URL = %q", request.URL)
+		}
+		if got := request.Header.Get("X-Order"); got != "7/8" {
+			t.Errorf("X-Order = %q", got)
+		}
+		if request.Body != `{"order": "7/8"}` {
+			t.Errorf("Body = %q", request.Body)
+		}
+	})
+
+	t.Run("triggered without the variable", func(t *testing.T) {
+		ctx := withVariables(context.Background(), map[string]string{})
+		if _, err := renderRequest(ctx, job); err == nil {
+			t.Error("renderRequest succeeded with an undefined variable")
+		}
+	})
+}
+
+// newStatusServer answers each request with the next of statuses, repeating
+// the last one, and counts the requests.
+func newStatusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestHTTPExecutorRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		retries      int64
+		failOnStatus bool
+		wantErr      string
+		wantAttempts int
+	}{
+		{"recovers on retry", []int{503, 200}, 1, false, "", 2},
+		{"retries run out", []int{503}, 1, false, "unexpected status code 503 (after 2 attempts)", 2},
+		{"429 without retries", []int{429}, 0, false, "", 1},
+		{"429 failing without retries", []int{429}, 0, true, "unexpected status code 429", 1},
+		{"404 is not retried", []int{404}, 1, false, "", 1},
+		{"404 failing is not retried", []int{404, 200}, 1, true, "unexpected status code 404", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newStatusServer(t, tt.statuses...)
+			executor := &httpExecutor{clients: newClientCache(), hosts: newHostLimiter(config.HostLimits{})}
+			job := db.Job{
+				Method:            "POST",
+				Url:               server.URL,
+				RetryAttempts:     sql.NullInt64{Int64: tt.retries, Valid: true},
+				FailOnErrorStatus: sql.NullBool{Bool: tt.failOnStatus, Valid: true},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			result, err := executor.Execute(ctx, job)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+			}
+			if result.Attempts != tt.wantAttempts || int(requests.Load()) != tt.wantAttempts {
+				t.Errorf("attempts = %d with %d requests, want %d", result.Attempts, requests.Load(), tt.wantAttempts)
+			}
+		})
+	}
+}
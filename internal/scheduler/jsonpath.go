@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// selectJSONPath evaluates a small JSONPath subset against body: a leading
+// "$" followed by ".field" and "[index]" segments, e.g. "$.data.items[0].id".
+// Strings are returned as-is; any other value is returned as compact JSON.
+func selectJSONPath(body []byte, path string) (string, error) {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "", fmt.Errorf("response is not valid JSON: %w", err)
+	}
+
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, segment := range segments {
+		switch current := value.(type) {
+		case map[string]any:
+			next, ok := current[segment]
+			if !ok {
+				return "", fmt.Errorf("%s: field %q not found", path, segment)
+			}
+			value = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(current) {
+				return "", fmt.Errorf("%s: index %q out of range", path, segment)
+			}
+			value = current[index]
+		default:
+			return "", fmt.Errorf("%s: cannot select %q from a scalar", path, segment)
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func parseJSONPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSON path %q must start with $", path)
+	}
+
+	var segments []string
+	rest := path[1:]
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("JSON path %q has an empty field name", path)
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("JSON path %q has an unclosed [", path)
+			}
+			segments = append(segments, strings.Trim(rest[1:end], `"'`))
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("JSON path %q is invalid near %q", path, rest)
+		}
+	}
+
+	return segments, nil
+}
+
+// ValidateJSONPath reports whether path is in the subset selectJSONPath
+// understands.
+func ValidateJSONPath(path string) error {
+	_, err := parseJSONPath(path)
+	return err
+}
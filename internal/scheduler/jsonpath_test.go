@@ -0,0 +1,41 @@
+package scheduler
+
+import "testing"
+
+func TestSelectJSONPath(t *testing.T) {
+	body := []byte(`{"data": {"items": [{"id": 7, "tags": ["a"]}], "name": "pulse", "dotted.key": true}}`)
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"$.data.name", "pulse", false},
+		{"$.data.items[0].id", "7", false},
+		{"$.data.items[0].tags", `["a"]`, false},
+		{`$.data["dotted.key"]`, "true", false},
+		{"$", `{"data":{"dotted.key":true,"items":[{"id":7,"tags":["a"]}],"name":"pulse"}}`, false},
+		{"$.data.missing", "", true},
+		{"$.data.items[1]", "", true},
+		{"$.data.name.first", "", true},
+		{"data.name", "", true},
+		{"$.data[0", "", true},
+		{"$..name", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := selectJSONPath(body, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectJSONPath error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("selectJSONPath = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := selectJSONPath([]byte("<html>"), "$.data"); err == nil {
+		t.Error("selected from a body that is not JSON")
+	}
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"time"
+)
+
+// LatencyBucketsMs are the upper bounds of the latency histogram kept per
+// job and hour. Runs slower than the last bound land in an overflow bucket
+// stored with le_ms = -1.
+var LatencyBucketsMs = []int64{
+	5, 10, 25, 50, 75, 100, 150, 200, 300, 500, 750,
+	1000, 1500, 2000, 3000, 5000, 7500, 10000, 15000, 30000, 60000,
+}
+
+const OverflowBucketMs = -1
+
+// recordRollup adds a finished run to the hourly aggregates the stats API
+// reads. Latency is only counted for runs that did not fail, so timeouts
+// and refused connections don't skew the percentiles.
+func (s *Scheduler) recordRollup(ctx context.Context, jobID int64, startedAt time.Time, status string, total time.Duration) {
+	bucketStart := startedAt.Truncate(time.Hour).Unix()
+
+	hasLatency := status != "failed" && total > 0
+	params := db.UpsertJobRollupParams{
+		JobID:       jobID,
+		BucketStart: bucketStart,
+		Status:      status,
+	}
+	if hasLatency {
+		params.LatencySumMs = total.Milliseconds()
+		params.LatencyCount = 1
+	}
+
+	if err := s.db.UpsertJobRollup(ctx, params); err != nil {
+		slog.ErrorContext(ctx, "failed to update rollup", "job_id", jobID, "error", err)
+		return
+	}
+
+	if !hasLatency {
+		return
+	}
+
+	err := s.db.UpsertJobLatencyRollup(ctx, db.UpsertJobLatencyRollupParams{
+		JobID:       jobID,
+		BucketStart: bucketStart,
+		LeMs:        latencyBucket(total.Milliseconds()),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update latency rollup", "job_id", jobID, "error", err)
+	}
+}
+
+func latencyBucket(ms int64) int64 {
+	for _, bound := range LatencyBucketsMs {
+		if ms <= bound {
+			return bound
+		}
+	}
+	return OverflowBucketMs
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"lucasbonna/pulse/db"
+	"testing"
+	"time"
+)
+
+func TestLatencyBucket(t *testing.T) {
+	tests := []struct {
+		ms   int64
+		want int64
+	}{
+		{0, 5},
+		{5, 5},
+		{6, 10},
+		{999, 1000},
+		{60000, 60000},
+		{60001, OverflowBucketMs},
+	}
+
+	for _, tt := range tests {
+		if got := latencyBucket(tt.ms); got != tt.want {
+			t.Errorf("latencyBucket(%d) = %d, want %d", tt.ms, got, tt.want)
+		}
+	}
+}
+
+func TestRecordRollup(t *testing.T) {
+	s := newTestScheduler(t)
+	job := createTestJob(t, s, db.CreateJobParams{Url: "https://example.com"})
+	ctx := context.Background()
+	hour := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	runs := []struct {
+		startedAt time.Time
+		status    string
+		total     time.Duration
+	}{
+		{hour.Add(time.Minute), "success", 40 * time.Millisecond},
+		{hour.Add(59 * time.Minute), "success", 60 * time.Millisecond},
+		{hour.Add(30 * time.Minute), "warning", 45 * time.Millisecond},
+		{hour.Add(30 * time.Minute), "failed", 5 * time.Second},
+		{hour.Add(30 * time.Minute), "success", 0},
+		{hour.Add(time.Hour), "success", 2 * time.Minute},
+	}
+	for _, run := range runs {
+		s.recordRollup(ctx, job.ID, run.startedAt, run.status, run.total)
+	}
+
+	rollups, err := s.db.GetJobRollups(ctx, db.GetJobRollupsParams{JobID: job.ID, BucketStart: hour.Unix()})
+	if err != nil {
+		t.Fatalf("list rollups: %v", err)
+	}
+	type key struct {
+		bucketStart int64
+		status      string
+	}
+	want := map[key]db.JobRollup{
+		{hour.Unix(), "success"}:                {Runs: 3, LatencySumMs: 100, LatencyCount: 2},
+		{hour.Unix(), "warning"}:                {Runs: 1, LatencySumMs: 45, LatencyCount: 1},
+		{hour.Unix(), "failed"}:                 {Runs: 1},
+		{hour.Add(time.Hour).Unix(), "success"}: {Runs: 1, LatencySumMs: 120000, LatencyCount: 1},
+	}
+	if len(rollups) != len(want) {
+		t.Fatalf("stored %d rollups, want %d: %+v", len(rollups), len(want), rollups)
+	}
+	for _, rollup := range rollups {
+		w := want[key{rollup.BucketStart, rollup.Status}]
+		if rollup.Runs != w.Runs || rollup.LatencySumMs != w.LatencySumMs || rollup.LatencyCount != w.LatencyCount {
+			t.Errorf("rollup %d/%s = %d runs %d/%d ms, want %d runs %d/%d ms", rollup.BucketStart, rollup.Status,
+				rollup.Runs, rollup.LatencySumMs, rollup.LatencyCount, w.Runs, w.LatencySumMs, w.LatencyCount)
+		}
+	}
+
+	histogram, err := s.db.GetJobLatencyHistogram(ctx, db.GetJobLatencyHistogramParams{JobID: job.ID, BucketStart: hour.Unix()})
+	if err != nil {
+		t.Fatalf("histogram: %v", err)
+	}
+	got := make(map[int64]int64)
+	for _, row := range histogram {
+		got[row.LeMs] = row.Runs
+	}
+	wantHistogram := map[int64]int64{50: 2, 75: 1, OverflowBucketMs: 1}
+	if len(got) != len(wantHistogram) {
+		t.Fatalf("histogram = %v, want %v", got, wantHistogram)
+	}
+	for le, runs := range wantHistogram {
+		if got[le] != runs {
+			t.Errorf("histogram = %v, want %v", got, wantHistogram)
+			break
+		}
+	}
+}
@@ -3,48 +3,96 @@ package scheduler
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"log"
+	"errors"
+	"log/slog"
 	"lucasbonna/pulse/db"
-	"net/http"
+	"lucasbonna/pulse/internal/config"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/metrics"
+	"lucasbonna/pulse/internal/telemetry"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// RunObserver is told about every finished run once it has been stored.
+// Observers are called on the run's goroutine, so slow work such as
+// network calls belongs in a goroutine of its own.
+type RunObserver interface {
+	RunFinished(ctx context.Context, job db.Job, run FinishedRun)
+}
+
+// FinishedRun summarises a stored run for observers.
+type FinishedRun struct {
+	ID           int64
+	Status       string
+	Error        string
+	ResponseCode int
+	StartedAt    time.Time
+	FinishedAt   time.Time
+}
+
 type Scheduler struct {
 	db          *db.Queries
-	httpClient  *http.Client
+	events      *events.Bus
+	executors   map[string]Executor
+	hosts       *hostLimiter
+	observers   []RunObserver
 	runningJobs map[int64]bool
 	mutex       sync.RWMutex
 	ticker      *time.Ticker
 	done        chan bool
+	// lastTick holds the unix nanoseconds at which the loop last finished
+	// checking for due jobs.
+	lastTick atomic.Int64
 }
 
-func NewScheduler(database *db.Queries) *Scheduler {
+// NewScheduler creates a scheduler whose http, steps and sse requests keep
+// to hostLimits.
+func NewScheduler(database *db.Queries, bus *events.Bus, hostLimits config.HostLimits) *Scheduler {
+	clients := newClientCache()
+	hosts := newHostLimiter(hostLimits)
+
 	return &Scheduler{
-		db: database,
-		httpClient: &http.Client{
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
-			},
+		db:     database,
+		events: bus,
+		hosts:  hosts,
+		executors: map[string]Executor{
+			JobTypeHTTP:       &httpExecutor{clients: clients, hosts: hosts},
+			JobTypeTCP:        &tcpExecutor{},
+			JobTypeDNS:        &dnsExecutor{},
+			JobTypeGRPCHealth: &grpcHealthExecutor{},
+			JobTypeSteps:      &stepsExecutor{clients: clients, hosts: hosts},
+			JobTypeWebSocket:  &websocketExecutor{},
+			JobTypeSSE:        &sseExecutor{clients: clients, hosts: hosts},
+			JobTypeHeartbeat:  &heartbeatExecutor{},
 		},
 		runningJobs: make(map[int64]bool),
 		done:        make(chan bool),
 	}
 }
 
+// AddRunObserver registers observer for finished runs. It must be called
+// before Start.
+func (s *Scheduler) AddRunObserver(observer RunObserver) {
+	s.observers = append(s.observers, observer)
+}
+
 func (s *Scheduler) Start(ctx context.Context) {
-	log.Println("Starting job scheduler (checking every 1 second)...")
+	slog.Info("starting job scheduler", "tick", time.Second.String())
 
 	s.ticker = time.NewTicker(1 * time.Second)
+	s.lastTick.Store(time.Now().UnixNano())
 
 	go s.run(ctx)
 }
 
 func (s *Scheduler) Stop() {
-	log.Println("Stopping job scheduler...")
+	slog.Info("stopping job scheduler")
 	if s.ticker != nil {
 		s.ticker.Stop()
 	}
@@ -55,26 +103,29 @@ func (s *Scheduler) run(ctx context.Context) {
 	for {
 		select {
 		case <-s.done:
-			log.Println("Scheduler stopped")
+			slog.Info("scheduler stopped")
 			return
 		case <-s.ticker.C:
 			s.checkAndRunJobs(ctx)
+			s.lastTick.Store(time.Now().UnixNano())
 		}
 	}
 }
 
 func (s *Scheduler) checkAndRunJobs(ctx context.Context) {
-	log.Println("checking for jobs to run")
 	jobs, err := s.db.GetDueJobs(ctx)
 	if err != nil {
-		log.Printf("error getting due jobs: %v", err)
+		slog.Error("failed to get due jobs", "error", err)
 		return
 	}
+	metrics.SetQueueDepth(len(jobs))
 
 	for _, job := range jobs {
-		log.Printf("job %d (%s) found, checking before running...", job.ID, job.Name)
 		if s.isJobRunning(job.ID) {
-			log.Printf("Job %d (%s) is already running, skipping", job.ID, job.Name)
+			slog.Debug("job is still running, skipping", "job_id", job.ID)
+			continue
+		}
+		if s.deferPausedJob(ctx, job) {
 			continue
 		}
 
@@ -89,6 +140,24 @@ func (s *Scheduler) isJobRunning(jobID int64) bool {
 	return s.runningJobs[jobID]
 }
 
+// RunningCount returns the number of jobs currently executing.
+func (s *Scheduler) RunningCount() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.runningJobs)
+}
+
+// LastTick returns when the scheduler loop last completed a check for due
+// jobs, or the zero time before Start. A loop stuck on a query stops
+// advancing it.
+func (s *Scheduler) LastTick() time.Time {
+	nanos := s.lastTick.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
 func (s *Scheduler) markJobAsRunning(jobID int64, running bool) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -100,64 +169,209 @@ func (s *Scheduler) markJobAsRunning(jobID int64, running bool) {
 	}
 }
 
+// claimJob marks a job as running unless a run of it is in progress.
+func (s *Scheduler) claimJob(jobID int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.runningJobs[jobID] {
+		return false
+	}
+	s.runningJobs[jobID] = true
+	return true
+}
+
 func (s *Scheduler) executeJob(ctx context.Context, job db.Job) {
 	defer s.markJobAsRunning(job.ID, false)
+	s.runJob(ctx, job)
+}
+
+// runJob performs one run of job and stores its outcome.
+func (s *Scheduler) runJob(ctx context.Context, job db.Job) {
+	ctx, span := telemetry.Tracer().Start(ctx, "job.run", trace.WithAttributes(
+		attribute.Int64("pulse.job.id", job.ID),
+		attribute.String("pulse.job.name", job.Name),
+		attribute.String("pulse.job.type", job.Type),
+	))
+	defer span.End()
 
-	log.Printf("executing job %d: %s %s", job.ID, job.Method, job.Url)
+	logger := slog.With("job_id", job.ID)
+	logger.DebugContext(ctx, "executing job", "type", job.Type, "target", describeTarget(job))
 
 	startTime := time.Now()
+	if job.NextRunAt.Valid {
+		metrics.ObserveSchedulerLag(startTime.Sub(job.NextRunAt.Time))
+	}
+
+	s.halfOpenBreaker(ctx, &job)
+
+	replayOf, _ := ctx.Value(replayKey{}).(replay)
+	jobRun, err := s.db.CreateJobRun(ctx, db.CreateJobRunParams{
+		JobID:         job.ID,
+		Status:        sql.NullString{String: "running", Valid: true},
+		StartedAt:     sql.NullTime{Time: startTime, Valid: true},
+		ReplayOfRunID: sql.NullInt64{Int64: replayOf.runID, Valid: replayOf.runID != 0},
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to create job run record", "error", err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int64("pulse.run.id", jobRun.ID))
+	logger = logger.With("run_id", jobRun.ID)
+	s.linkReplay(ctx, jobRun.ID)
 
-	// jobRun, err := s.db.CreateJobRun(ctx, db.CreateJobRunParams{
-	// 	JobID:     job.ID,
-	// 	Status:    sql.NullString{String: "running", Valid: true},
-	// 	StartedAt: sql.NullTime{Time: startTime, Valid: true},
-	// })
-	// if err != nil {
-	// 	log.Printf("Failed to create job run record: %v", err)
-	// 	return
-	// }
-
-	_, err := s.makeHTTPRequest(job)
+	s.events.Publish(events.RunStarted, job, events.RunData{
+		JobID:     job.ID,
+		JobName:   job.Name,
+		RunID:     jobRun.ID,
+		Status:    "running",
+		StartedAt: startTime,
+	})
+
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(job.TimeoutSeconds)*time.Second)
+	result, err := s.execute(runCtx, job)
+	cancel()
 	finishTime := time.Now()
+	deliveryErr := err
 
 	status := "success"
 	if err != nil {
 		status = "failed"
-		log.Printf("error executing job %d: %v", job.ID, err)
+		logger.WarnContext(ctx, "job run failed", "error", err)
 	}
 
-	// s.db.UpdateJobRun(ctx, db.UpdateJobRunParams{
-	// 	ID:           jobRun.ID,
-	// 	Status:       sql.NullString{String: status, Valid: true},
-	// 	ResponseCode: sql.NullInt64{Int64: int64(statusCode), Valid: true},
-	// 	ResponseBody: sql.NullString{String: "", Valid: false},
-	// 	FinishedAt:   sql.NullTime{Time: finishTime, Valid: true},
-	// })
+	if len(result.Steps) > 0 {
+		s.saveSteps(ctx, jobRun.ID, result.Steps)
+	}
 
-	currentTime := time.Now()
+	if len(result.Certificates) > 0 {
+		s.saveCertificates(ctx, job, jobRun.ID, result.Host, result.Certificates)
 
-	duration := time.Duration(job.IntervalSeconds) * time.Second
+		if err == nil {
+			if err = checkCertificateExpiry(job, result.Certificates, finishTime); err != nil {
+				status = "failed"
+				if job.CertExpiryAction == "warn" {
+					status = "warning"
+				}
+				logger.WarnContext(ctx, "certificate expiry check tripped", "error", err)
+			}
+		}
+	}
 
-	nextRun := currentTime.Add(duration).UTC()
-	s.db.UpdateJobNextRun(ctx, db.UpdateJobNextRunParams{
-		ID:        job.ID,
-		NextRunAt: sql.NullTime{Time: nextRun, Valid: true},
-	})
+	var change contentChange
+	if job.TrackChanges.Bool && err == nil {
+		change, err = s.trackContent(ctx, job, result.Body)
+		if errors.Is(err, errTrackedContent) {
+			status = "failed"
+			logger.WarnContext(ctx, "tracked content not found", "error", err)
+		} else if err != nil {
+			logger.ErrorContext(ctx, "failed to track content", "error", err)
+			err = nil
+		} else if change.Changed {
+			logger.InfoContext(ctx, "response content changed")
+		}
+	}
 
-	log.Printf("job %d completed with status %s in %v", job.ID, status, finishTime.Sub(startTime))
-}
+	response, captureErr := captureResponse(job, result)
+	if captureErr != nil {
+		logger.ErrorContext(ctx, "failed to capture response", "error", captureErr)
+	}
 
-func (s *Scheduler) makeHTTPRequest(job db.Job) (int, error) {
-	req, err := http.NewRequest(job.Method.(string), job.Url, nil)
+	runError := sql.NullString{}
 	if err != nil {
-		return 0, fmt.Errorf("failed to create request: %w", err)
+		runError = sql.NullString{String: err.Error(), Valid: true}
 	}
 
-	resp, err := s.httpClient.Do(req)
+	err = s.db.UpdateJobRun(ctx, db.UpdateJobRunParams{
+		ID:           jobRun.ID,
+		Status:       sql.NullString{String: status, Valid: true},
+		ResponseCode: sql.NullInt64{Int64: int64(result.StatusCode), Valid: result.StatusCode != 0},
+		ResponseBody: response.Body,
+		FinishedAt:   sql.NullTime{Time: finishTime, Valid: true},
+		DnsMs:        durationMs(result.Timings.DNSLookup),
+		ConnectMs:    durationMs(result.Timings.TCPConnect),
+		TlsMs:        durationMs(result.Timings.TLSHandshake),
+		TtfbMs:       durationMs(result.Timings.TimeToFirstByte),
+		TotalMs:      durationMs(result.Timings.Total),
+		Error:        runError,
+		BodyHash:     change.Hash,
+		Changed:      sql.NullBool{Bool: change.Changed, Valid: true},
+		BodyDiff:     change.Diff,
+
+		ResponseHeaders:   response.Headers,
+		ResponseEncoding:  response.Encoding,
+		ResponseSize:      response.Size,
+		ResponseTruncated: sql.NullBool{Bool: response.Truncated, Valid: true},
+	})
 	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+		logger.ErrorContext(ctx, "failed to update job run", "error", err)
+	}
+
+	// a failed replay leaves its dead letter pending instead of adding one
+	switch _, replaying := replayFrom(ctx); {
+	case replaying:
+		if deliveryErr == nil {
+			s.markReplayed(ctx)
+		}
+	case deliveryErr != nil && result.Request != nil && deadLettered(job):
+		s.saveDeadLetter(ctx, job, jobRun.ID, result, deliveryErr)
+	}
+
+	s.recordRollup(ctx, job.ID, startTime, status, result.Timings.Total)
+	metrics.ObserveRun(job.ID, job.Name, job.Type, status, finishTime.Sub(startTime))
+
+	span.SetAttributes(attribute.String("pulse.run.status", status))
+	if runError.Valid {
+		span.SetStatus(codes.Error, runError.String)
+	}
+
+	currentTime := time.Now()
+
+	duration := s.updateBreaker(ctx, &job, status, currentTime)
+
+	if job.Type == JobTypeHeartbeat {
+		s.heartbeatMissed(ctx, job, currentTime)
+	} else {
+		nextRun := s.honourBackpressure(ctx, job, result, currentTime, currentTime.Add(duration).UTC())
+		s.db.UpdateJobNextRun(ctx, db.UpdateJobNextRunParams{
+			ID:        job.ID,
+			NextRunAt: sql.NullTime{Time: nextRun, Valid: true},
+		})
 	}
-	defer resp.Body.Close()
 
-	return resp.StatusCode, nil
+	s.runFinished(ctx, job, FinishedRun{
+		ID:           jobRun.ID,
+		Status:       status,
+		Error:        runError.String,
+		ResponseCode: result.StatusCode,
+		StartedAt:    startTime,
+		FinishedAt:   finishTime,
+	})
+
+	logger.InfoContext(ctx, "job run finished", "status", status, "duration_ms", finishTime.Sub(startTime).Milliseconds())
+}
+
+// runFinished publishes the outcome of a stored run and tells the observers.
+func (s *Scheduler) runFinished(ctx context.Context, job db.Job, run FinishedRun) {
+	eventType := events.RunSucceeded
+	if run.Status == "failed" {
+		eventType = events.RunFailed
+	}
+	durationMs := run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	s.events.Publish(eventType, job, events.RunData{
+		JobID:        job.ID,
+		JobName:      job.Name,
+		RunID:        run.ID,
+		Status:       run.Status,
+		Error:        run.Error,
+		ResponseCode: run.ResponseCode,
+		StartedAt:    run.StartedAt,
+		FinishedAt:   &run.FinishedAt,
+		DurationMs:   &durationMs,
+	})
+
+	for _, observer := range s.observers {
+		observer.RunFinished(ctx, job, run)
+	}
 }
@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/config"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/storage"
+	"testing"
+)
+
+// newTestScheduler returns a scheduler, not started, on a fresh database
+// in a temporary directory.
+func newTestScheduler(t *testing.T) *Scheduler {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+	queries, database, err := storage.NewSQLiteDB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	return NewScheduler(queries, events.NewBus(), config.HostLimits{})
+}
+
+// createTestJob stores a job from params, filling in a name, method, type,
+// interval and timeout when they are missing. Jobs are active unless
+// params say otherwise.
+func createTestJob(t *testing.T, s *Scheduler, params db.CreateJobParams) db.Job {
+	t.Helper()
+
+	if params.Name == "" {
+		params.Name = "test"
+	}
+	if params.Method == nil {
+		params.Method = "GET"
+	}
+	if params.Type == "" {
+		params.Type = JobTypeHTTP
+	}
+	if params.IntervalSeconds == 0 {
+		params.IntervalSeconds = 60
+	}
+	if params.TimeoutSeconds == 0 {
+		params.TimeoutSeconds = 5
+	}
+	if !params.Active.Valid {
+		params.Active = sql.NullBool{Bool: true, Valid: true}
+	}
+
+	job, err := s.db.CreateJob(context.Background(), params)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	return job
+}
+
+// jobRuns returns the stored runs of job, newest first.
+func jobRuns(t *testing.T, s *Scheduler, job db.Job) []db.JobRun {
+	t.Helper()
+
+	runs, err := s.db.GetJobRuns(context.Background(), db.GetJobRunsParams{JobID: job.ID, Limit: 100})
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	return runs
+}
@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"lucasbonna/pulse/db"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// sseExecutor subscribes to a Server-Sent Events stream and waits for an
+// event whose data contains expect_message (any event when empty). Timings
+// follow websocketExecutor: TCPConnect is the time until the response
+// headers arrive and TimeToFirstByte the latency of the first event.
+type sseExecutor struct {
+	clients *clientCache
+	hosts   *hostLimiter
+}
+
+func (e *sseExecutor) Execute(ctx context.Context, job db.Job) (Result, error) {
+	var result Result
+
+	client, err := e.clients.clientFor(job)
+	if err != nil {
+		return result, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, job.Url, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	release, err := e.hosts.acquire(ctx, req.URL.Hostname())
+	if err != nil {
+		return result, err
+	}
+	defer release()
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Timings.TCPConnect = time.Since(start)
+	if err != nil {
+		result.Timings.Total = time.Since(start)
+		return result, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	result.Host = resp.Request.URL.Host
+	result.RetryAfter = parseRetryAfter(resp.StatusCode, resp.Header, time.Now())
+	if resp.TLS != nil {
+		result.Certificates = resp.TLS.PeerCertificates
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		result.Timings.Total = time.Since(start)
+		return result, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/event-stream") {
+		result.Timings.Total = time.Since(start)
+		return result, fmt.Errorf("unexpected content type %q", contentType)
+	}
+
+	waitStart := time.Now()
+	scanner := bufio.NewScanner(resp.Body)
+	var data []string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data = append(data, strings.TrimPrefix(value, " "))
+			continue
+		}
+
+		// a blank line dispatches the event
+		if line != "" || len(data) == 0 {
+			continue
+		}
+
+		if result.Timings.TimeToFirstByte == 0 {
+			result.Timings.TimeToFirstByte = time.Since(waitStart)
+		}
+
+		if strings.Contains(strings.Join(data, "\n"), job.ExpectMessage.String) {
+			result.Timings.Total = time.Since(start)
+			return result, nil
+		}
+		data = data[:0]
+	}
+
+	result.Timings.Total = time.Since(start)
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("no matching event received: %w", err)
+	}
+	return result, fmt.Errorf("stream closed before a matching event arrived")
+}
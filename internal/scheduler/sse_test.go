@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newEventServer serves /events as a Server-Sent Events stream of a
+// comment, an event without data, a two-line event and a single-line one,
+// then closes it. /json answers with a JSON document and /busy with 503.
+func newEventServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		io.WriteString(w, ": connected\n\nevent: ping\n\ndata: order 17\ndata: shipped\n\ndata:done\n\n")
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data": "shipped"}`)
+	})
+	mux.HandleFunc("/busy", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSSEExecutor(t *testing.T) {
+	server := newEventServer(t)
+
+	tests := []struct {
+		name    string
+		path    string
+		expect  string
+		wantErr string
+	}{
+		{"any event", "/events", "", ""},
+		{"multi-line data", "/events", "17\nshipped", ""},
+		{"data without a space", "/events", "done", ""},
+		{"stream closes first", "/events", "cancelled", "stream closed before a matching event arrived"},
+		{"not an event stream", "/json", "", `unexpected content type "application/json"`},
+		{"unavailable", "/busy", "", "unexpected status code 503"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			executor := &sseExecutor{clients: newClientCache(), hosts: newHostLimiter(config.HostLimits{})}
+			job := db.Job{
+				Url:            server.URL + tt.path,
+				TimeoutSeconds: 5,
+				ExpectMessage:  sql.NullString{String: tt.expect, Valid: tt.expect != ""},
+			}
+			result, err := executor.Execute(ctx, job)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantErr == "" && result.Timings.TimeToFirstByte == 0 {
+				t.Error("TimeToFirstByte was not recorded")
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxStepBodyBytes = 1 << 20
+
+// Step is one request of a steps job, stored as JSON in jobs.steps. URL,
+// header values and body may reference variables as {{name}}: trigger
+// variables and values extracted by earlier steps.
+type Step struct {
+	Name               string            `json:"name"`
+	Method             string            `json:"method"`
+	URL                string            `json:"url"`
+	Headers            map[string]string `json:"headers,omitempty"`
+	Body               string            `json:"body,omitempty"`
+	Extract            map[string]string `json:"extract,omitempty"`
+	ExpectStatus       int               `json:"expect_status,omitempty"`
+	ExpectBodyContains string            `json:"expect_body_contains,omitempty"`
+}
+
+type StepResult struct {
+	Name       string
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// stepsExecutor runs a job's steps in order with a shared cookie jar and
+// variable set, stopping at the first step that fails.
+type stepsExecutor struct {
+	clients *clientCache
+	hosts   *hostLimiter
+}
+
+func (e *stepsExecutor) Execute(ctx context.Context, job db.Job) (result Result, err error) {
+	var steps []Step
+	if err := json.Unmarshal([]byte(job.Steps.String), &steps); err != nil {
+		return result, fmt.Errorf("invalid steps definition: %w", err)
+	}
+	if len(steps) == 0 {
+		return result, fmt.Errorf("job has no steps")
+	}
+
+	client, err := e.clients.clientFor(job)
+	if err != nil {
+		return result, err
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return result, err
+	}
+
+	// copy the shared client so the cookie jar stays local to this run
+	runClient := *client
+	runClient.Jar = jar
+
+	vars := variablesFrom(ctx)
+	start := time.Now()
+	defer func() { result.Timings.Total = time.Since(start) }()
+
+	for i, step := range steps {
+		stepStart := time.Now()
+		statusCode, err := e.runStep(ctx, &runClient, step, vars)
+
+		result.StatusCode = statusCode
+		result.Steps = append(result.Steps, StepResult{
+			Name:       stepName(i, step),
+			StatusCode: statusCode,
+			Duration:   time.Since(stepStart),
+			Err:        err,
+		})
+
+		if err != nil {
+			return result, fmt.Errorf("step %d (%s): %w", i+1, stepName(i, step), err)
+		}
+	}
+
+	return result, nil
+}
+
+func (e *stepsExecutor) runStep(ctx context.Context, client *http.Client, step Step, vars map[string]string) (int, error) {
+	url, err := renderTemplate(step.URL, vars)
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := renderTemplate(step.Body, vars)
+	if err != nil {
+		return 0, err
+	}
+
+	method := step.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for name, value := range step.Headers {
+		rendered, err := renderTemplate(value, vars)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set(name, rendered)
+	}
+
+	release, err := e.hosts.acquire(ctx, req.URL.Hostname())
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxStepBodyBytes))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if step.ExpectStatus != 0 {
+		if resp.StatusCode != step.ExpectStatus {
+			return resp.StatusCode, fmt.Errorf("expected status %d, got %d", step.ExpectStatus, resp.StatusCode)
+		}
+	} else if resp.StatusCode >= 400 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if step.ExpectBodyContains != "" && !strings.Contains(string(respBody), step.ExpectBodyContains) {
+		return resp.StatusCode, fmt.Errorf("response body does not contain %q", step.ExpectBodyContains)
+	}
+
+	for name, source := range step.Extract {
+		value, err := extractValue(resp, respBody, source)
+		if err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		vars[name] = value
+	}
+
+	return resp.StatusCode, nil
+}
+
+// extractValue reads a variable from a response. Sources are either a JSON
+// path into the body ("$.token") or a response header ("header:Location").
+func extractValue(resp *http.Response, body []byte, source string) (string, error) {
+	if name, ok := strings.CutPrefix(source, "header:"); ok {
+		value := resp.Header.Get(name)
+		if value == "" {
+			return "", fmt.Errorf("header %q not present", name)
+		}
+		return value, nil
+	}
+
+	return selectJSONPath(body, source)
+}
+
+var templateVariable = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// renderTemplate replaces {{name}} references with values from vars.
+func renderTemplate(s string, vars map[string]string) (string, error) {
+	return renderEscaped(s, vars, func(value string) string { return value })
+}
+
+// renderEscaped is renderTemplate passing every value through escape.
+func renderEscaped(s string, vars map[string]string, escape func(string) string) (string, error) {
+	var missing []string
+
+	rendered := templateVariable.ReplaceAllStringFunc(s, func(match string) string {
+		name := templateVariable.FindStringSubmatch(match)[1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		return escape(value)
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variables: %s", strings.Join(missing, ", "))
+	}
+
+	return rendered, nil
+}
+
+func stepName(i int, step Step) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return fmt.Sprintf("step %d", i+1)
+}
+
+func (s *Scheduler) saveSteps(ctx context.Context, runID int64, steps []StepResult) {
+	for position, step := range steps {
+		status := "success"
+		stepError := sql.NullString{}
+		if step.Err != nil {
+			status = "failed"
+			stepError = sql.NullString{String: step.Err.Error(), Valid: true}
+		}
+
+		err := s.db.CreateJobRunStep(ctx, db.CreateJobRunStepParams{
+			JobRunID:     runID,
+			Position:     int64(position),
+			Name:         step.Name,
+			Status:       status,
+			ResponseCode: sql.NullInt64{Int64: int64(step.StatusCode), Valid: step.StatusCode != 0},
+			DurationMs:   durationMs(step.Duration),
+			Error:        stepError,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to store step", "run_id", runID, "position", position, "error", err)
+			return
+		}
+	}
+}
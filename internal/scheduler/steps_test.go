@@ -0,0 +1,150 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newShopServer serves a login that hands out a session cookie, a token in
+// its JSON body and the order's path as Location, an order page that
+// requires all three, and an echo of request bodies.
+func newShopServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"user":"ana"}` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+		w.Header().Set("Location", "/orders/17")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"token":"t0k","roles":["admin"]}}`))
+	})
+	mux.HandleFunc("GET /orders/17", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != "s1" || r.Header.Get("Authorization") != "Bearer t0k" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("order 17 shipped"))
+	})
+	mux.HandleFunc("POST /echo", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func encodeTestSteps(t *testing.T, steps ...Step) sql.NullString {
+	t.Helper()
+
+	encoded, err := json.Marshal(steps)
+	if err != nil {
+		t.Fatalf("encode steps: %v", err)
+	}
+	return sql.NullString{String: string(encoded), Valid: true}
+}
+
+func TestStepsChaining(t *testing.T) {
+	server := newShopServer(t)
+	s := newTestScheduler(t)
+	job := createTestJob(t, s, db.CreateJobParams{
+		Type: JobTypeSteps,
+		Url:  server.URL,
+		Steps: encodeTestSteps(t,
+			Step{
+				Name:    "login",
+				Method:  http.MethodPost,
+				URL:     server.URL + "/login",
+				Body:    `{"user":"{{user}}"}`,
+				Extract: map[string]string{"token": "$.data.token", "order": "header:Location"},
+			},
+			Step{
+				Name:               "order",
+				URL:                server.URL + "{{order}}",
+				Headers:            map[string]string{"Authorization": "Bearer {{token}}"},
+				ExpectStatus:       http.StatusOK,
+				ExpectBodyContains: "shipped",
+			},
+		),
+	})
+
+	s.runJob(withVariables(context.Background(), map[string]string{"user": "ana"}), job)
+
+	run := jobRuns(t, s, job)[0]
+	if run.Status.String != "success" || run.ResponseCode.Int64 != http.StatusOK {
+		t.Fatalf("run = %s/%d (%s), want success/200", run.Status.String, run.ResponseCode.Int64, run.Error.String)
+	}
+
+	steps, err := s.db.GetJobRunSteps(context.Background(), run.ID)
+	if err != nil {
+		t.Fatalf("list steps: %v", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("stored %d steps, want 2", len(steps))
+	}
+	for i, name := range []string{"login", "order"} {
+		if steps[i].Name != name || steps[i].Status != "success" || steps[i].ResponseCode.Int64 != http.StatusOK {
+			t.Errorf("step %d = %s %s/%d, want %s success/200", i, steps[i].Name, steps[i].Status, steps[i].ResponseCode.Int64, name)
+		}
+	}
+}
+
+func TestStepsExecutor(t *testing.T) {
+	server := newShopServer(t)
+	login := Step{
+		Name:    "login",
+		Method:  http.MethodPost,
+		URL:     server.URL + "/login",
+		Body:    `{"user":"ana"}`,
+		Extract: map[string]string{"token": "$.data.token"},
+	}
+
+	tests := []struct {
+		name      string
+		steps     []Step
+		wantErr   string
+		wantSteps int
+	}{
+		{"json value", []Step{{URL: server.URL + "/login", Method: http.MethodPost, Body: `{"user":"ana"}`, Extract: map[string]string{"roles": "$.data.roles"}}, {URL: server.URL + "/echo", Method: http.MethodPost, Body: "roles={{roles}}", ExpectBodyContains: `roles=["admin"]`}}, "", 2},
+		{"unexpected status", []Step{login, {Name: "order", URL: server.URL + "/orders/17"}}, "step 2 (order): unexpected status code 403", 2},
+		{"expected status", []Step{{URL: server.URL + "/login", Method: http.MethodPost, ExpectStatus: http.StatusOK}}, "step 1 (step 1): expected status 200, got 401", 1},
+		{"body text", []Step{login, {URL: server.URL + "/login", Method: http.MethodPost, Body: `{"user":"ana"}`, ExpectBodyContains: "refund"}}, `step 2 (step 2): response body does not contain "refund"`, 2},
+		{"missing header", []Step{{URL: server.URL + "/login", Method: http.MethodPost, Body: `{"user":"ana"}`, Extract: map[string]string{"next": "header:Link"}}}, `step 1 (step 1): failed to extract next: header "Link" not present`, 1},
+		{"missing field", []Step{{URL: server.URL + "/login", Method: http.MethodPost, Body: `{"user":"ana"}`, Extract: map[string]string{"id": "$.data.id"}}}, `step 1 (step 1): failed to extract id: $.data.id: field "id" not found`, 1},
+		{"undefined variable", []Step{login, {Name: "order", URL: server.URL + "/orders/{{order_id}}"}, login}, "step 2 (order): undefined variables: order_id", 2},
+		{"no steps", nil, "job has no steps", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &stepsExecutor{clients: newClientCache(), hosts: newHostLimiter(config.HostLimits{})}
+			job := db.Job{Type: JobTypeSteps, Url: server.URL, Steps: encodeTestSteps(t, tt.steps...)}
+
+			result, err := executor.Execute(context.Background(), job)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)) {
+				t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+			}
+			if len(result.Steps) != tt.wantSteps {
+				t.Errorf("ran %d steps, want %d", len(result.Steps), tt.wantSteps)
+			}
+		})
+	}
+}
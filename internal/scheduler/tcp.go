@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"lucasbonna/pulse/db"
+	"time"
+)
+
+// tcpExecutor succeeds when a TCP connection to host:port, or to the
+// address a resolve override gives for it, can be opened.
+type tcpExecutor struct{}
+
+func (e *tcpExecutor) Execute(ctx context.Context, job db.Job) (Result, error) {
+	result := Result{Host: job.Url}
+
+	overrides, err := clientConfigFromJob(job).resolveOverrides()
+	if err != nil {
+		return result, err
+	}
+
+	dial := overrideDialer(overrides)
+	start := time.Now()
+
+	conn, err := dial(ctx, "tcp", job.Url)
+	result.Timings.TCPConnect = time.Since(start)
+	result.Timings.Total = result.Timings.TCPConnect
+	if err != nil {
+		return result, fmt.Errorf("connect failed: %w", err)
+	}
+
+	conn.Close()
+	return result, nil
+}
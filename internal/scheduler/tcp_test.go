@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"net"
+	"testing"
+	"time"
+)
+
+// closedAddress returns a local address nothing listens on.
+func closedAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+func TestTCPExecutor(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	tests := []struct {
+		name    string
+		job     db.Job
+		wantErr bool
+	}{
+		{"listening", db.Job{Url: listener.Addr().String()}, false},
+		{"closed port", db.Job{Url: closedAddress(t)}, true},
+		{"resolve override", db.Job{
+			Url:     "pulse.invalid:" + port,
+			Resolve: sql.NullString{String: `["pulse.invalid:` + port + `:127.0.0.1"]`, Valid: true},
+		}, false},
+		{"override for another port", db.Job{
+			Url:     "pulse.invalid:" + port,
+			Resolve: sql.NullString{String: `["pulse.invalid:1:127.0.0.1"]`, Valid: true},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := (&tcpExecutor{}).Execute(ctx, tt.job)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute error = %v, want error %v", err, tt.wantErr)
+			}
+			if result.Host != tt.job.Url {
+				t.Errorf("Host = %q, want %q", result.Host, tt.job.Url)
+			}
+			if result.Timings.Total != result.Timings.TCPConnect {
+				t.Errorf("Total = %s, want the connect time %s", result.Timings.Total, result.Timings.TCPConnect)
+			}
+		})
+	}
+}
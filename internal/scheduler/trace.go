@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// timings is the per-phase breakdown of a single run. Phases that did not
+// happen, such as DNS and connect on a reused connection, stay zero.
+type timings struct {
+	DNSLookup       time.Duration
+	TCPConnect      time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+	Total           time.Duration
+}
+
+// requestTracer collects timings through httptrace hooks. Hooks can fire
+// from several goroutines when dialing multiple addresses, hence the mutex.
+type requestTracer struct {
+	mutex        sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timings      timings
+}
+
+func newRequestTracer() *requestTracer {
+	return &requestTracer{start: time.Now()}
+}
+
+func (t *requestTracer) withContext(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.timings.DNSLookup += time.Since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.timings.TCPConnect += time.Since(t.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.timings.TLSHandshake += time.Since(t.tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.timings.TimeToFirstByte = time.Since(t.start)
+		},
+	})
+}
+
+// finish stamps the total duration and returns the collected timings.
+func (t *requestTracer) finish() timings {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.timings.Total = time.Since(t.start)
+	return t.timings
+}
+
+func durationMs(d time.Duration) sql.NullInt64 {
+	if d <= 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: d.Milliseconds(), Valid: true}
+}
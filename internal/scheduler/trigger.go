@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"context"
+	"lucasbonna/pulse/db"
+	"maps"
+)
+
+type variablesKey struct{}
+
+// withVariables makes vars available to the templates of the run executed
+// with ctx.
+func withVariables(ctx context.Context, vars map[string]string) context.Context {
+	return context.WithValue(ctx, variablesKey{}, vars)
+}
+
+// variablesFrom returns a copy of the run's variables; scheduled runs have
+// none.
+func variablesFrom(ctx context.Context) map[string]string {
+	vars, _ := triggerVariables(ctx)
+	return vars
+}
+
+// triggerVariables returns a copy of the run's variables and whether the
+// run was triggered.
+func triggerVariables(ctx context.Context) (map[string]string, bool) {
+	vars := make(map[string]string)
+	triggered, ok := ctx.Value(variablesKey{}).(map[string]string)
+	maps.Copy(vars, triggered)
+	return vars, ok
+}
+
+// Trigger runs job right away, outside its schedule, with vars available as
+// {{name}} in its templates. It returns ErrJobRunning when a run of the job
+// is in progress.
+func (s *Scheduler) Trigger(ctx context.Context, job db.Job, vars map[string]string) error {
+	if !s.claimJob(job.ID) {
+		return ErrJobRunning
+	}
+
+	// the run outlives the request that triggered it but keeps its trace
+	go s.executeJob(withVariables(context.WithoutCancel(ctx), vars), job)
+	return nil
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"lucasbonna/pulse/db"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// websocketExecutor opens a WebSocket, optionally sends send_message and
+// waits for a message containing expect_message (any message when empty).
+// TCPConnect holds the time to a completed handshake and TimeToFirstByte
+// the latency of the first message received after it.
+type websocketExecutor struct{}
+
+func (e *websocketExecutor) Execute(ctx context.Context, job db.Job) (Result, error) {
+	var result Result
+
+	cfg := clientConfigFromJob(job)
+
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return result, err
+	}
+
+	dialer := websocket.Dialer{
+		TLSClientConfig:  tlsConfig,
+		HandshakeTimeout: time.Duration(job.TimeoutSeconds) * time.Second,
+	}
+	if cfg.ProxyURL != "" {
+		proxyURL, err := ParseProxyURL(cfg.ProxyURL)
+		if err != nil {
+			return result, err
+		}
+		dialer.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	overrides, err := cfg.resolveOverrides()
+	if err != nil {
+		return result, err
+	}
+	if overrides != nil {
+		dialer.NetDialContext = overrideDialer(overrides)
+	}
+
+	// propagate the run's trace context on the handshake request
+	header := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+
+	start := time.Now()
+	conn, resp, err := dialer.DialContext(ctx, job.Url, header)
+	result.Timings.TCPConnect = time.Since(start)
+	if resp != nil {
+		result.StatusCode = resp.StatusCode
+		result.Host = resp.Request.URL.Host
+		if resp.TLS != nil {
+			result.Certificates = resp.TLS.PeerCertificates
+		}
+	}
+	if err != nil {
+		result.Timings.Total = time.Since(start)
+		return result, fmt.Errorf("websocket handshake failed: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetReadDeadline(deadline)
+		conn.SetWriteDeadline(deadline)
+	}
+
+	if job.SendMessage.Valid && job.SendMessage.String != "" {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(job.SendMessage.String)); err != nil {
+			result.Timings.Total = time.Since(start)
+			return result, fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+
+	waitStart := time.Now()
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			result.Timings.Total = time.Since(start)
+			return result, fmt.Errorf("no matching message received: %w", err)
+		}
+
+		if result.Timings.TimeToFirstByte == 0 {
+			result.Timings.TimeToFirstByte = time.Since(waitStart)
+		}
+
+		if strings.Contains(string(message), job.ExpectMessage.String) {
+			break
+		}
+	}
+
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+
+	result.Timings.Total = time.Since(start)
+	return result, nil
+}
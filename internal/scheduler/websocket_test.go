@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"lucasbonna/pulse/db"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newEchoServer serves a WebSocket that greets with "welcome", echoes the
+// first message it receives as "echo: <message>" and closes.
+func newEchoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.TextMessage, []byte("welcome"))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, append([]byte("echo: "), message...))
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebSocketExecutor(t *testing.T) {
+	server := newEchoServer(t)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	_, port, _ := strings.Cut(strings.TrimPrefix(server.URL, "http://"), ":")
+
+	tests := []struct {
+		name       string
+		job        db.Job
+		wantErr    string
+		wantStatus int
+	}{
+		{"any message", db.Job{Url: url}, "", http.StatusSwitchingProtocols},
+		{"echoed message", db.Job{
+			Url:           url,
+			SendMessage:   sql.NullString{String: "ping", Valid: true},
+			ExpectMessage: sql.NullString{String: "echo: ping", Valid: true},
+		}, "", http.StatusSwitchingProtocols},
+		{"message never arrives", db.Job{
+			Url:           url,
+			SendMessage:   sql.NullString{String: "ping", Valid: true},
+			ExpectMessage: sql.NullString{String: "pong", Valid: true},
+		}, "no matching message received", http.StatusSwitchingProtocols},
+		{"not a websocket", db.Job{Url: url + "/missing"}, "websocket handshake failed", http.StatusNotFound},
+		{"resolve override", db.Job{
+			Url:     "ws://pulse.invalid:" + port + "/ws",
+			Resolve: sql.NullString{String: `["pulse.invalid:` + port + `:127.0.0.1"]`, Valid: true},
+		}, "", http.StatusSwitchingProtocols},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			tt.job.TimeoutSeconds = 5
+			result, err := (&websocketExecutor{}).Execute(ctx, tt.job)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)) {
+				t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+			}
+			if result.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", result.StatusCode, tt.wantStatus)
+			}
+			if tt.wantErr == "" && result.Timings.TimeToFirstByte == 0 {
+				t.Error("TimeToFirstByte was not recorded")
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// schema.sql only uses CREATE TABLE IF NOT EXISTS, so columns added to a
+// table after it shipped never reach databases created by older versions.
+// Every such column is listed here and added on startup when missing.
+var columnMigrations = []columnMigration{
+	{"jobs", "tls_client_cert_file", "TEXT"},
+	{"jobs", "tls_client_key_file", "TEXT"},
+	{"jobs", "tls_ca_file", "TEXT"},
+	{"jobs", "tls_server_name", "TEXT"},
+	{"jobs", "tls_min_version", "TEXT"},
+	{"jobs", "tls_insecure_skip_verify", "boolean DEFAULT 0"},
+	{"jobs", "proxy_url", "TEXT"},
+	{"jobs", "follow_redirects", "boolean DEFAULT 1"},
+	{"jobs", "max_redirects", "INTEGER DEFAULT 10"},
+	{"jobs", "resolve", "TEXT"},
+	{"jobs", "cert_expiry_days", "INTEGER"},
+	{"jobs", "cert_expiry_action", "TEXT NOT NULL DEFAULT 'fail'"},
+	{"jobs", "type", "TEXT NOT NULL DEFAULT 'http'"},
+	{"jobs", "timeout_seconds", "INTEGER NOT NULL DEFAULT 30"},
+	{"jobs", "dns_record_type", "TEXT"},
+	{"jobs", "dns_expected", "TEXT"},
+	{"jobs", "dns_server", "TEXT"},
+	{"jobs", "grpc_service", "TEXT"},
+	{"jobs", "grpc_tls", "boolean DEFAULT 0"},
+	{"jobs", "fail_on_error_status", "boolean DEFAULT 0"},
+	{"jobs", "steps", "TEXT"},
+	{"jobs", "send_message", "TEXT"},
+	{"jobs", "expect_message", "TEXT"},
+	{"jobs", "track_changes", "boolean DEFAULT 0"},
+	{"jobs", "track_json_path", "TEXT"},
+	{"jobs", "max_body_bytes", "INTEGER NOT NULL DEFAULT 65536"},
+	{"jobs", "redact_headers", "TEXT"},
+	{"jobs", "retention_keep_runs", "INTEGER"},
+	{"jobs", "retention_keep_days", "INTEGER"},
+	{"jobs", "retention_keep_failures_days", "INTEGER"},
+	{"jobs", "alert_channels", "TEXT"},
+	{"jobs", "alert_consecutive_failures", "INTEGER"},
+	{"jobs", "alert_failure_rate", "INTEGER"},
+	{"jobs", "alert_failure_window", "INTEGER NOT NULL DEFAULT 20"},
+	{"jobs", "alert_renotify_minutes", "INTEGER"},
+	{"jobs", "alert_escalation_channels", "TEXT"},
+	{"jobs", "alert_escalation_minutes", "INTEGER"},
+	{"jobs", "tags", "TEXT"},
+	{"jobs", "heartbeat_token", "TEXT"},
+	{"jobs", "heartbeat_grace_seconds", "INTEGER"},
+	{"jobs", "heartbeat_started_at", "DATETIME"},
+	{"jobs", "trigger_token", "TEXT"},
+	{"jobs", "trigger_secret", "TEXT"},
+	{"jobs", "trigger_rate_limit", "INTEGER"},
+	{"jobs", "breaker_failure_threshold", "INTEGER"},
+	{"jobs", "breaker_probe_seconds", "INTEGER"},
+	{"jobs", "breaker_state", "TEXT NOT NULL DEFAULT 'closed'"},
+	{"jobs", "breaker_failures", "INTEGER NOT NULL DEFAULT 0"},
+	{"jobs", "breaker_opened_at", "DATETIME"},
+	{"jobs", "body", "TEXT"},
+	{"jobs", "retry_attempts", "INTEGER"},
+	{"jobs", "retry_delay_seconds", "INTEGER"},
+	{"jobs", "retry_after_host_wide", "boolean DEFAULT 0"},
+	{"job_runs", "dns_ms", "INTEGER"},
+	{"job_runs", "connect_ms", "INTEGER"},
+	{"job_runs", "tls_ms", "INTEGER"},
+	{"job_runs", "ttfb_ms", "INTEGER"},
+	{"job_runs", "total_ms", "INTEGER"},
+	{"job_runs", "error", "TEXT"},
+	{"job_runs", "body_hash", "TEXT"},
+	{"job_runs", "changed", "boolean DEFAULT 0"},
+	{"job_runs", "body_diff", "TEXT"},
+	{"job_runs", "response_headers", "TEXT"},
+	{"job_runs", "response_encoding", "TEXT"},
+	{"job_runs", "response_size", "INTEGER"},
+	{"job_runs", "response_truncated", "boolean DEFAULT 0"},
+	{"job_runs", "replay_of_run_id", "INTEGER"},
+}
+
+func addMissingColumns(ctx context.Context, database *sql.DB) error {
+	for _, m := range columnMigrations {
+		var count int
+		err := database.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", m.table, m.column,
+		).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to inspect table %s: %w", m.table, err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)
+		if _, err := database.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", m.table, m.column, err)
+		}
+	}
+
+	return nil
+}
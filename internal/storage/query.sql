@@ -3,7 +3,25 @@ SELECT * FROM jobs WHERE id = ? LIMIT 1;
 
 -- name: UpdateJob :one
 UPDATE jobs
-SET name = ?, url = ?, method = ?, headers = ?, interval_seconds = ?, active = ?
+SET name = ?, url = ?, method = ?, headers = ?, interval_seconds = ?, active = ?,
+    tls_client_cert_file = ?, tls_client_key_file = ?, tls_ca_file = ?,
+    tls_server_name = ?, tls_min_version = ?, tls_insecure_skip_verify = ?,
+    proxy_url = ?, follow_redirects = ?, max_redirects = ?, resolve = ?,
+    cert_expiry_days = ?, cert_expiry_action = ?,
+    type = ?, timeout_seconds = ?,
+    dns_record_type = ?, dns_expected = ?, dns_server = ?,
+    grpc_service = ?, grpc_tls = ?, fail_on_error_status = ?, steps = ?,
+    send_message = ?, expect_message = ?,
+    track_changes = ?, track_json_path = ?,
+    max_body_bytes = ?, redact_headers = ?,
+    retention_keep_runs = ?, retention_keep_days = ?, retention_keep_failures_days = ?,
+    alert_channels = ?, alert_consecutive_failures = ?, alert_failure_rate = ?, alert_failure_window = ?,
+    alert_renotify_minutes = ?, alert_escalation_channels = ?, alert_escalation_minutes = ?,
+    tags = ?, heartbeat_token = ?, heartbeat_grace_seconds = ?,
+    trigger_token = ?, trigger_secret = ?, trigger_rate_limit = ?,
+    breaker_failure_threshold = ?, breaker_probe_seconds = ?,
+    body = ?, retry_attempts = ?, retry_delay_seconds = ?,
+    retry_after_host_wide = ?
 WHERE id = ?
 RETURNING *;
 
@@ -20,9 +38,45 @@ ORDER BY next_run_at ASC;
 
 -- name: CreateJob :one
 INSERT INTO jobs (
-  name, url, method, headers, interval_seconds, next_run_at, active
+  name, url, method, headers, interval_seconds, next_run_at, active,
+  tls_client_cert_file, tls_client_key_file, tls_ca_file,
+  tls_server_name, tls_min_version, tls_insecure_skip_verify,
+  proxy_url, follow_redirects, max_redirects, resolve,
+  cert_expiry_days, cert_expiry_action,
+  type, timeout_seconds,
+  dns_record_type, dns_expected, dns_server,
+  grpc_service, grpc_tls, fail_on_error_status, steps,
+  send_message, expect_message,
+  track_changes, track_json_path,
+  max_body_bytes, redact_headers,
+  retention_keep_runs, retention_keep_days, retention_keep_failures_days,
+  alert_channels, alert_consecutive_failures, alert_failure_rate, alert_failure_window,
+  alert_renotify_minutes, alert_escalation_channels, alert_escalation_minutes,
+  tags, heartbeat_token, heartbeat_grace_seconds,
+  trigger_token, trigger_secret, trigger_rate_limit,
+  breaker_failure_threshold, breaker_probe_seconds,
+  body, retry_attempts, retry_delay_seconds,
+  retry_after_host_wide
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?,
+  ?, ?, ?,
+  ?, ?, ?,
+  ?, ?, ?, ?,
+  ?, ?,
+  ?, ?,
+  ?, ?, ?,
+  ?, ?, ?, ?,
+  ?, ?,
+  ?, ?,
+  ?, ?,
+  ?, ?, ?,
+  ?, ?, ?, ?,
+  ?, ?, ?,
+  ?, ?, ?,
+  ?, ?, ?,
+  ?, ?,
+  ?, ?, ?,
+  ?
 )
 RETURNING *;
 
@@ -31,16 +85,362 @@ UPDATE jobs
 SET next_run_at = ?
 WHERE id = ?;
 
+-- name: GetJobByHeartbeatToken :one
+SELECT * FROM jobs WHERE heartbeat_token = ? LIMIT 1;
+
+-- name: GetJobByTriggerToken :one
+SELECT * FROM jobs WHERE trigger_token = ? LIMIT 1;
+
+-- name: StartJobHeartbeat :exec
+UPDATE jobs
+SET heartbeat_started_at = ?
+WHERE id = ?;
+
+-- name: FinishJobHeartbeat :exec
+UPDATE jobs
+SET heartbeat_started_at = NULL, next_run_at = ?
+WHERE id = ?;
+
+-- name: UpdateJobBreaker :exec
+UPDATE jobs
+SET breaker_state = ?, breaker_failures = ?, breaker_opened_at = ?
+WHERE id = ?;
+
 -- name: CreateJobRun :one
-INSERT INTO job_runs (job_id, status, started_at)
-VALUES (?, ?, ?)
+INSERT INTO job_runs (job_id, status, started_at, replay_of_run_id)
+VALUES (?, ?, ?, ?)
 RETURNING *;
 
 -- name: UpdateJobRun :exec
 UPDATE job_runs
-SET status = ?, response_code = ?, response_body = ?, finished_at = ?
+SET status = ?, response_code = ?, response_body = ?, finished_at = ?,
+    dns_ms = ?, connect_ms = ?, tls_ms = ?, ttfb_ms = ?, total_ms = ?,
+    error = ?, body_hash = ?, changed = ?, body_diff = ?,
+    response_headers = ?, response_encoding = ?, response_size = ?, response_truncated = ?
 WHERE id = ?;
 
+-- name: GetJobRunByID :one
+SELECT * FROM job_runs WHERE id = ? LIMIT 1;
+
+-- name: GetJobRuns :many
+SELECT * FROM job_runs
+WHERE job_id = ?
+ORDER BY id DESC
+LIMIT ?;
+
 -- name: DeleteJob :exec
 DELETE FROM jobs
 WHERE id = ?;
+
+-- name: CreateRunCertificate :exec
+INSERT INTO run_certificates (
+  job_run_id, job_id, host, position, subject, issuer, not_after
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetCertificateExpiries :many
+SELECT c.job_id, j.name AS job_name, c.job_run_id, c.host, c.subject, c.issuer, c.not_after
+FROM run_certificates c
+JOIN jobs j ON j.id = c.job_id
+WHERE c.position = 0
+  AND c.job_run_id = (
+    SELECT MAX(latest.job_run_id) FROM run_certificates latest
+    WHERE latest.job_id = c.job_id
+  )
+ORDER BY c.not_after ASC;
+
+-- name: CreateJobRunStep :exec
+INSERT INTO job_run_steps (
+  job_run_id, position, name, status, response_code, duration_ms, error
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: GetJobRunSteps :many
+SELECT * FROM job_run_steps
+WHERE job_run_id = ?
+ORDER BY position;
+
+-- name: GetTrackedContent :one
+SELECT * FROM job_tracked_content WHERE job_id = ? LIMIT 1;
+
+-- name: UpsertTrackedContent :exec
+INSERT INTO job_tracked_content (job_id, body_hash, content, updated_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (job_id) DO UPDATE
+SET body_hash = excluded.body_hash, content = excluded.content, updated_at = excluded.updated_at;
+
+-- name: GetNthLatestJobRunID :one
+SELECT id FROM job_runs
+WHERE job_id = ?
+ORDER BY id DESC
+LIMIT 1 OFFSET ?;
+
+-- name: GetExpiredJobRunIDs :many
+-- Failed runs (and runs left "running" by a crash) only expire by age at
+-- failed_before; every other run expires at before or when older than
+-- min_kept_id.
+SELECT id FROM job_runs
+WHERE job_id = sqlc.arg(job_id)
+  AND (
+    (status IN ('failed', 'running') AND started_at < sqlc.arg(failed_before))
+    OR (status NOT IN ('failed', 'running') AND (started_at < sqlc.arg(before) OR id < sqlc.arg(min_kept_id)))
+  )
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
+
+-- name: DeleteRunCertificatesByRunIDs :exec
+DELETE FROM run_certificates WHERE job_run_id IN (sqlc.slice('ids'));
+
+-- name: DeleteJobRunStepsByRunIDs :exec
+DELETE FROM job_run_steps WHERE job_run_id IN (sqlc.slice('ids'));
+
+-- name: DeleteJobRunsByIDs :execrows
+DELETE FROM job_runs WHERE id IN (sqlc.slice('ids'));
+
+-- name: GetOrphanedJobRunIDs :many
+-- Runs left behind by deleted jobs.
+SELECT id FROM job_runs
+WHERE job_id NOT IN (SELECT id FROM jobs)
+ORDER BY id
+LIMIT ?;
+
+-- name: DeleteOrphanedTrackedContent :exec
+DELETE FROM job_tracked_content WHERE job_id NOT IN (SELECT id FROM jobs);
+
+-- name: DeleteOrphanedAlertStates :exec
+DELETE FROM job_alert_states WHERE job_id NOT IN (SELECT id FROM jobs);
+
+-- name: DeleteOrphanedIncidents :exec
+DELETE FROM incidents WHERE job_id NOT IN (SELECT id FROM jobs);
+
+-- name: DeleteOrphanedDeadLetters :exec
+DELETE FROM dead_letters WHERE job_id NOT IN (SELECT id FROM jobs);
+
+-- name: UpsertJobRollup :exec
+INSERT INTO job_rollups (job_id, bucket_start, status, runs, latency_sum_ms, latency_count)
+VALUES (?, ?, ?, 1, ?, ?)
+ON CONFLICT (job_id, bucket_start, status) DO UPDATE
+SET runs = runs + 1,
+    latency_sum_ms = latency_sum_ms + excluded.latency_sum_ms,
+    latency_count = latency_count + excluded.latency_count;
+
+-- name: UpsertJobLatencyRollup :exec
+INSERT INTO job_latency_rollups (job_id, bucket_start, le_ms, runs)
+VALUES (?, ?, ?, 1)
+ON CONFLICT (job_id, bucket_start, le_ms) DO UPDATE
+SET runs = runs + 1;
+
+-- name: GetJobRollups :many
+SELECT * FROM job_rollups
+WHERE job_id = ? AND bucket_start >= ?
+ORDER BY bucket_start;
+
+-- name: GetJobLatencyHistogram :many
+SELECT le_ms, CAST(SUM(runs) AS INTEGER) AS runs
+FROM job_latency_rollups
+WHERE job_id = ? AND bucket_start >= ?
+GROUP BY le_ms;
+
+-- name: DeleteJobRollupsBefore :execrows
+DELETE FROM job_rollups WHERE bucket_start < ?;
+
+-- name: DeleteJobLatencyRollupsBefore :execrows
+DELETE FROM job_latency_rollups WHERE bucket_start < ?;
+
+-- name: CountRecentJobRunFailures :one
+-- Looks at the job's last finished runs, newest first.
+SELECT CAST(COUNT(*) AS INTEGER) AS runs,
+       CAST(COALESCE(SUM(status = 'failed'), 0) AS INTEGER) AS failures
+FROM (
+  SELECT status FROM job_runs
+  WHERE job_id = ? AND status != 'running'
+  ORDER BY id DESC
+  LIMIT ?
+);
+
+-- name: CreateNotificationChannel :one
+INSERT INTO notification_channels (name, type, config, created_at)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetNotificationChannel :one
+SELECT * FROM notification_channels WHERE id = ? LIMIT 1;
+
+-- name: GetNotificationChannels :many
+SELECT * FROM notification_channels
+ORDER BY id;
+
+-- name: GetNotificationChannelsByIDs :many
+SELECT * FROM notification_channels
+WHERE id IN (sqlc.slice('ids'))
+ORDER BY id;
+
+-- name: UpdateNotificationChannel :one
+UPDATE notification_channels
+SET name = ?, type = ?, config = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: DeleteNotificationChannel :execrows
+DELETE FROM notification_channels WHERE id = ?;
+
+-- name: GetJobAlertState :one
+SELECT * FROM job_alert_states WHERE job_id = ? LIMIT 1;
+
+-- name: UpsertJobAlertState :exec
+INSERT INTO job_alert_states (job_id, state, consecutive_failures, changed_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (job_id) DO UPDATE
+SET state = excluded.state,
+    consecutive_failures = excluded.consecutive_failures,
+    changed_at = excluded.changed_at;
+
+-- name: CreateIncident :one
+INSERT INTO incidents (
+  job_id, state, reason, last_run_id, last_error, consecutive_failures,
+  opened_at, last_notified_at
+) VALUES (
+  ?, 'open', ?, ?, ?, ?,
+  ?, ?
+)
+RETURNING *;
+
+-- name: GetIncident :one
+SELECT * FROM incidents WHERE id = ? LIMIT 1;
+
+-- name: GetActiveIncidentForJob :one
+SELECT * FROM incidents
+WHERE job_id = ? AND state != 'resolved'
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetOpenIncidents :many
+SELECT * FROM incidents
+WHERE state = 'open'
+ORDER BY id;
+
+-- name: ListIncidents :many
+SELECT * FROM incidents
+WHERE (CAST(sqlc.arg(state) AS TEXT) = '' OR state = sqlc.arg(state))
+  AND (CAST(sqlc.arg(job_id) AS INTEGER) = 0 OR job_id = sqlc.arg(job_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: UpdateIncidentFailure :exec
+UPDATE incidents
+SET last_run_id = ?, last_error = ?, consecutive_failures = ?
+WHERE id = ? AND state != 'resolved';
+
+-- name: AcknowledgeIncident :one
+UPDATE incidents
+SET state = 'acknowledged', acknowledged_at = ?, acknowledged_by = ?
+WHERE id = ? AND state = 'open'
+RETURNING *;
+
+-- name: ResolveIncident :one
+UPDATE incidents
+SET state = 'resolved', resolved_at = ?
+WHERE id = ? AND state != 'resolved'
+RETURNING *;
+
+-- name: MarkIncidentNotified :execrows
+UPDATE incidents
+SET last_notified_at = ?, notifications = notifications + 1
+WHERE id = ? AND state = 'open';
+
+-- name: MarkIncidentEscalated :execrows
+UPDATE incidents
+SET escalated_at = ?, last_notified_at = ?, notifications = notifications + 1
+WHERE id = ? AND state = 'open' AND escalated_at IS NULL;
+
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (url, secret, events, active, created_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions WHERE id = ? LIMIT 1;
+
+-- name: GetWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+ORDER BY id;
+
+-- name: GetActiveWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE active = 1
+ORDER BY id;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET url = ?, secret = ?, events = ?, active = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions WHERE id = ?;
+
+-- name: DeleteWebhookDeliveriesBySubscription :exec
+DELETE FROM webhook_deliveries WHERE subscription_id = ?;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+  subscription_id, event_id, event_type, payload, status, next_attempt_at, created_at
+) VALUES (
+  ?, ?, ?, ?, 'pending', ?, ?
+)
+RETURNING *;
+
+-- name: GetDueWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at <= ?
+ORDER BY next_attempt_at
+LIMIT ?;
+
+-- name: UpdateWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = ?, attempts = ?, next_attempt_at = ?, last_attempt_at = ?,
+    response_code = ?, error = ?
+WHERE id = ?;
+
+-- name: GetWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = ?
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: DeleteWebhookDeliveriesBefore :execrows
+DELETE FROM webhook_deliveries WHERE status != 'pending' AND created_at < ?;
+
+-- name: CreateDeadLetter :one
+INSERT INTO dead_letters (
+  job_id, job_run_id, method, url, headers, body, attempts, response_code, error, created_at
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetDeadLetterByID :one
+SELECT * FROM dead_letters WHERE id = ? LIMIT 1;
+
+-- name: ListDeadLetters :many
+SELECT * FROM dead_letters
+WHERE (CAST(sqlc.arg(job_id) AS INTEGER) = 0 OR job_id = sqlc.arg(job_id))
+  AND (CAST(sqlc.arg(status) AS TEXT) = ''
+    OR (sqlc.arg(status) = 'pending' AND replayed_at IS NULL)
+    OR (sqlc.arg(status) = 'replayed' AND replayed_at IS NOT NULL))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: LinkDeadLetterReplay :exec
+UPDATE dead_letters SET replay_run_id = ? WHERE id = ?;
+
+-- name: MarkDeadLetterReplayed :exec
+UPDATE dead_letters SET replayed_at = ? WHERE id = ?;
+
+-- name: DeleteDeadLetter :exec
+DELETE FROM dead_letters WHERE id = ?;
+
+-- name: DeleteDeadLettersReplayedBefore :execrows
+DELETE FROM dead_letters WHERE replayed_at IS NOT NULL AND replayed_at < ?;
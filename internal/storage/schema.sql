@@ -6,7 +6,60 @@ CREATE TABLE IF NOT EXISTS jobs (
   headers TEXT,
   interval_seconds INTEGER NOT NULL,
   next_run_at DATETIME,
-  active boolean DEFAULT 1
+  active boolean DEFAULT 1,
+  tls_client_cert_file TEXT,
+  tls_client_key_file TEXT,
+  tls_ca_file TEXT,
+  tls_server_name TEXT,
+  tls_min_version TEXT,
+  tls_insecure_skip_verify boolean DEFAULT 0,
+  proxy_url TEXT,
+  follow_redirects boolean DEFAULT 1,
+  max_redirects INTEGER DEFAULT 10,
+  resolve TEXT,
+  cert_expiry_days INTEGER,
+  cert_expiry_action TEXT NOT NULL DEFAULT 'fail',
+  type TEXT NOT NULL DEFAULT 'http',
+  timeout_seconds INTEGER NOT NULL DEFAULT 30,
+  dns_record_type TEXT,
+  dns_expected TEXT,
+  dns_server TEXT,
+  grpc_service TEXT,
+  grpc_tls boolean DEFAULT 0,
+  fail_on_error_status boolean DEFAULT 0,
+  steps TEXT,
+  send_message TEXT,
+  expect_message TEXT,
+  track_changes boolean DEFAULT 0,
+  track_json_path TEXT,
+  max_body_bytes INTEGER NOT NULL DEFAULT 65536,
+  redact_headers TEXT,
+  retention_keep_runs INTEGER,
+  retention_keep_days INTEGER,
+  retention_keep_failures_days INTEGER,
+  alert_channels TEXT,
+  alert_consecutive_failures INTEGER,
+  alert_failure_rate INTEGER,
+  alert_failure_window INTEGER NOT NULL DEFAULT 20,
+  alert_renotify_minutes INTEGER,
+  alert_escalation_channels TEXT,
+  alert_escalation_minutes INTEGER,
+  tags TEXT,
+  heartbeat_token TEXT,
+  heartbeat_grace_seconds INTEGER,
+  heartbeat_started_at DATETIME,
+  trigger_token TEXT,
+  trigger_secret TEXT,
+  trigger_rate_limit INTEGER,
+  breaker_failure_threshold INTEGER,
+  breaker_probe_seconds INTEGER,
+  breaker_state TEXT NOT NULL DEFAULT 'closed',
+  breaker_failures INTEGER NOT NULL DEFAULT 0,
+  breaker_opened_at DATETIME,
+  body TEXT,
+  retry_attempts INTEGER,
+  retry_delay_seconds INTEGER,
+  retry_after_host_wide boolean DEFAULT 0
 );
 
 CREATE TABLE IF NOT EXISTS job_runs (
@@ -14,8 +67,172 @@ CREATE TABLE IF NOT EXISTS job_runs (
     job_id INTEGER NOT NULL,
     status TEXT,
     response_code INTEGER,
-    response_body TEXT,
+    response_body BLOB,
     started_at DATETIME,
     finished_at DATETIME,
+    dns_ms INTEGER,
+    connect_ms INTEGER,
+    tls_ms INTEGER,
+    ttfb_ms INTEGER,
+    total_ms INTEGER,
+    error TEXT,
+    body_hash TEXT,
+    changed boolean DEFAULT 0,
+    body_diff TEXT,
+    response_headers TEXT,
+    response_encoding TEXT,
+    response_size INTEGER,
+    response_truncated boolean DEFAULT 0,
+    replay_of_run_id INTEGER,
     FOREIGN KEY (job_id) REFERENCES jobs(id)
 );
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_id ON job_runs(job_id, id);
+
+CREATE TABLE IF NOT EXISTS run_certificates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_run_id INTEGER NOT NULL,
+    job_id INTEGER NOT NULL,
+    host TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    subject TEXT NOT NULL,
+    issuer TEXT NOT NULL,
+    not_after DATETIME NOT NULL,
+    FOREIGN KEY (job_run_id) REFERENCES job_runs(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_certificates_job_id ON run_certificates(job_id, job_run_id);
+
+CREATE TABLE IF NOT EXISTS job_run_steps (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_run_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    status TEXT NOT NULL,
+    response_code INTEGER,
+    duration_ms INTEGER,
+    error TEXT,
+    FOREIGN KEY (job_run_id) REFERENCES job_runs(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_run_steps_job_run_id ON job_run_steps(job_run_id, position);
+
+CREATE TABLE IF NOT EXISTS job_tracked_content (
+    job_id INTEGER PRIMARY KEY,
+    body_hash TEXT NOT NULL,
+    content TEXT NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (job_id) REFERENCES jobs(id)
+);
+
+-- hourly aggregates written as runs finish; bucket_start is a unix timestamp
+CREATE TABLE IF NOT EXISTS job_rollups (
+    job_id INTEGER NOT NULL,
+    bucket_start INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    runs INTEGER NOT NULL,
+    latency_sum_ms INTEGER NOT NULL,
+    latency_count INTEGER NOT NULL,
+    PRIMARY KEY (job_id, bucket_start, status)
+);
+
+-- latency histogram per hour; le_ms is the bucket's upper bound, -1 for
+-- the overflow bucket
+CREATE TABLE IF NOT EXISTS job_latency_rollups (
+    job_id INTEGER NOT NULL,
+    bucket_start INTEGER NOT NULL,
+    le_ms INTEGER NOT NULL,
+    runs INTEGER NOT NULL,
+    PRIMARY KEY (job_id, bucket_start, le_ms)
+);
+
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    config TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+-- alerting state per job: healthy or failing, updated as runs finish
+CREATE TABLE IF NOT EXISTS job_alert_states (
+    job_id INTEGER PRIMARY KEY,
+    state TEXT NOT NULL,
+    consecutive_failures INTEGER NOT NULL,
+    changed_at DATETIME NOT NULL,
+    FOREIGN KEY (job_id) REFERENCES jobs(id)
+);
+
+-- one incident per failing period of a job; state is open, acknowledged or
+-- resolved
+CREATE TABLE IF NOT EXISTS incidents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL,
+    state TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    last_run_id INTEGER,
+    last_error TEXT,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    opened_at DATETIME NOT NULL,
+    acknowledged_at DATETIME,
+    acknowledged_by TEXT,
+    escalated_at DATETIME,
+    resolved_at DATETIME,
+    last_notified_at DATETIME NOT NULL,
+    notifications INTEGER NOT NULL DEFAULT 1,
+    FOREIGN KEY (job_id) REFERENCES jobs(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_incidents_job_id ON incidents(job_id, state);
+
+-- subscribers of outbound event webhooks; events is a JSON list of event
+-- types, ["*"] for all
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT NOT NULL,
+    active boolean NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL
+);
+
+-- one row per event and subscriber; status is pending, succeeded or failed
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL,
+    event_id INTEGER NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME,
+    last_attempt_at DATETIME,
+    response_code INTEGER,
+    error TEXT,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries(subscription_id, id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(status, next_attempt_at);
+
+-- failed http deliveries kept with the exact request for replay; headers is
+-- a JSON object of header value lists
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL,
+    job_run_id INTEGER NOT NULL,
+    method TEXT NOT NULL,
+    url TEXT NOT NULL,
+    headers TEXT NOT NULL,
+    body TEXT,
+    attempts INTEGER NOT NULL,
+    response_code INTEGER,
+    error TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    replay_run_id INTEGER,
+    replayed_at DATETIME,
+    FOREIGN KEY (job_id) REFERENCES jobs(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_job_id ON dead_letters(job_id, id);
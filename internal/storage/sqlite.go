@@ -14,12 +14,14 @@ import (
 //go:embed schema.sql
 var ddl string
 
-func NewSQLiteDB() (*db.Queries, error) {
+// NewSQLiteDB opens the database and applies the schema. The raw handle is
+// returned alongside the queries for transactions and maintenance.
+func NewSQLiteDB() (*db.Queries, *sql.DB, error) {
 	ctx := context.Background()
 
-	startedDb, err := sql.Open("sqlite", "db.sqlite?_journal=WAL&_timeout=5000&_synchronous=NORMAL")
+	startedDb, err := sql.Open("sqlite", "db.sqlite?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)&_pragma=auto_vacuum(INCREMENTAL)")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	startedDb.SetMaxOpenConns(10)
@@ -27,10 +29,14 @@ func NewSQLiteDB() (*db.Queries, error) {
 	startedDb.SetConnMaxLifetime(time.Hour)
 
 	if _, err := startedDb.ExecContext(ctx, ddl); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	if err := addMissingColumns(ctx, startedDb); err != nil {
+		return nil, nil, err
 	}
 
 	queries := db.New(startedDb)
 
-	return queries, nil
+	return queries, startedDb, nil
 }
@@ -0,0 +1,319 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/telemetry"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+
+	// AllEvents subscribes to every event type.
+	AllEvents = "*"
+)
+
+const (
+	// MaxAttempts is how often a delivery is tried before it is failed.
+	MaxAttempts     = 6
+	retryBaseDelay  = 10 * time.Second
+	deliveryTimeout = 10 * time.Second
+	pollInterval    = 2 * time.Second
+	deliveryBatch   = 20
+	queueSize       = 1024
+	maxErrorLength  = 500
+)
+
+var httpClient = &http.Client{
+	Timeout:   deliveryTimeout,
+	Transport: telemetry.Transport(http.DefaultTransport),
+}
+
+// Dispatcher records every published event as a delivery for each matching
+// subscription and sends them, retrying failures with exponential backoff.
+// Events are queued in memory until their deliveries are stored; from then
+// on pending retries survive a restart.
+type Dispatcher struct {
+	db     *db.Queries
+	queue  chan events.Event
+	wake   chan struct{}
+	done   chan struct{}
+	wg     sync.WaitGroup
+	ticker *time.Ticker
+}
+
+func NewDispatcher(database *db.Queries, bus *events.Bus) *Dispatcher {
+	d := &Dispatcher{
+		db:    database,
+		queue: make(chan events.Event, queueSize),
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	bus.Subscribe(d.enqueue)
+	return d
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	slog.Info("starting webhook dispatcher")
+
+	d.ticker = time.NewTicker(pollInterval)
+
+	d.wg.Add(2)
+	go d.recordEvents(ctx)
+	go d.deliverLoop(ctx)
+}
+
+func (d *Dispatcher) Stop() {
+	if d.ticker == nil {
+		return
+	}
+
+	slog.Info("stopping webhook dispatcher")
+	d.ticker.Stop()
+	close(d.done)
+	d.wg.Wait()
+	slog.Info("webhook dispatcher stopped")
+}
+
+// enqueue runs on the publisher's goroutine, with the bus locked, and must
+// not block: storing the deliveries can wait for the database.
+func (d *Dispatcher) enqueue(event events.Event) {
+	select {
+	case d.queue <- event:
+	default:
+		slog.Warn("webhook queue full, dropping event", "event_id", event.ID, "type", event.Type)
+	}
+}
+
+// recordEvents stores the deliveries of queued events and wakes the
+// delivery loop. On stop it stores what is still queued before returning.
+func (d *Dispatcher) recordEvents(ctx context.Context) {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.done:
+			for {
+				select {
+				case event := <-d.queue:
+					d.record(ctx, event)
+				default:
+					return
+				}
+			}
+		case event := <-d.queue:
+			if d.record(ctx, event) {
+				select {
+				case d.wake <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// record stores one pending delivery per subscription interested in the
+// event and reports whether there were any.
+func (d *Dispatcher) record(ctx context.Context, event events.Event) bool {
+	subscriptions, err := d.db.GetActiveWebhookSubscriptions(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load webhook subscriptions", "error", err)
+		return false
+	}
+
+	var payload []byte
+	recorded := false
+	for _, subscription := range subscriptions {
+		if !Subscribed(subscription, event.Type) {
+			continue
+		}
+
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				slog.ErrorContext(ctx, "failed to encode event", "event_id", event.ID, "error", err)
+				return false
+			}
+		}
+
+		now := time.Now().UTC()
+		_, err := d.db.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        string(payload),
+			NextAttemptAt:  sql.NullTime{Time: now, Valid: true},
+			CreatedAt:      now,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to record webhook delivery", "subscription_id", subscription.ID, "error", err)
+			continue
+		}
+		recorded = true
+	}
+
+	return recorded
+}
+
+func (d *Dispatcher) deliverLoop(ctx context.Context) {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-d.ticker.C:
+		case <-d.wake:
+		}
+		d.deliverDue(ctx)
+	}
+}
+
+// deliverDue sends due deliveries in parallel batches until none are left.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for {
+		due, err := d.db.GetDueWebhookDeliveries(ctx, db.GetDueWebhookDeliveriesParams{
+			NextAttemptAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+			Limit:         deliveryBatch,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to fetch due webhook deliveries", "error", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range due {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.attempt(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		if len(due) < deliveryBatch {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery db.WebhookDelivery) {
+	now := time.Now().UTC()
+	params := db.UpdateWebhookDeliveryAttemptParams{
+		ID:            delivery.ID,
+		Attempts:      delivery.Attempts + 1,
+		LastAttemptAt: sql.NullTime{Time: now, Valid: true},
+	}
+
+	subscription, err := d.db.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err = errors.New("subscription was deleted")
+		params.Attempts = MaxAttempts
+	case err == nil && !subscription.Active:
+		err = errors.New("subscription is disabled")
+		params.Attempts = MaxAttempts
+	case err == nil:
+		var statusCode int
+		statusCode, err = send(ctx, subscription, delivery)
+		params.ResponseCode = sql.NullInt64{Int64: int64(statusCode), Valid: statusCode != 0}
+	}
+
+	switch {
+	case err == nil:
+		params.Status = StatusSucceeded
+	case params.Attempts >= MaxAttempts:
+		params.Status = StatusFailed
+		slog.WarnContext(ctx, "webhook delivery failed", "delivery_id", delivery.ID,
+			"subscription_id", delivery.SubscriptionID, "attempts", params.Attempts, "error", err)
+	default:
+		params.Status = StatusPending
+		params.NextAttemptAt = sql.NullTime{Time: now.Add(RetryDelay(params.Attempts)), Valid: true}
+	}
+
+	if err != nil {
+		message := err.Error()
+		if len(message) > maxErrorLength {
+			message = message[:maxErrorLength]
+		}
+		params.Error = sql.NullString{String: message, Valid: true}
+	}
+
+	if err := d.db.UpdateWebhookDeliveryAttempt(ctx, params); err != nil {
+		slog.ErrorContext(ctx, "failed to update webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+func send(ctx context.Context, subscription db.WebhookSubscription, delivery db.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Pulse-Webhooks")
+	req.Header.Set("X-Pulse-Event", delivery.EventType)
+	req.Header.Set("X-Pulse-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Pulse-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Pulse-Signature", "sha256="+Sign(subscription.Secret, timestamp, payload))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return resp.StatusCode, fmt.Errorf("subscriber answered %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<payload>" under secret,
+// as sent in X-Pulse-Signature. Receivers recompute it with the
+// X-Pulse-Timestamp header and reject stale timestamps to stop replays.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RetryDelay is the wait after the given number of failed attempts: 10s,
+// 20s, 40s and so on.
+func RetryDelay(attempts int64) time.Duration {
+	return retryBaseDelay << (attempts - 1)
+}
+
+// Subscribed reports whether subscription wants events of eventType.
+func Subscribed(subscription db.WebhookSubscription, eventType string) bool {
+	var eventTypes []string
+	json.Unmarshal([]byte(subscription.Events), &eventTypes)
+	return slices.Contains(eventTypes, AllEvents) || slices.Contains(eventTypes, eventType)
+}
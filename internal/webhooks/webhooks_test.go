@@ -0,0 +1,181 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"lucasbonna/pulse/db"
+	"lucasbonna/pulse/internal/events"
+	"lucasbonna/pulse/internal/storage"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// received is what the subscriber endpoint saw of one delivery.
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// newTestDispatcher returns a dispatcher on a fresh database with one
+// subscription to run.failed events, pointing at a local endpoint that
+// sends every delivery it receives on the returned channel.
+func newTestDispatcher(t *testing.T) (*Dispatcher, *events.Bus, db.WebhookSubscription, <-chan received) {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+	queries, database, err := storage.NewSQLiteDB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	requests := make(chan received, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	subscription, err := queries.CreateWebhookSubscription(context.Background(), db.CreateWebhookSubscriptionParams{
+		Url:       server.URL,
+		Secret:    "whsec",
+		Events:    `["run.failed"]`,
+		Active:    true,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+
+	bus := events.NewBus()
+	return NewDispatcher(queries, bus), bus, subscription, requests
+}
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"type":"run.failed"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		payload   []byte
+		want      string
+	}{
+		{"payload", "whsec", 1700000000, payload, "3e763244305bcc54c6e5b7eff71a52db647838242ff4604e799d02c778ad32db"},
+		{"other timestamp", "whsec", 1700000001, payload, "bb0bc897f0f6416527992c1ea32a58932f4e564c7751d88d56cc5cb60e206067"},
+		{"other secret", "other", 1700000000, payload, "3a315b1927b3730454cec80e1fcdcfec6c5d2d0940cb3f08ffa98d4213fca290"},
+		{"empty payload", "whsec", 0, nil, "0d1dbfdae9a6e257f2fa78f48d7310b9ac3ba418a71f5ee3afe129f3c6c15578"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, tt.payload); got != tt.want {
+				t.Errorf("Sign = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int64
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{MaxAttempts - 1, 160 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := RetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("RetryDelay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestSubscribed(t *testing.T) {
+	tests := []struct {
+		name      string
+		events    string
+		eventType string
+		want      bool
+	}{
+		{"listed", `["run.failed","run.succeeded"]`, "run.failed", true},
+		{"not listed", `["run.succeeded"]`, "run.failed", false},
+		{"all events", `["*"]`, "job.deleted", true},
+		{"none", `[]`, "run.failed", false},
+		{"unreadable", `run.failed`, "run.failed", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := db.WebhookSubscription{Events: tt.events}
+			if got := Subscribed(subscription, tt.eventType); got != tt.want {
+				t.Errorf("Subscribed(%s, %q) = %v, want %v", tt.events, tt.eventType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcherDeliversSignedEvents(t *testing.T) {
+	dispatcher, bus, subscription, requests := newTestDispatcher(t)
+	ctx := context.Background()
+
+	bus.Publish(events.RunSucceeded, db.Job{ID: 1}, nil)
+	bus.Publish(events.RunFailed, db.Job{ID: 1}, nil)
+
+	// publishing only queues the events
+	deliveries, err := dispatcher.db.GetWebhookDeliveries(ctx, db.GetWebhookDeliveriesParams{SubscriptionID: subscription.ID, Limit: 10})
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	if len(deliveries) != 0 {
+		t.Fatalf("stored %d deliveries on the publisher's goroutine", len(deliveries))
+	}
+
+	dispatcher.Start(ctx)
+	defer dispatcher.Stop()
+
+	var request received
+	select {
+	case request = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no delivery arrived")
+	}
+
+	if got := request.header.Get("X-Pulse-Event"); got != events.RunFailed {
+		t.Errorf("X-Pulse-Event = %q, want %q", got, events.RunFailed)
+	}
+	timestamp, err := strconv.ParseInt(request.header.Get("X-Pulse-Timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("X-Pulse-Timestamp: %v", err)
+	}
+	want := "sha256=" + Sign("whsec", timestamp, request.body)
+	if got := request.header.Get("X-Pulse-Signature"); got != want {
+		t.Errorf("X-Pulse-Signature = %q, want %q", got, want)
+	}
+}
+
+func TestDispatcherStoresQueuedEventsOnStop(t *testing.T) {
+	dispatcher, bus, subscription, _ := newTestDispatcher(t)
+	ctx := context.Background()
+
+	for range 3 {
+		bus.Publish(events.RunFailed, db.Job{ID: 1}, nil)
+	}
+	dispatcher.Start(ctx)
+	dispatcher.Stop()
+
+	deliveries, err := dispatcher.db.GetWebhookDeliveries(ctx, db.GetWebhookDeliveriesParams{SubscriptionID: subscription.ID, Limit: 10})
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	if len(deliveries) != 3 {
+		t.Errorf("stored %d deliveries, want 3", len(deliveries))
+	}
+}